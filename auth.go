@@ -0,0 +1,158 @@
+package wordcounter
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"crypto/subtle"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/middleware"
+)
+
+// HeaderAPIKey is the header of API keys, the alternative to bearer tokens
+const HeaderAPIKey = "X-API-Key"
+
+// apiKeyKey is the key of the API key of the request in the echo.Context
+const apiKeyKey = "wordcounter.api_key"
+
+// publicEndpoints can be requested without an API key
+var publicEndpoints = map[string]bool{
+	PingEndpoint:    true,
+	OpenAPIEndpoint: true,
+	DocsEndpoint:    true,
+}
+
+// LoadAPIKeys reads API keys from a file, one per line. Blank lines and lines starting with # are skipped.
+func LoadAPIKeys(path string) ([]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, NewFileReadError(path, err)
+	}
+	defer file.Close()
+
+	var keys []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line != "" && !strings.HasPrefix(line, "#") {
+			keys = append(keys, line)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, NewFileReadError(path, err)
+	}
+	if len(keys) == 0 {
+		return nil, NewInvalidInputError("no API keys in file: "+path).WithContext("path", path)
+	}
+	return keys, nil
+}
+
+// requestAPIKey returns the bearer token or the X-API-Key header of the request
+func requestAPIKey(req *http.Request) string {
+	scheme, token, found := strings.Cut(req.Header.Get(echo.HeaderAuthorization), " ")
+	if found && strings.EqualFold(scheme, "Bearer") {
+		return strings.TrimSpace(token)
+	}
+	return req.Header.Get(HeaderAPIKey)
+}
+
+// validAPIKey reports whether the key is one of Config.APIKeys. Digests are compared
+// in constant time, so that neither the keys nor their lengths can be guessed from timings.
+func (s *WordCounterServer) validAPIKey(key string) bool {
+	digest := sha256.Sum256([]byte(key))
+	valid := 0
+	for _, apiKey := range s.Config.APIKeys {
+		apiDigest := sha256.Sum256([]byte(apiKey))
+		valid |= subtle.ConstantTimeCompare(digest[:], apiDigest[:])
+	}
+	return valid == 1
+}
+
+// authenticate rejects requests without one of Config.APIKeys, except to the public endpoints
+func (s *WordCounterServer) authenticate(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		if len(s.Config.APIKeys) == 0 || publicEndpoints[c.Path()] {
+			return next(c)
+		}
+
+		key := requestAPIKey(c.Request())
+		if key == "" || !s.validAPIKey(key) {
+			message := "invalid API key"
+			if key == "" {
+				message = "missing API key, send it as a bearer token or in the " + HeaderAPIKey + " header"
+			}
+			c.Response().Header().Set(echo.HeaderWWWAuthenticate, `Bearer realm="wordcounter"`)
+			return respondError(c, MsgUnauthorized, NewUnauthorizedError(message))
+		}
+		c.Set(apiKeyKey, key)
+		return next(c)
+	}
+}
+
+// limitIP applies Config.IPRateLimit to every client IP, see clientIP
+func (s *WordCounterServer) limitIP(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		if ok, retryAfter := s.ipLimiter.allow(c.RealIP(), s.Config.IPRateLimit, time.Now()); !ok {
+			return rateLimited(c, retryAfter)
+		}
+		return next(c)
+	}
+}
+
+// limitKey applies Config.KeyRateLimit to every API key
+func (s *WordCounterServer) limitKey(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		key, _ := c.Get(apiKeyKey).(string)
+		if key == "" {
+			return next(c)
+		}
+		if ok, retryAfter := s.keyLimiter.allow(key, s.Config.KeyRateLimit, time.Now()); !ok {
+			return rateLimited(c, retryAfter)
+		}
+		return next(c)
+	}
+}
+
+// rateLimited responds 429 with the seconds to wait in the Retry-After header
+func rateLimited(c echo.Context, retryAfter time.Duration) error {
+	err := NewRateLimitError(retryAfter)
+	c.Response().Header().Set(echo.HeaderRetryAfter, strconv.Itoa(err.Context["retry_after"].(int)))
+	return respondError(c, MsgRateLimited, err)
+}
+
+// clientIP returns the IP of the client, taken from X-Forwarded-For when Config.TrustProxy is set
+func (s *WordCounterServer) clientIP(req *http.Request) string {
+	if s.Config.TrustProxy {
+		return echo.ExtractIPFromXFFHeader()(req)
+	}
+	return echo.ExtractIPDirect()(req)
+}
+
+// cors lets the browsers of Config.CORSOrigins call the API. It is configured on the first request.
+func (s *WordCounterServer) cors(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		s.corsOnce.Do(func() {
+			if len(s.Config.CORSOrigins) == 0 {
+				return
+			}
+			s.corsMiddleware = middleware.CORSWithConfig(middleware.CORSConfig{
+				AllowOrigins: s.Config.CORSOrigins,
+				AllowMethods: []string{http.MethodGet, http.MethodPost, http.MethodOptions},
+				AllowHeaders: []string{
+					echo.HeaderAuthorization, HeaderAPIKey, echo.HeaderContentType, echo.HeaderAccept, "Accept-Language",
+				},
+				ExposeHeaders: []string{echo.HeaderRetryAfter, echo.HeaderContentDisposition},
+				MaxAge:        int(time.Hour.Seconds()),
+			})
+		})
+		if s.corsMiddleware == nil {
+			return next(c)
+		}
+		return s.corsMiddleware(next)(c)
+	}
+}
@@ -0,0 +1,184 @@
+package wordcounter_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	wcg "github.com/100gle/wordcounter"
+	"github.com/gavv/httpexpect/v2"
+)
+
+func newTestExpect(t *testing.T, server *wcg.WordCounterServer) *httpexpect.Expect {
+	testServer := httptest.NewServer(server.Echo)
+	t.Cleanup(testServer.Close)
+	return httpexpect.WithConfig(httpexpect.Config{
+		BaseURL:  testServer.URL,
+		Reporter: httpexpect.NewAssertReporter(t),
+	})
+}
+
+func TestLoadAPIKeys(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "keys")
+	if err := os.WriteFile(path, []byte("# editors\nkey-1\n\n  key-2  \n"), 0600); err != nil {
+		t.Fatalf("Failed to write keys: %v", err)
+	}
+	keys, err := wcg.LoadAPIKeys(path)
+	if err != nil {
+		t.Fatalf("LoadAPIKeys() error = %v", err)
+	}
+	if want := []string{"key-1", "key-2"}; !reflect.DeepEqual(keys, want) {
+		t.Errorf("LoadAPIKeys() = %v, want %v", keys, want)
+	}
+
+	empty := filepath.Join(dir, "empty")
+	if err := os.WriteFile(empty, []byte("# no keys yet\n"), 0600); err != nil {
+		t.Fatalf("Failed to write keys: %v", err)
+	}
+	for _, path := range []string{empty, filepath.Join(dir, "missing")} {
+		if _, err := wcg.LoadAPIKeys(path); err == nil {
+			t.Errorf("LoadAPIKeys(%s) should return error", filepath.Base(path))
+		}
+	}
+}
+
+func TestWordCounterServer_Authenticate(t *testing.T) {
+	doc := loadOpenAPI(t)
+	server := wcg.NewWordCounterServer()
+	server.Config.APIKeys = []string{"key-1", "key-2"}
+	e := newTestExpect(t, server)
+	body := &wcg.CountBody{Content: "你好"}
+
+	tests := []struct {
+		name   string
+		header string
+		value  string
+		status int
+	}{
+		{"missing key", "", "", http.StatusUnauthorized},
+		{"invalid key", "Authorization", "Bearer key-3", http.StatusUnauthorized},
+		{"other scheme", "Authorization", "Basic key-1", http.StatusUnauthorized},
+		{"bearer token", "Authorization", "Bearer key-1", http.StatusOK},
+		{"lowercase scheme", "Authorization", "bearer key-2", http.StatusOK},
+		{"header", "X-API-Key", "key-2", http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := e.POST(wcg.CountEndpoint).WithJSON(body)
+			if tt.header != "" {
+				req = req.WithHeader(tt.header, tt.value)
+			}
+			resp := req.Expect().Status(tt.status)
+			if tt.status == http.StatusUnauthorized {
+				resp.Header("WWW-Authenticate").IsEqual(`Bearer realm="wordcounter"`)
+				resp.JSON().
+					Schema(doc.responseSchema(t, http.MethodPost, wcg.CountEndpoint, tt.status)).
+					Object().HasValue("code", "unauthorized").HasValue("msg", "unauthorized")
+			}
+		})
+	}
+
+	t.Run("public endpoints", func(t *testing.T) {
+		for _, endpoint := range []string{wcg.PingEndpoint, wcg.OpenAPIEndpoint, wcg.DocsEndpoint} {
+			e.GET(endpoint).Expect().Status(http.StatusOK)
+		}
+		e.GET(wcg.MetricsEndpoint).Expect().Status(http.StatusUnauthorized)
+	})
+}
+
+func TestWordCounterServer_RateLimit(t *testing.T) {
+	doc := loadOpenAPI(t)
+
+	t.Run("per IP", func(t *testing.T) {
+		server := wcg.NewWordCounterServer()
+		server.Config.IPRateLimit = wcg.RateLimit{Rate: 0.1, Burst: 2}
+		e := newTestExpect(t, server)
+
+		for i := 0; i < 2; i++ {
+			e.GET(wcg.PingEndpoint).Expect().Status(http.StatusOK)
+		}
+		resp := e.POST(wcg.CountEndpoint).WithText("你好").Expect().Status(http.StatusTooManyRequests)
+		resp.Header("Retry-After").AsNumber().InRange(1, 10)
+		resp.JSON().
+			Schema(doc.responseSchema(t, http.MethodPost, wcg.CountEndpoint, http.StatusTooManyRequests)).
+			Object().
+			HasValue("code", "rate_limited").
+			HasValue("msg", "too many requests").
+			Value("context").Object().Value("retry_after").Number().InRange(1, 10)
+	})
+
+	t.Run("forwarded IPs", func(t *testing.T) {
+		server := wcg.NewWordCounterServer()
+		server.Config.IPRateLimit = wcg.RateLimit{Rate: 0.1, Burst: 1}
+		e := newTestExpect(t, server)
+
+		// Not trusted, every request comes from the address of the test client
+		e.GET(wcg.PingEndpoint).WithHeader("X-Forwarded-For", "203.0.113.1").Expect().Status(http.StatusOK)
+		e.GET(wcg.PingEndpoint).WithHeader("X-Forwarded-For", "203.0.113.2").Expect().Status(http.StatusTooManyRequests)
+
+		server.Config.TrustProxy = true
+		e.GET(wcg.PingEndpoint).WithHeader("X-Forwarded-For", "203.0.113.3").Expect().Status(http.StatusOK)
+		e.GET(wcg.PingEndpoint).WithHeader("X-Forwarded-For", "203.0.113.4").Expect().Status(http.StatusOK)
+		e.GET(wcg.PingEndpoint).WithHeader("X-Forwarded-For", "203.0.113.4").Expect().Status(http.StatusTooManyRequests)
+	})
+
+	t.Run("per key", func(t *testing.T) {
+		server := wcg.NewWordCounterServer()
+		server.Config.APIKeys = []string{"key-1", "key-2"}
+		server.Config.KeyRateLimit = wcg.RateLimit{Rate: 0.1, Burst: 1}
+		e := newTestExpect(t, server)
+
+		e.POST(wcg.CountEndpoint).WithHeader("X-API-Key", "key-1").WithText("你好").Expect().Status(http.StatusOK)
+		e.POST(wcg.CountEndpoint).WithHeader("X-API-Key", "key-1").WithText("你好").Expect().Status(http.StatusTooManyRequests)
+		e.POST(wcg.CountEndpoint).WithHeader("X-API-Key", "key-2").WithText("你好").Expect().Status(http.StatusOK)
+		// Requests without a valid key are not counted against a key
+		e.POST(wcg.CountEndpoint).WithHeader("X-API-Key", "key-3").WithText("你好").Expect().Status(http.StatusUnauthorized)
+	})
+}
+
+func TestWordCounterServer_CORS(t *testing.T) {
+	origin := "https://editor.example.com"
+	server := wcg.NewWordCounterServer()
+	server.Config.APIKeys = []string{"key-1"}
+	server.Config.CORSOrigins = []string{origin}
+	e := newTestExpect(t, server)
+
+	// Preflight requests carry no credentials
+	e.OPTIONS(wcg.CountEndpoint).
+		WithHeader("Origin", origin).
+		WithHeader("Access-Control-Request-Method", http.MethodPost).
+		WithHeader("Access-Control-Request-Headers", "authorization,content-type").
+		Expect().
+		Status(http.StatusNoContent).
+		Header("Access-Control-Allow-Origin").IsEqual(origin)
+
+	resp := e.POST(wcg.CountEndpoint).
+		WithHeader("Origin", origin).
+		WithHeader("Authorization", "Bearer key-1").
+		WithText("你好").
+		Expect().
+		Status(http.StatusOK)
+	resp.Header("Access-Control-Allow-Origin").IsEqual(origin)
+	resp.Header("Access-Control-Expose-Headers").Contains("Retry-After")
+
+	e.POST(wcg.CountEndpoint).
+		WithHeader("Origin", "https://other.example.com").
+		WithHeader("Authorization", "Bearer key-1").
+		WithText("你好").
+		Expect().
+		Status(http.StatusOK).
+		Header("Access-Control-Allow-Origin").IsEmpty()
+
+	// Without origins, no CORS headers
+	e = newTestExpect(t, wcg.NewWordCounterServer())
+	e.POST(wcg.CountEndpoint).
+		WithHeader("Origin", origin).
+		WithText("你好").
+		Expect().
+		Status(http.StatusOK).
+		Header("Access-Control-Allow-Origin").IsEmpty()
+}
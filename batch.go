@@ -0,0 +1,132 @@
+package wordcounter
+
+import (
+	"fmt"
+	"net/http"
+	"sync"
+
+	"github.com/labstack/echo/v4"
+)
+
+// BatchItem is a document of a batch request
+type BatchItem struct {
+	ID      string        `json:"id"`
+	Content string        `json:"content"`
+	Options *CountOptions `json:"options,omitempty"` // Counting options, the zero value if nil
+}
+
+// BatchItemResult is the stats of a document, or why it could not be counted
+type BatchItemResult struct {
+	Stats   *Stats       `json:"stats,omitempty"`
+	Options CountOptions `json:"options"` // Effective counting options
+	Error   string       `json:"error,omitempty"`
+}
+
+// BatchResult is the result of every document keyed by id, and the total of the counted ones
+type BatchResult struct {
+	Results map[string]*BatchItemResult `json:"results"`
+	Total   Stats                       `json:"total"`
+	Failed  int                         `json:"failed"`
+}
+
+// CountBatch counts the documents concurrently with a bounded pool of workers.
+// A document that cannot be counted gets an error instead of failing the others.
+func CountBatch(items []BatchItem) *BatchResult {
+	results := make([]*BatchItemResult, len(items))
+	jobs := make(chan int, len(items))
+	for i := range items {
+		jobs <- i
+	}
+	close(jobs)
+
+	var wg sync.WaitGroup
+	for i := 0; i < workerCount(len(items)); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for index := range jobs {
+				options := CountOptions{}
+				if items[index].Options != nil {
+					options = *items[index].Options
+				}
+				counter := NewCounterWithOptions(options)
+				if err := counter.Count(items[index].Content); err != nil {
+					results[index] = &BatchItemResult{Options: options, Error: err.Error()}
+					continue
+				}
+				results[index] = &BatchItemResult{Stats: counter.Stats, Options: options}
+			}
+		}()
+	}
+	wg.Wait()
+
+	batch := &BatchResult{Results: make(map[string]*BatchItemResult, len(items))}
+	for i, result := range results {
+		batch.Results[items[i].ID] = result
+		if result.Stats == nil {
+			batch.Failed++
+			continue
+		}
+		batch.Total.add(result.Stats)
+	}
+	return batch
+}
+
+// validateBatch checks that the batch has at most max items, each with a unique id
+func validateBatch(items []BatchItem, max int) error {
+	if len(items) == 0 {
+		return NewInvalidInputError("batch is empty")
+	}
+	if max > 0 && len(items) > max {
+		return NewInvalidInputError(fmt.Sprintf("batch has %d items, the maximum is %d", len(items), max))
+	}
+	seen := make(map[string]bool, len(items))
+	for i, item := range items {
+		if item.ID == "" {
+			return NewInvalidInputError(fmt.Sprintf("item %d has no id", i))
+		}
+		if seen[item.ID] {
+			return NewInvalidInputError(fmt.Sprintf("duplicate id: %s", item.ID))
+		}
+		seen[item.ID] = true
+	}
+	return nil
+}
+
+// Batch counts a JSON array of documents and returns their results keyed by id.
+// The options of the query parameters apply to the items without options.
+func (s *WordCounterServer) Batch(c echo.Context) error {
+	lang := requestLanguage(c)
+	options, err := requestOptions(c.QueryParam)
+	if err != nil {
+		return invalidOptions(c, err, "")
+	}
+
+	var items []BatchItem
+	if err := c.Bind(&items); err != nil {
+		return s.bodyError(c, err, "options.")
+	}
+	for i := range items {
+		if items[i].Options == nil {
+			items[i].Options = &options
+		}
+	}
+	if err := validateBatch(items, s.Config.MaxBatchItems); err != nil {
+		return respondError(c, MsgParseFailed, err)
+	}
+
+	result := CountBatch(items)
+	size := 0
+	for _, item := range items {
+		if result.Results[item.ID].Stats != nil {
+			size += len(item.Content)
+		}
+	}
+	s.Metrics.ObserveCount(int64(size), &result.Total)
+
+	return c.JSON(http.StatusOK, map[string]any{
+		"msg":   Translate(lang, MsgOK),
+		"data":  result,
+		"error": "",
+	})
+}
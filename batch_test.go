@@ -0,0 +1,119 @@
+package wordcounter_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	wcg "github.com/100gle/wordcounter"
+	"github.com/gavv/httpexpect/v2"
+)
+
+func TestCountBatch(t *testing.T) {
+	items := []wcg.BatchItem{
+		{ID: "a", Content: "你好，世界！"},
+		{ID: "b", Content: "hello\nworld"},
+		{ID: "c", Content: ""},
+	}
+	for i := 0; i < 100; i++ {
+		items = append(items, wcg.BatchItem{ID: fmt.Sprintf("n%d", i), Content: "中文"})
+	}
+
+	result := wcg.CountBatch(items)
+	if len(result.Results) != len(items) {
+		t.Fatalf("CountBatch() has %d results, want %d", len(result.Results), len(items))
+	}
+	if got := result.Results["a"].Stats; got == nil || *got != (wcg.Stats{Lines: 1, ChineseChars: 6, TotalChars: 6}) {
+		t.Errorf("CountBatch() a = %+v", got)
+	}
+	if got := result.Results["b"].Stats; got == nil || got.Lines != 2 || got.NonChineseChars != 10 {
+		t.Errorf("CountBatch() b = %+v", got)
+	}
+	if got := result.Results["c"]; got.Stats != nil || got.Error == "" {
+		t.Errorf("CountBatch() c = %+v, want an error", got)
+	}
+	if result.Failed != 1 {
+		t.Errorf("CountBatch() failed = %d, want 1", result.Failed)
+	}
+	if result.Total.ChineseChars != 6+200 {
+		t.Errorf("CountBatch() total = %+v", result.Total)
+	}
+}
+
+func TestCountBatch_Options(t *testing.T) {
+	items := []wcg.BatchItem{
+		{ID: "plain", Content: "hello, world"},
+		{ID: "words", Content: "hello, world", Options: &wcg.CountOptions{CountWords: true, ExcludePunctuation: true}},
+	}
+	result := wcg.CountBatch(items)
+
+	plain := result.Results["plain"]
+	if *plain.Stats != (wcg.Stats{Lines: 1, NonChineseChars: 12, TotalChars: 12}) || plain.Options != (wcg.CountOptions{}) {
+		t.Errorf("CountBatch() plain = %+v %+v", *plain.Stats, plain.Options)
+	}
+	words := result.Results["words"]
+	if *words.Stats != (wcg.Stats{Lines: 1, NonChineseChars: 11, TotalChars: 11, Words: 2}) || words.Options != *items[1].Options {
+		t.Errorf("CountBatch() words = %+v %+v", *words.Stats, words.Options)
+	}
+	if result.Total.Words != 2 {
+		t.Errorf("CountBatch() total words = %d, want 2", result.Total.Words)
+	}
+}
+
+func TestWordCounterServer_Batch(t *testing.T) {
+	server := wcg.NewWordCounterServer()
+	server.Config.MaxBatchItems = 3
+	testServer := httptest.NewServer(server.Echo)
+	defer testServer.Close()
+
+	e := httpexpect.WithConfig(httpexpect.Config{
+		BaseURL:  testServer.URL,
+		Reporter: httpexpect.NewAssertReporter(t),
+	})
+
+	data := e.POST(wcg.BatchEndpoint).
+		WithJSON([]wcg.BatchItem{{ID: "a", Content: "你好"}, {ID: "b", Content: ""}}).
+		Expect().
+		Status(http.StatusOK).
+		JSON().Object().
+		HasValue("msg", "ok").
+		Value("data").Object()
+	data.Value("results").Object().Value("a").Object().Value("stats").Object().HasValue("chinese_chars", 2)
+	data.Value("results").Object().Value("b").Object().ContainsKey("error").NotContainsKey("stats")
+	data.HasValue("failed", 1)
+
+	e.POST(wcg.BatchEndpoint).
+		WithJSON([]map[string]any{{"id": "a", "content": "hello world", "options": map[string]bool{"count_words": true}}}).
+		Expect().
+		Status(http.StatusOK).
+		JSON().Object().
+		Value("data").Object().
+		Value("results").Object().
+		Value("a").Object().
+		HasValue("options", map[string]bool{"strip_markdown": false, "exclude_punctuation": false, "count_words": true}).
+		Value("stats").Object().HasValue("words", 2)
+
+	tests := []struct {
+		name string
+		body any
+	}{
+		{"not an array", map[string]string{"id": "a"}},
+		{"empty", []wcg.BatchItem{}},
+		{"missing id", []wcg.BatchItem{{Content: "你好"}}},
+		{"duplicate id", []wcg.BatchItem{{ID: "a", Content: "你好"}, {ID: "a", Content: "世界"}}},
+		{"too many items", []wcg.BatchItem{{ID: "a"}, {ID: "b"}, {ID: "c"}, {ID: "d"}}},
+		{"invalid options", []map[string]any{{"id": "a", "content": "你好", "options": map[string]any{"count_words": 1}}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e.POST(wcg.BatchEndpoint).
+				WithJSON(tt.body).
+				Expect().
+				Status(http.StatusUnprocessableEntity).
+				JSON().Object().
+				ContainsKey("msg").
+				ContainsKey("error")
+		})
+	}
+}
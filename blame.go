@@ -0,0 +1,263 @@
+package wordcounter
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Blame report views
+const (
+	BlameViewAuthors = "authors"
+	BlameViewMatrix  = "matrix"
+)
+
+// AuthorStats is the contribution of an author, counted over the lines git blame attributes to them
+type AuthorStats struct {
+	Author string `json:"author"`
+	Files  int    `json:"files"`
+	Stats
+}
+
+// BlameReport attributes the stats of the lines of each file to their authors
+type BlameReport struct {
+	Field   string                      `json:"field"`   // Stats field used to rank authors and fill the matrix
+	Authors []*AuthorStats              `json:"authors"` // Sorted by Field in descending order
+	Files   map[string]map[string]Stats `json:"files"`   // Slash separated path -> author -> stats
+	Total   Stats                       `json:"total"`
+}
+
+// Blame runs git blame over each file counted by the DirCounter, at its git revision if one is set.
+// In the working tree, untracked files are skipped and uncommitted lines belong to "Not Committed Yet".
+// Every blamed line counts as one line.
+func Blame(dc *DirCounter, field string) (*BlameReport, error) {
+	if _, err := (&Stats{}).Value(field); err != nil {
+		return nil, err
+	}
+	rev := dc.GitRevision()
+	if rev == GitIndex {
+		return nil, NewInvalidInputError("blame does not support the staging index, use a revision")
+	}
+
+	root := ToAbsolutePath(dc.dirname)
+	var tracked map[string]bool
+	if rev == "" {
+		files, err := gitListIndex(root)
+		if err != nil {
+			return nil, err
+		}
+		tracked = make(map[string]bool, len(files))
+		for _, file := range files {
+			tracked[file.Path] = true
+		}
+	}
+
+	var paths []string
+	for _, fc := range dc.fileCounters {
+		relPath, err := filepath.Rel(root, fc.FileName)
+		if err != nil {
+			continue
+		}
+		relPath = filepath.ToSlash(relPath)
+		if tracked == nil || tracked[relPath] {
+			paths = append(paths, relPath)
+		}
+	}
+
+	files, err := blameFiles(root, rev, paths, dc.options)
+	if err != nil {
+		return nil, err
+	}
+	return newBlameReport(files, field), nil
+}
+
+// blameFiles blames the files concurrently and returns the stats of each author per file
+func blameFiles(root, rev string, paths []string, options CountOptions) (map[string]map[string]Stats, error) {
+	jobs := make(chan string, len(paths))
+	for _, path := range paths {
+		jobs <- path
+	}
+	close(jobs)
+
+	var (
+		mu       sync.Mutex
+		wg       sync.WaitGroup
+		firstErr error
+		files    = make(map[string]map[string]Stats, len(paths))
+	)
+	for i := 0; i < workerCount(len(paths)); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for path := range jobs {
+				authors, err := blameFile(root, rev, path, options)
+				mu.Lock()
+				if err != nil && firstErr == nil {
+					firstErr = err
+				}
+				files[path] = authors
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	return files, nil
+}
+
+// blameFile attributes the stats of each line of a file to its author
+func blameFile(root, rev, path string, options CountOptions) (map[string]Stats, error) {
+	args := []string{"blame", "--line-porcelain"}
+	if rev != "" {
+		args = append(args, rev)
+	}
+	out, err := runGit(root, append(args, "--", path)...)
+	if err != nil {
+		return nil, err
+	}
+
+	authors := map[string]Stats{}
+	author := ""
+	scanner := bufio.NewScanner(bytes.NewReader(out))
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case strings.HasPrefix(line, "author "):
+			author = strings.TrimPrefix(line, "author ")
+		case strings.HasPrefix(line, "\t"):
+			counter := NewCounterWithOptions(options)
+			if err := counter.CountBytes([]byte(line[1:])); err != nil {
+				return nil, NewFileReadError(path, err)
+			}
+			counter.Lines = 1
+
+			stats := authors[author]
+			stats.add(counter.Stats)
+			authors[author] = stats
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, NewFileReadError(path, err)
+	}
+	return authors, nil
+}
+
+// newBlameReport sums the stats of every author and ranks them by the field
+func newBlameReport(files map[string]map[string]Stats, field string) *BlameReport {
+	report := &BlameReport{Field: field, Authors: []*AuthorStats{}, Files: files}
+
+	byAuthor := map[string]*AuthorStats{}
+	for _, authors := range files {
+		for author, stats := range authors {
+			summary, found := byAuthor[author]
+			if !found {
+				summary = &AuthorStats{Author: author}
+				byAuthor[author] = summary
+				report.Authors = append(report.Authors, summary)
+			}
+			summary.Files++
+			summary.add(&stats)
+			report.Total.add(&stats)
+		}
+	}
+
+	sort.Slice(report.Authors, func(i, j int) bool {
+		a, _ := report.Authors[i].Value(field)
+		b, _ := report.Authors[j].Value(field)
+		if a != b {
+			return a > b
+		}
+		return report.Authors[i].Author < report.Authors[j].Author
+	})
+	return report
+}
+
+// authorsHeaderAndRows returns one row per author with their share of the field
+func (r *BlameReport) authorsHeaderAndRows() []Row {
+	header := Row{T(MsgHeaderAuthor), T(MsgHeaderFiles)}
+	header = append(header, (&Stats{}).Header()...)
+	data := []Row{append(header, T(MsgHeaderShare))}
+
+	total, _ := r.Total.Value(r.Field)
+	for _, author := range r.Authors {
+		value, _ := author.Value(r.Field)
+		row := Row{author.Author, author.Files}
+		row = append(row, author.ToRow()...)
+		data = append(data, append(row, fmt.Sprintf("%.1f%%", ratio(value, total)*100)))
+	}
+
+	row := Row{T(MsgTotal), len(r.Files)}
+	row = append(row, r.Total.ToRow()...)
+	return append(data, append(row, ""))
+}
+
+// matrixHeaderAndRows returns the field of each file per author, authors in ranking order
+func (r *BlameReport) matrixHeaderAndRows() []Row {
+	header := Row{T(MsgHeaderFile)}
+	for _, author := range r.Authors {
+		header = append(header, author.Author)
+	}
+	data := []Row{append(header, T(MsgTotal))}
+
+	paths := make([]string, 0, len(r.Files))
+	for path := range r.Files {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	for _, path := range paths {
+		row := Row{path}
+		sum := 0
+		for _, author := range r.Authors {
+			stats := r.Files[path][author.Author]
+			value, _ := stats.Value(r.Field)
+			row = append(row, value)
+			sum += value
+		}
+		data = append(data, append(row, sum))
+	}
+
+	row := Row{T(MsgTotal)}
+	for _, author := range r.Authors {
+		value, _ := author.Value(r.Field)
+		row = append(row, value)
+	}
+	total, _ := r.Total.Value(r.Field)
+	return append(data, append(row, total))
+}
+
+// headerAndRows returns the rows of the view, BlameViewAuthors by default
+func (r *BlameReport) headerAndRows(view string) []Row {
+	if view == BlameViewMatrix {
+		return r.matrixHeaderAndRows()
+	}
+	return r.authorsHeaderAndRows()
+}
+
+// ExportTable renders the view as a table
+func (r *BlameReport) ExportTable(view string) string {
+	return exportToTable(r.headerAndRows(view))
+}
+
+// ExportCSV exports the view as CSV
+func (r *BlameReport) ExportCSV(view string, filename ...string) (string, error) {
+	return exportToCSV(r.headerAndRows(view), filename...)
+}
+
+// ExportExcel exports the view to an Excel file
+func (r *BlameReport) ExportExcel(view string, filename ...string) error {
+	return exportToExcel(r.headerAndRows(view), filename...)
+}
+
+// ExportJSON exports the whole report as JSON
+func (r *BlameReport) ExportJSON(filename ...string) (string, error) {
+	return exportToJSON(r, filename...)
+}
@@ -0,0 +1,145 @@
+package wordcounter_test
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	wcg "github.com/100gle/wordcounter"
+)
+
+// createBlameBook extends createGitBook with a chapter committed by alice,
+// an uncommitted first line in intro.md and an untracked draft
+func createBlameBook(t *testing.T) string {
+	t.Helper()
+
+	dir := createGitBook(t)
+	if err := os.WriteFile(filepath.Join(dir, "part1", "ch05.md"), []byte("爱丽丝写的"), 0644); err != nil {
+		t.Fatalf("Failed to write test file: %v", err)
+	}
+	runGit(t, dir, "add", ".")
+	runGit(t, dir, "-c", "user.name=alice", "commit", "-q", "-m", "ch05")
+
+	if err := os.WriteFile(filepath.Join(dir, "intro.md"), []byte("未提交\n前言\n新的一段"), 0644); err != nil {
+		t.Fatalf("Failed to write test file: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "draft.md"), []byte("草稿"), 0644); err != nil {
+		t.Fatalf("Failed to write test file: %v", err)
+	}
+	return dir
+}
+
+func TestBlame(t *testing.T) {
+	dir := createBlameBook(t)
+	dc := wcg.NewDirCounter(dir, ".git")
+	if err := dc.Count(); err != nil {
+		t.Fatalf("DirCounter.Count() error = %v", err)
+	}
+
+	report, err := wcg.Blame(dc, wcg.ColumnChineseChars)
+	if err != nil {
+		t.Fatalf("Blame() error = %v", err)
+	}
+
+	want := []wcg.AuthorStats{
+		{Author: "test", Files: 5, Stats: wcg.Stats{Lines: 6, ChineseChars: 18, TotalChars: 18}},
+		{Author: "alice", Files: 1, Stats: wcg.Stats{Lines: 1, ChineseChars: 5, TotalChars: 5}},
+		{Author: "Not Committed Yet", Files: 1, Stats: wcg.Stats{Lines: 1, ChineseChars: 3, TotalChars: 3}},
+	}
+	if len(report.Authors) != len(want) {
+		t.Fatalf("Blame() authors = %+v, want %+v", report.Authors, want)
+	}
+	for i := range want {
+		if *report.Authors[i] != want[i] {
+			t.Errorf("Blame() author %d = %+v, want %+v", i, *report.Authors[i], want[i])
+		}
+	}
+	if _, found := report.Files["draft.md"]; found || len(report.Files) != 6 {
+		t.Errorf("Blame() files = %v, want the 6 tracked files", report.Files)
+	}
+	if report.Files["intro.md"]["test"].ChineseChars != 6 || report.Total.ChineseChars != 26 {
+		t.Errorf("Blame() intro.md = %+v, total = %+v", report.Files["intro.md"], report.Total)
+	}
+}
+
+func TestBlame_Revision(t *testing.T) {
+	dir := createBlameBook(t)
+	dc := wcg.NewDirCounter(dir)
+	dc.SetGitRevision("v1")
+	if err := dc.Count(); err != nil {
+		t.Fatalf("DirCounter.Count() error = %v", err)
+	}
+
+	report, err := wcg.Blame(dc, wcg.ColumnTotalChars)
+	if err != nil {
+		t.Fatalf("Blame() error = %v", err)
+	}
+	if len(report.Authors) != 1 || report.Authors[0].Author != "test" || report.Authors[0].TotalChars != 18 {
+		t.Errorf("Blame() at v1 authors = %+v, want only test with 18 chars", report.Authors)
+	}
+
+	if _, err := wcg.Blame(dc, "paragraphs"); err == nil {
+		t.Error("Blame() with unknown field should return error")
+	}
+	dc.SetGitRevision(wcg.GitIndex)
+	if _, err := wcg.Blame(dc, wcg.ColumnLines); err == nil {
+		t.Error("Blame() of the staging index should return error")
+	}
+}
+
+func TestBlameReport_Export(t *testing.T) {
+	dir := createBlameBook(t)
+	dc := wcg.NewDirCounter(dir, ".git")
+	if err := dc.Count(); err != nil {
+		t.Fatalf("DirCounter.Count() error = %v", err)
+	}
+	report, err := wcg.Blame(dc, wcg.ColumnChineseChars)
+	if err != nil {
+		t.Fatalf("Blame() error = %v", err)
+	}
+
+	table := report.ExportTable(wcg.BlameViewAuthors)
+	for _, want := range []string{"AUTHOR", "SHARE", "alice", "69.2%", "Total"} {
+		if !strings.Contains(table, want) {
+			t.Errorf("BlameReport.ExportTable() missing %q:\n%s", want, table)
+		}
+	}
+
+	csvData, err := report.ExportCSV(wcg.BlameViewMatrix)
+	if err != nil {
+		t.Fatalf("BlameReport.ExportCSV() error = %v", err)
+	}
+	wantRows := []string{
+		"File,test,alice,Not Committed Yet,Total",
+		"intro.md,6,0,3,9",
+		"part1/ch05.md,0,5,0,5",
+		"Total,18,5,3,26",
+	}
+	for _, want := range wantRows {
+		if !strings.Contains(csvData, want) {
+			t.Errorf("BlameReport.ExportCSV() missing %q:\n%s", want, csvData)
+		}
+	}
+
+	excelPath := filepath.Join(t.TempDir(), "blame.xlsx")
+	if err := report.ExportExcel(wcg.BlameViewMatrix, excelPath); err != nil {
+		t.Fatalf("BlameReport.ExportExcel() error = %v", err)
+	}
+	if _, err := os.Stat(excelPath); err != nil {
+		t.Errorf("BlameReport.ExportExcel() did not create the file: %v", err)
+	}
+
+	jsonData, err := report.ExportJSON()
+	if err != nil {
+		t.Fatalf("BlameReport.ExportJSON() error = %v", err)
+	}
+	var decoded wcg.BlameReport
+	if err := json.Unmarshal([]byte(jsonData), &decoded); err != nil {
+		t.Fatalf("BlameReport.ExportJSON() returned invalid JSON: %v", err)
+	}
+	if len(decoded.Authors) != 3 || decoded.Authors[1].Author != "alice" || decoded.Files["part1/ch05.md"]["alice"].ChineseChars != 5 {
+		t.Errorf("BlameReport.ExportJSON() decoded = %+v", decoded)
+	}
+}
@@ -0,0 +1,192 @@
+package wordcounter
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// cacheVersion changes with the counting rules, so that caches of older versions are discarded
+const cacheVersion = 1
+
+// CacheEntry is the cached result of counting a file
+type CacheEntry struct {
+	Size        int64     `json:"size"`
+	ModTime     time.Time `json:"mod_time"`
+	Hash        string    `json:"hash"` // SHA-256 of the content
+	Stats       Stats     `json:"stats"`
+	FrontMatter string    `json:"front_matter,omitempty"` // YAML front matter of the file, parsed when its metadata is read
+}
+
+// Cache keeps the stats of counted files between runs. Files whose size and modification time
+// did not change are not read again, and files whose content hash did not change are not counted again.
+// Entries are discarded when the counting options change.
+type Cache struct {
+	Options string                 `json:"options"`
+	Files   map[string]*CacheEntry `json:"files"` // Keyed by slash separated path relative to the counted directory
+
+	path   string
+	mu     sync.Mutex
+	seen   map[string]bool
+	hits   int
+	misses int
+}
+
+// CacheFilePath returns the default cache file of a counted directory
+func CacheFilePath(dirname string) string {
+	return filepath.Join(ToAbsolutePath(dirname), DataDirName, CacheFileName)
+}
+
+// LoadCache reads a cache file. A missing or unreadable file yields an empty cache,
+// and so does a cache written with other counting options.
+func LoadCache(path, options string) *Cache {
+	cache := &Cache{}
+	if data, err := os.ReadFile(path); err == nil {
+		if json.Unmarshal(data, cache) != nil || cache.Options != options {
+			cache = &Cache{}
+		}
+	}
+
+	cache.Options = options
+	cache.path = path
+	cache.seen = map[string]bool{}
+	if cache.Files == nil {
+		cache.Files = map[string]*CacheEntry{}
+	}
+	return cache
+}
+
+// Save writes the entries of the files counted since the cache was loaded, dropping the others
+func (c *Cache) Save() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for path := range c.Files {
+		if !c.seen[path] {
+			delete(c.Files, path)
+		}
+	}
+
+	data, err := json.Marshal(c)
+	if err != nil {
+		return NewFileWriteError(c.path, err)
+	}
+	if err := os.MkdirAll(filepath.Dir(c.path), 0755); err != nil {
+		return NewFileWriteError(c.path, err)
+	}
+	// Write a temporary file first so an interrupted run never leaves a truncated cache
+	tmp := c.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return NewFileWriteError(c.path, err)
+	}
+	if err := os.Rename(tmp, c.path); err != nil {
+		return NewFileWriteError(c.path, err)
+	}
+	return nil
+}
+
+// Hits returns the number of files taken from the cache
+func (c *Cache) Hits() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.hits
+}
+
+// Misses returns the number of files that had to be counted
+func (c *Cache) Misses() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.misses
+}
+
+// HitRate returns the share of files taken from the cache, between 0 and 1
+func (c *Cache) HitRate() float64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return ratio(c.hits, c.hits+c.misses)
+}
+
+// lookup returns the entry of a file and marks it as seen
+func (c *Cache) lookup(relPath string) *CacheEntry {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.seen[relPath] = true
+	return c.Files[relPath]
+}
+
+// store records the entry of a file and whether it was a hit
+func (c *Cache) store(relPath string, entry *CacheEntry, hit bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.Files[relPath] = entry
+	if hit {
+		c.hits++
+	} else {
+		c.misses++
+	}
+}
+
+// count counts the file, or takes its stats from the cache if it did not change
+func (c *Cache) count(fc *FileCounter, relPath string) error {
+	entry := c.lookup(relPath)
+
+	if entry != nil {
+		if info, err := os.Stat(fc.FileName); err == nil && info.Size() == entry.Size && info.ModTime().Equal(entry.ModTime) {
+			fc.size, fc.modTime = info.Size(), info.ModTime()
+			if fc.size == 0 {
+				fc.warnEmpty()
+			}
+			c.apply(fc, entry)
+			c.store(relPath, entry, true)
+			return nil
+		}
+	}
+
+	data, err := fc.readFile()
+	if err != nil {
+		return err
+	}
+	sum := sha256.Sum256(data)
+	hash := hex.EncodeToString(sum[:])
+
+	if entry != nil && entry.Hash == hash {
+		// Same content with a new modification time, e.g. a fresh checkout
+		entry = &CacheEntry{Size: fc.size, ModTime: fc.modTime, Hash: hash, Stats: entry.Stats, FrontMatter: entry.FrontMatter}
+		c.apply(fc, entry)
+		c.store(relPath, entry, true)
+		return nil
+	}
+
+	if err := fc.countData(data); err != nil {
+		return err
+	}
+	entry = &CacheEntry{Size: fc.size, ModTime: fc.modTime, Hash: hash, Stats: *fc.Stats, FrontMatter: string(fc.frontMatter)}
+	c.store(relPath, entry, false)
+	return nil
+}
+
+// apply sets the stats and metadata of the entry on the file counter
+func (c *Cache) apply(fc *FileCounter, entry *CacheEntry) {
+	*fc.Stats = entry.Stats
+	var frontMatter []byte
+	if entry.FrontMatter != "" {
+		frontMatter = []byte(entry.FrontMatter)
+	}
+	fc.setFrontMatter(frontMatter)
+}
+
+// SetCache makes Count and Refresh take unchanged files from the cache, nil disables caching.
+// Files of a git revision are never cached.
+func (dc *DirCounter) SetCache(cache *Cache) {
+	dc.cache = cache
+}
+
+// CountingOptions identifies the options that affect counting results, used to invalidate caches
+func (dc *DirCounter) CountingOptions() string {
+	return fmt.Sprintf("v%d:%s", cacheVersion, dc.options)
+}
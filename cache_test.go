@@ -0,0 +1,110 @@
+package wordcounter_test
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	wcg "github.com/100gle/wordcounter"
+)
+
+// countWithCache counts the directory with the cache file and saves it
+func countWithCache(t *testing.T, dir, options string) (*wcg.DirCounter, *wcg.Cache) {
+	t.Helper()
+
+	dc := wcg.NewDirCounter(dir, wcg.DataDirName)
+	if options == "" {
+		options = dc.CountingOptions()
+	}
+	cache := wcg.LoadCache(wcg.CacheFilePath(dir), options)
+	dc.SetCache(cache)
+	if err := dc.Count(); err != nil {
+		t.Fatalf("DirCounter.Count() error = %v", err)
+	}
+	if err := cache.Save(); err != nil {
+		t.Fatalf("Cache.Save() error = %v", err)
+	}
+	return dc, cache
+}
+
+func TestCache(t *testing.T) {
+	dir := createBookDir(t)
+	if path := wcg.CacheFilePath(dir); path != filepath.Join(dir, ".wcg", "cache.json") {
+		t.Errorf("CacheFilePath() = %s", path)
+	}
+
+	uncached := wcg.NewDirCounter(dir, wcg.DataDirName)
+	if err := uncached.Count(); err != nil {
+		t.Fatalf("DirCounter.Count() error = %v", err)
+	}
+
+	_, cache := countWithCache(t, dir, "")
+	if cache.Hits() != 0 || cache.Misses() != 5 {
+		t.Errorf("first run hits = %d, misses = %d, want 0 and 5", cache.Hits(), cache.Misses())
+	}
+
+	dc, cache := countWithCache(t, dir, "")
+	if cache.Hits() != 5 || cache.Misses() != 0 || cache.HitRate() != 1 {
+		t.Errorf("second run hits = %d, misses = %d, want 5 and 0", cache.Hits(), cache.Misses())
+	}
+	if !reflect.DeepEqual(dc.GetRows(), uncached.GetRows()) {
+		t.Errorf("cached rows = %v, want %v", dc.GetRows(), uncached.GetRows())
+	}
+
+	// A new modification time with the same content is a hit, a changed content a miss
+	later := time.Now().Add(time.Hour)
+	if err := os.Chtimes(filepath.Join(dir, "intro.md"), later, later); err != nil {
+		t.Fatalf("Failed to change modification time: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "part1", "ch01.md"), []byte("第一章新的内容"), 0644); err != nil {
+		t.Fatalf("Failed to write test file: %v", err)
+	}
+	if err := os.Remove(filepath.Join(dir, "part2", "ch03.md")); err != nil {
+		t.Fatalf("Failed to remove test file: %v", err)
+	}
+	dc, cache = countWithCache(t, dir, "")
+	if cache.Hits() != 3 || cache.Misses() != 1 {
+		t.Errorf("third run hits = %d, misses = %d, want 3 and 1", cache.Hits(), cache.Misses())
+	}
+	if got := dc.GetFileCounters()[1].ChineseChars; got != 7 {
+		t.Errorf("changed file Chinese chars = %d, want 7", got)
+	}
+	if _, found := cache.Files["part2/ch03.md"]; found {
+		t.Error("Cache.Save() should drop removed files")
+	}
+
+	// Other counting options discard the cache
+	_, cache = countWithCache(t, dir, "other")
+	if cache.Hits() != 0 || cache.Misses() != 4 {
+		t.Errorf("run with other options hits = %d, misses = %d, want 0 and 4", cache.Hits(), cache.Misses())
+	}
+
+	// A corrupt cache is ignored
+	if err := os.WriteFile(wcg.CacheFilePath(dir), []byte("{"), 0644); err != nil {
+		t.Fatalf("Failed to write cache: %v", err)
+	}
+	if cache := wcg.LoadCache(wcg.CacheFilePath(dir), "other"); len(cache.Files) != 0 {
+		t.Errorf("LoadCache() of a corrupt file = %v, want empty", cache.Files)
+	}
+}
+
+func TestCache_Metadata(t *testing.T) {
+	dir := t.TempDir()
+	content := "---\ntitle: 第一篇\ndate: 2024-01-02\ntags: [go, news]\nweight: 3\n---\n正文"
+	if err := os.WriteFile(filepath.Join(dir, "post.md"), []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write test file: %v", err)
+	}
+
+	first, _ := countWithCache(t, dir, "")
+	second, cache := countWithCache(t, dir, "")
+	if cache.Hits() != 1 {
+		t.Fatalf("second run hits = %d, want 1", cache.Hits())
+	}
+	want := first.GetFileCounters()[0].Metadata()
+	got := second.GetFileCounters()[0].Metadata()
+	if want == nil || !reflect.DeepEqual(got, want) {
+		t.Errorf("cached metadata = %#v, want %#v", got, want)
+	}
+}
@@ -0,0 +1,206 @@
+package wordcounter
+
+import (
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Kinds of check violations
+const (
+	ViolationMin = "min"
+	ViolationMax = "max"
+)
+
+// CheckRule limits a Stats field of the files matching Path.
+// A zero Min or Max means no limit on that side.
+type CheckRule struct {
+	Path  string `yaml:"path,omitempty" toml:"path,omitempty" json:"path,omitempty"`    // Glob relative to the counted directory, empty for all files
+	Field string `yaml:"field,omitempty" toml:"field,omitempty" json:"field,omitempty"` // Stats field, chinese_chars by default
+	Min   int    `yaml:"min,omitempty" toml:"min,omitempty" json:"min,omitempty"`
+	Max   int    `yaml:"max,omitempty" toml:"max,omitempty" json:"max,omitempty"`
+}
+
+// Violation is a file breaking a rule
+type Violation struct {
+	File  string `json:"file"` // Slash separated path relative to the counted directory
+	Rule  string `json:"rule"`
+	Field string `json:"field"`
+	Kind  string `json:"kind"` // ViolationMin or ViolationMax
+	Value int    `json:"value"`
+	Limit int    `json:"limit"`
+}
+
+// CheckReport is the result of checking the rules against the counted files
+type CheckReport struct {
+	Files      int         `json:"files"`
+	Rules      int         `json:"rules"`
+	Violations []Violation `json:"violations"`
+}
+
+// ParseCheckRule parses a rule written as [glob:]field>=min, [glob:]field<=max
+// or both limits separated by a comma, e.g. "ch*.md:chinese_chars>=2000,<=8000".
+func ParseCheckRule(s string) (CheckRule, error) {
+	rule := CheckRule{}
+	expr := strings.TrimSpace(s)
+	if i := strings.LastIndex(expr, ":"); i >= 0 {
+		rule.Path = strings.TrimSpace(expr[:i])
+		expr = expr[i+1:]
+	}
+
+	for i, cond := range strings.Split(expr, ",") {
+		cond = strings.TrimSpace(cond)
+		op := ">="
+		pos := strings.Index(cond, op)
+		if pos < 0 {
+			op = "<="
+			pos = strings.Index(cond, op)
+		}
+		if pos < 0 {
+			return CheckRule{}, NewInvalidInputError(fmt.Sprintf("invalid rule %q, expected [glob:]field>=min or field<=max", s))
+		}
+
+		field := strings.TrimSpace(cond[:pos])
+		if i == 0 {
+			rule.Field = field
+		} else if field != "" && field != rule.Field {
+			return CheckRule{}, NewInvalidInputError(fmt.Sprintf("invalid rule %q, all limits must use the same field", s))
+		}
+
+		limit, err := strconv.Atoi(strings.TrimSpace(cond[pos+len(op):]))
+		if err != nil {
+			return CheckRule{}, NewInvalidInputError(fmt.Sprintf("invalid rule %q, limit must be an integer", s))
+		}
+		if op == ">=" {
+			rule.Min = limit
+		} else {
+			rule.Max = limit
+		}
+	}
+
+	return rule, rule.Validate()
+}
+
+// field returns the Stats field of the rule
+func (r *CheckRule) field() string {
+	if r.Field == "" {
+		return ColumnChineseChars
+	}
+	return r.Field
+}
+
+// String formats the rule in the form accepted by ParseCheckRule
+func (r CheckRule) String() string {
+	limits := []string{}
+	if r.Min > 0 {
+		limits = append(limits, fmt.Sprintf(">=%d", r.Min))
+	}
+	if r.Max > 0 {
+		limits = append(limits, fmt.Sprintf("<=%d", r.Max))
+	}
+
+	s := r.field() + strings.Join(limits, ",")
+	if r.Path != "" {
+		s = r.Path + ":" + s
+	}
+	return s
+}
+
+// Validate checks that the rule has a limit, a known field and a valid path pattern
+func (r *CheckRule) Validate() error {
+	if r.Min < 0 || r.Max < 0 || (r.Min == 0 && r.Max == 0) {
+		return NewInvalidInputError(fmt.Sprintf("rule %q must have a positive min or max", r.String()))
+	}
+	if r.Max > 0 && r.Min > r.Max {
+		return NewInvalidInputError(fmt.Sprintf("rule %q min must not be greater than max", r.String()))
+	}
+	if _, err := (&Stats{}).Value(r.field()); err != nil {
+		return err
+	}
+	if r.Path != "" {
+		if _, err := filepath.Match(r.Path, ""); err != nil {
+			return NewPatternMatchError(r.Path, err).WithContext("rule", r.String())
+		}
+	}
+	return nil
+}
+
+// matches reports whether a slash separated path relative to the counted directory is checked by the rule
+func (r *CheckRule) matches(relPath string) bool {
+	return r.Path == "" || matchPathPattern(r.Path, relPath)
+}
+
+// Check tests every file counted by the DirCounter against the rules
+func Check(dc *DirCounter, rules []CheckRule) (*CheckReport, error) {
+	for i := range rules {
+		if err := rules[i].Validate(); err != nil {
+			return nil, err
+		}
+	}
+
+	root := ToAbsolutePath(dc.dirname)
+	report := &CheckReport{
+		Files:      len(dc.fileCounters),
+		Rules:      len(rules),
+		Violations: []Violation{},
+	}
+
+	for _, fc := range dc.fileCounters {
+		relPath, err := filepath.Rel(root, fc.FileName)
+		if err != nil {
+			continue
+		}
+		relPath = filepath.ToSlash(relPath)
+
+		for _, rule := range rules {
+			if !rule.matches(relPath) {
+				continue
+			}
+			value, _ := fc.Stats.Value(rule.field())
+			violation := Violation{File: relPath, Rule: rule.String(), Field: rule.field(), Value: value}
+			if rule.Min > 0 && value < rule.Min {
+				violation.Kind, violation.Limit = ViolationMin, rule.Min
+			} else if rule.Max > 0 && value > rule.Max {
+				violation.Kind, violation.Limit = ViolationMax, rule.Max
+			} else {
+				continue
+			}
+			report.Violations = append(report.Violations, violation)
+		}
+	}
+	return report, nil
+}
+
+// Passed reports whether no file breaks a rule
+func (r *CheckReport) Passed() bool {
+	return len(r.Violations) == 0
+}
+
+// headerAndRows returns the violation table
+func (r *CheckReport) headerAndRows() []Row {
+	data := []Row{{T(MsgHeaderFile), T(MsgHeaderRule), T(MsgHeaderField), T(MsgHeaderValue), T(MsgHeaderLimit)}}
+	for _, v := range r.Violations {
+		limit := ">= " + strconv.Itoa(v.Limit)
+		if v.Kind == ViolationMax {
+			limit = "<= " + strconv.Itoa(v.Limit)
+		}
+		data = append(data, Row{v.File, v.Rule, v.Field, v.Value, limit})
+	}
+	return data
+}
+
+// ExportTable renders the violations as a table
+func (r *CheckReport) ExportTable() string {
+	return exportToTable(r.headerAndRows())
+}
+
+// ExportCSV exports the violations as CSV
+func (r *CheckReport) ExportCSV(filename ...string) (string, error) {
+	return exportToCSV(r.headerAndRows(), filename...)
+}
+
+// ExportJSON exports the whole report as JSON
+func (r *CheckReport) ExportJSON(filename ...string) (string, error) {
+	return exportToJSON(r, filename...)
+}
@@ -0,0 +1,139 @@
+package wordcounter_test
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	wcg "github.com/100gle/wordcounter"
+)
+
+func TestParseCheckRule(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    wcg.CheckRule
+		wantErr bool
+	}{
+		{name: "min", input: "chinese_chars>=100", want: wcg.CheckRule{Field: "chinese_chars", Min: 100}},
+		{name: "max with glob", input: "abstract.md:total_chars<=300", want: wcg.CheckRule{Path: "abstract.md", Field: "total_chars", Max: 300}},
+		{name: "min and max", input: "ch*.md: lines >= 2, <= 40", want: wcg.CheckRule{Path: "ch*.md", Field: "lines", Min: 2, Max: 40}},
+		{name: "default field", input: "part1/*:>=10", want: wcg.CheckRule{Path: "part1/*", Min: 10}},
+		{name: "missing operator", input: "chinese_chars=100", wantErr: true},
+		{name: "invalid limit", input: "chinese_chars>=many", wantErr: true},
+		{name: "mixed fields", input: "lines>=1,total_chars<=2", wantErr: true},
+		{name: "unknown field", input: "paragraphs>=1", wantErr: true},
+		{name: "min above max", input: "lines>=10,<=5", wantErr: true},
+		{name: "invalid glob", input: "[:lines>=1", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := wcg.ParseCheckRule(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseCheckRule() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && got != tt.want {
+				t.Errorf("ParseCheckRule() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckRule_String(t *testing.T) {
+	rule := wcg.CheckRule{Path: "ch*.md", Min: 2, Max: 40}
+	if got := rule.String(); got != "ch*.md:chinese_chars>=2,<=40" {
+		t.Errorf("CheckRule.String() = %s", got)
+	}
+	parsed, err := wcg.ParseCheckRule(rule.String())
+	if err != nil || parsed.String() != rule.String() {
+		t.Errorf("ParseCheckRule(String()) = %+v, %v", parsed, err)
+	}
+}
+
+func TestCheck(t *testing.T) {
+	dir := createBookDir(t)
+	dc := wcg.NewDirCounter(dir)
+	if err := dc.Count(); err != nil {
+		t.Fatalf("DirCounter.Count() error = %v", err)
+	}
+
+	rules := []wcg.CheckRule{
+		{Path: "ch*.md", Min: 4},
+		{Path: "intro.md", Field: "total_chars", Max: 1},
+		{Path: "part2/*", Field: "lines", Min: 1, Max: 1},
+	}
+	report, err := wcg.Check(dc, rules)
+	if err != nil {
+		t.Fatalf("Check() error = %v", err)
+	}
+	if report.Passed() || report.Files != 5 || report.Rules != 3 {
+		t.Errorf("Check() = %+v, want 3 rules on 5 files with violations", report)
+	}
+
+	got := map[string]wcg.Violation{}
+	for _, v := range report.Violations {
+		got[v.File] = v
+	}
+	want := map[string]wcg.Violation{
+		"intro.md":          {File: "intro.md", Rule: "intro.md:total_chars<=1", Field: "total_chars", Kind: wcg.ViolationMax, Value: 2, Limit: 1},
+		"part1/sec/ch02.md": {File: "part1/sec/ch02.md", Rule: "ch*.md:chinese_chars>=4", Field: "chinese_chars", Kind: wcg.ViolationMin, Value: 3, Limit: 4},
+		"part2/ch03.md":     {File: "part2/ch03.md", Rule: "ch*.md:chinese_chars>=4", Field: "chinese_chars", Kind: wcg.ViolationMin, Value: 3, Limit: 4},
+	}
+	if len(got) != len(want) || len(report.Violations) != len(want) {
+		t.Fatalf("Check() violations = %+v, want %+v", report.Violations, want)
+	}
+	for file, w := range want {
+		if got[file] != w {
+			t.Errorf("Check() violation of %s = %+v, want %+v", file, got[file], w)
+		}
+	}
+
+	report, err = wcg.Check(dc, []wcg.CheckRule{{Field: "total_chars", Min: 1}})
+	if err != nil || !report.Passed() {
+		t.Errorf("Check() = %+v, %v, want passed", report, err)
+	}
+
+	if _, err := wcg.Check(dc, []wcg.CheckRule{{Path: "*.md"}}); err == nil {
+		t.Error("Check() with a rule without limits should return error")
+	}
+}
+
+func TestCheckReport_Export(t *testing.T) {
+	report := &wcg.CheckReport{
+		Files: 2,
+		Rules: 1,
+		Violations: []wcg.Violation{
+			{File: "a.md", Rule: "lines<=1", Field: "lines", Kind: wcg.ViolationMax, Value: 3, Limit: 1},
+		},
+	}
+
+	table := report.ExportTable()
+	for _, want := range []string{"RULE", "a.md", "lines<=1", "<= 1"} {
+		if !strings.Contains(table, want) {
+			t.Errorf("CheckReport.ExportTable() missing %q:\n%s", want, table)
+		}
+	}
+
+	csvData, err := report.ExportCSV()
+	if err != nil {
+		t.Fatalf("CheckReport.ExportCSV() error = %v", err)
+	}
+	if !strings.Contains(csvData, "a.md,lines<=1,lines,3,<= 1") {
+		t.Errorf("CheckReport.ExportCSV() = %s", csvData)
+	}
+
+	jsonData, err := report.ExportJSON()
+	if err != nil {
+		t.Fatalf("CheckReport.ExportJSON() error = %v", err)
+	}
+	if !strings.Contains(jsonData, `"rule": "lines<=1"`) {
+		t.Errorf("CheckReport.ExportJSON() should not escape the rule: %s", jsonData)
+	}
+	var decoded wcg.CheckReport
+	if err := json.Unmarshal([]byte(jsonData), &decoded); err != nil {
+		t.Fatalf("CheckReport.ExportJSON() returned invalid JSON: %v", err)
+	}
+	if decoded.Files != 2 || len(decoded.Violations) != 1 || decoded.Violations[0] != report.Violations[0] {
+		t.Errorf("CheckReport.ExportJSON() decoded = %+v", decoded)
+	}
+}
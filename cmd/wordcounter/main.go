@@ -1,12 +1,22 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"log"
+	"log/slog"
 	"os"
+	"os/signal"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
 
 	wcg "github.com/100gle/wordcounter"
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"gopkg.in/yaml.v3"
 )
 
 var (
@@ -16,12 +26,249 @@ var (
 	excludePattern []string
 	withTotal      bool
 	relativePath   bool
+	templateName   string
+	columns        []string
+	sortBy         string
+	sortDesc       bool
+	top            int
+	where          []string
+	tree           bool
+	depth          int
+	groupBy        string
+	record         bool
+	historyFile    string
+	gitRev         string
+	watch          bool
+	watchPoll      bool
+	watchInterval  time.Duration
+	noCache        bool
+	countOptions   wcg.CountOptions
 )
 
 // rootCmd represents the base command when called without any subcommands
 var rootCmd = &cobra.Command{
-	Use:   "wcg",
-	Short: "wordcounter is a simple tool that counts the chinese characters in a file",
+	Use:              "wcg",
+	Short:            "wordcounter is a simple tool that counts the chinese characters in a file",
+	PersistentPreRun: setup,
+}
+
+var lang string
+
+// setup runs before every command
+func setup(cmd *cobra.Command, args []string) {
+	setupLanguage(cmd, args)
+	setupConfig(cmd, args)
+}
+
+// setupLanguage selects the language from --lang, or from the locale environment if not given
+func setupLanguage(cmd *cobra.Command, args []string) {
+	if lang == "" {
+		lang = wcg.DetectLanguage()
+	}
+	if err := wcg.SetLanguage(lang); err != nil {
+		fatal(cmd, wcg.T(wcg.MsgErrUnsupportedLang, lang))
+	}
+}
+
+// fatal exits with the error status of the command, which is not 1 for check as 1 means violations
+func fatal(cmd *cobra.Command, msg string) {
+	if cmd == checkCmd {
+		checkFatal(msg)
+	}
+	log.Fatal(msg)
+}
+
+var (
+	configFile    string
+	configProfile string
+	projectConfig = &wcg.ProjectConfig{}
+)
+
+// configTarget returns the path from which the configuration file is discovered
+func configTarget(cmd *cobra.Command, args []string) string {
+	if cmd == diffCmd && gitDir != "" {
+		return gitDir
+	}
+	if cmd == serverCmd || cmd == diffCmd || len(args) == 0 || args[0] == "" {
+		return "."
+	}
+	return args[0]
+}
+
+// configPath returns the --config file, or the one discovered upward from the target path
+func configPath(target string) string {
+	if configFile != "" {
+		return configFile
+	}
+	return wcg.FindConfigFile(target)
+}
+
+// setupConfig loads the project configuration with the --profile applied,
+// and uses it as the defaults of the count and server flags not given on the command line.
+// The other commands counting files take the countingFlags of the count section.
+func setupConfig(cmd *cobra.Command, args []string) {
+	config, err := wcg.LoadProjectConfig(configPath(configTarget(cmd, args)))
+	if err != nil {
+		fatal(cmd, wcg.T(wcg.MsgErrConfig, err))
+	}
+	if projectConfig, err = config.WithProfile(configProfile); err != nil {
+		fatal(cmd, wcg.T(wcg.MsgErrConfig, err))
+	}
+
+	switch cmd {
+	case countCmd:
+		err = applyConfig(cmd, projectConfig.Count)
+	case serverCmd:
+		err = applyConfig(cmd, projectConfig.Server)
+	case goalCmd, checkCmd, diffCmd, blameCmd:
+		err = applyCountingConfig(cmd, projectConfig.Count)
+	}
+	if err != nil {
+		fatal(cmd, wcg.T(wcg.MsgErrConfig, err))
+	}
+}
+
+// countingFlags are the flags of wcg count that change the counts, shared by the commands counting files
+var countingFlags = []string{"exclude", "strip-markdown", "exclude-punctuation", "count-words"}
+
+// applyCountingConfig sets the countingFlags of the command from the count section, so that it counts
+// like wcg count. Flags the command lacks are set through countCmd, which binds the same variables.
+func applyCountingConfig(cmd *cobra.Command, defaults wcg.FlagDefaults) error {
+	own, shared := wcg.FlagDefaults{}, wcg.FlagDefaults{}
+	for _, name := range countingFlags {
+		value, ok := defaults[name]
+		if !ok {
+			continue
+		}
+		if cmd.LocalFlags().Lookup(name) != nil {
+			own[name] = value
+		} else {
+			shared[name] = value
+		}
+	}
+	if err := applyConfig(cmd, own); err != nil {
+		return err
+	}
+	return applyConfig(countCmd, shared)
+}
+
+// applyConfig sets the flags of the command from the configuration, unless given on the command line
+func applyConfig(cmd *cobra.Command, defaults wcg.FlagDefaults) error {
+	for name, value := range defaults {
+		flag := cmd.LocalFlags().Lookup(name)
+		if flag == nil {
+			return fmt.Errorf("unknown flag of %s: %s", cmd.Name(), name)
+		}
+		if flag.Changed {
+			continue
+		}
+
+		var err error
+		if slice, ok := flag.Value.(pflag.SliceValue); ok {
+			err = slice.Replace(configStrings(value))
+		} else {
+			err = flag.Value.Set(fmt.Sprint(value))
+		}
+		if err != nil {
+			return fmt.Errorf("invalid value of %s: %v", name, err)
+		}
+	}
+	return nil
+}
+
+// configStrings converts a list, or a single value, of the configuration to strings
+func configStrings(value any) []string {
+	items, ok := value.([]any)
+	if !ok {
+		return []string{fmt.Sprint(value)}
+	}
+	values := make([]string, len(items))
+	for i, item := range items {
+		values[i] = fmt.Sprint(item)
+	}
+	return values
+}
+
+// flagValues returns the current values of the local flags of the command, typed like in the configuration
+func flagValues(cmd *cobra.Command) map[string]any {
+	values := map[string]any{}
+	cmd.LocalFlags().VisitAll(func(flag *pflag.Flag) {
+		if flag.Name == "help" {
+			return
+		}
+		if slice, ok := flag.Value.(pflag.SliceValue); ok {
+			values[flag.Name] = slice.GetSlice()
+			return
+		}
+		value := flag.Value.String()
+		switch flag.Value.Type() {
+		case "bool":
+			values[flag.Name], _ = strconv.ParseBool(value)
+		case "int":
+			values[flag.Name], _ = strconv.Atoi(value)
+		case "int64":
+			values[flag.Name], _ = strconv.ParseInt(value, 10, 64)
+		case "float64":
+			values[flag.Name], _ = strconv.ParseFloat(value, 64)
+		default:
+			values[flag.Name] = value
+		}
+	})
+	return values
+}
+
+// effectiveConfig is printed by config show
+type effectiveConfig struct {
+	File    string          `yaml:"file"`
+	Profile string          `yaml:"profile,omitempty"`
+	Count   map[string]any  `yaml:"count"`
+	Server  map[string]any  `yaml:"server"`
+	Goals   []wcg.Goal      `yaml:"goals,omitempty"`
+	Check   []wcg.CheckRule `yaml:"check,omitempty"`
+}
+
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Inspect the project configuration",
+}
+
+var configShowCmd = &cobra.Command{
+	Use:   "show [path]",
+	Short: "Print the effective configuration of the count and server commands for a path",
+	Args:  cobra.MaximumNArgs(1),
+	Run:   runConfigShow,
+}
+
+func runConfigShow(cmd *cobra.Command, args []string) {
+	path := configPath(configTarget(cmd, args))
+	if err := applyConfig(countCmd, projectConfig.Count); err != nil {
+		log.Fatal(wcg.T(wcg.MsgErrConfig, err))
+	}
+	if err := applyConfig(serverCmd, projectConfig.Server); err != nil {
+		log.Fatal(wcg.T(wcg.MsgErrConfig, err))
+	}
+
+	if path == "" {
+		path = wcg.T(wcg.MsgNoConfigFile)
+	}
+	server := flagValues(serverCmd)
+	for _, name := range secretFlags {
+		if values, ok := server[name].([]string); ok && len(values) > 0 {
+			server[name] = "<redacted>"
+		}
+	}
+	data, err := yaml.Marshal(effectiveConfig{
+		File:    path,
+		Profile: configProfile,
+		Count:   flagValues(countCmd),
+		Server:  server,
+		Goals:   projectConfig.Goals,
+		Check:   projectConfig.Check,
+	})
+	if err != nil {
+		log.Fatal(wcg.T(wcg.MsgErrConfig, err))
+	}
+	fmt.Print(string(data))
 }
 
 var countCmd = &cobra.Command{
@@ -32,12 +279,12 @@ var countCmd = &cobra.Command{
 
 func runWordCounter(cmd *cobra.Command, args []string) {
 	if len(args) == 0 {
-		log.Fatal("Error: path argument is required")
+		log.Fatal(wcg.T(wcg.MsgErrPathRequired))
 	}
 
 	path := args[0]
 	if path == "" {
-		log.Fatal("Error: path cannot be empty")
+		log.Fatal(wcg.T(wcg.MsgErrPathEmpty))
 	}
 
 	switch mode {
@@ -46,17 +293,20 @@ func runWordCounter(cmd *cobra.Command, args []string) {
 	case "file":
 		runFileCounter(path)
 	default:
-		log.Fatal("Error: Invalid mode. Choose either 'dir' or 'file'")
+		log.Fatal(wcg.T(wcg.MsgErrInvalidMode))
 	}
 }
 
-func runDirCounter(dirPath string) {
+// newDirCounter creates a DirCounter for the directory with the ignore file,
+// the data directory and --exclude patterns ignored
+func newDirCounter(dirPath string) *wcg.DirCounter {
 	// Validate directory path
 	if _, err := os.Stat(dirPath); os.IsNotExist(err) {
-		log.Fatalf("Error: Directory does not exist: %s", dirPath)
+		log.Fatal(wcg.T(wcg.MsgErrDirNotExist, dirPath))
 	}
 
 	ignores := wcg.DiscoverIgnoreFile()
+	ignores = append(ignores, wcg.DataDirName)
 	ignores = append(ignores, excludePattern...)
 
 	pathDisplayMode := wcg.PathDisplayAbsolute
@@ -65,34 +315,230 @@ func runDirCounter(dirPath string) {
 	}
 
 	counter := wcg.NewDirCounterWithPathMode(dirPath, pathDisplayMode, ignores...)
+	counter.SetOptions(countOptions)
+	return counter
+}
+
+// reportColumns returns the --columns, with the words column added to the default ones by --count-words
+func reportColumns() []string {
+	if len(columns) == 0 && countOptions.CountWords {
+		return append(append([]string{}, wcg.DefaultColumns...), wcg.ColumnWords)
+	}
+	return columns
+}
+
+func runDirCounter(dirPath string) {
+	counter := newDirCounter(dirPath)
+	counter.SetGitRevision(gitRev)
+	if columns := reportColumns(); len(columns) > 0 {
+		if err := counter.SetColumns(columns...); err != nil {
+			log.Fatal(wcg.T(wcg.MsgErrInvalidColumns, err))
+		}
+	}
+	if err := counter.SetQuery(buildQuery()); err != nil {
+		log.Fatal(wcg.T(wcg.MsgErrInvalidQuery, err))
+	}
 	if withTotal {
 		counter.EnableTotal()
 	}
+	cache := enableCache(counter, dirPath)
+	if watch {
+		runWatch(counter, dirPath)
+		saveCache(cache)
+		return
+	}
 	if err := counter.Count(); err != nil {
-		log.Fatalf("Error counting files in directory: %v", err)
+		log.Fatal(wcg.T(wcg.MsgErrCountDir, err))
+	}
+	saveCache(cache)
+	if record {
+		recordHistory(counter, dirPath)
+	}
+
+	if tree {
+		exportTree(counter)
+		return
+	}
+	if groupBy != "" {
+		exportGroups(counter)
+		return
 	}
 
 	switch exportType {
 	case "csv":
 		csvData, err := counter.ExportCSV(exportPath)
 		if err != nil {
-			log.Fatalf("Error exporting to CSV: %v", err)
+			log.Fatal(wcg.T(wcg.MsgErrExportCSV, err))
 		}
 		fmt.Println(csvData)
 	case "excel":
 		if err := counter.ExportExcel(exportPath); err != nil {
-			log.Fatalf("Error exporting to Excel: %v", err)
+			log.Fatal(wcg.T(wcg.MsgErrExportExcel, err))
+		}
+		fmt.Println(wcg.T(wcg.MsgExcelExported, exportPath))
+	case "json":
+		jsonData, err := counter.ExportJSON(jsonOutputPath())
+		if err != nil {
+			log.Fatal(wcg.T(wcg.MsgErrExportJSON, err))
 		}
-		fmt.Printf("Excel file exported to: %s\n", exportPath)
+		fmt.Println(jsonData)
+	case "template":
+		output, err := counter.ExportTemplate(templateName, templateOutputPath())
+		if err != nil {
+			log.Fatal(wcg.T(wcg.MsgErrRenderTemplate, err))
+		}
+		fmt.Println(output)
 	default:
 		fmt.Println(counter.ExportTable())
 	}
 }
 
+// enableCache makes the counter use the cache of the directory, unless disabled with --no-cache
+// or counting a git revision
+func enableCache(counter *wcg.DirCounter, dirPath string) *wcg.Cache {
+	if noCache || counter.GitRevision() != "" {
+		return nil
+	}
+	cache := wcg.LoadCache(wcg.CacheFilePath(dirPath), counter.CountingOptions())
+	counter.SetCache(cache)
+	return cache
+}
+
+// saveCache saves the cache and reports its hit rate
+func saveCache(cache *wcg.Cache) {
+	if cache == nil {
+		return
+	}
+	if err := cache.Save(); err != nil {
+		fmt.Fprintln(os.Stderr, wcg.T(wcg.MsgWarnCacheSave, err))
+	}
+	fmt.Fprintln(os.Stderr, wcg.T(wcg.MsgCacheStats, cache.Hits(), cache.Misses(), cache.HitRate()*100))
+}
+
+// runWatch recounts changed files until interrupted. On a terminal the table is redrawn in place.
+func runWatch(counter *wcg.DirCounter, dirPath string) {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	terminal := false
+	if info, err := os.Stdout.Stat(); err == nil {
+		terminal = info.Mode()&os.ModeCharDevice != 0
+	}
+
+	watcher := wcg.NewWatcher(counter, wcg.WatchOptions{Poll: watchPoll, Interval: watchInterval})
+	err := watcher.Run(ctx, func(update *wcg.WatchUpdate) {
+		if terminal {
+			// Move the cursor home and clear the screen
+			fmt.Print("\033[H\033[2J")
+		}
+		fmt.Println(wcg.T(wcg.MsgWatching, dirPath))
+		if update.Polling {
+			fmt.Println(wcg.T(wcg.MsgWatchPolling, watchInterval))
+		}
+		if tree {
+			fmt.Println(counter.ExportTreeTable(depth))
+		} else {
+			fmt.Println(counter.ExportTable())
+		}
+		if len(update.Changed) > 0 {
+			fmt.Println(wcg.T(wcg.MsgWatchChanged, len(update.Changed), time.Now().Format(time.TimeOnly)))
+		}
+		d := update.Delta
+		fmt.Println(wcg.T(wcg.MsgWatchDelta, d.Lines, d.ChineseChars, d.NonChineseChars, d.TotalChars))
+	})
+	if err != nil {
+		log.Fatal(wcg.T(wcg.MsgErrWatch, err))
+	}
+}
+
+// recordHistory appends the counting result to the history file
+func recordHistory(counter *wcg.DirCounter, dirPath string) {
+	path := historyFile
+	if path == "" {
+		path = wcg.HistoryFilePath(dirPath)
+	}
+	if err := wcg.AppendHistory(path, wcg.NewHistoryRecord(counter)); err != nil {
+		log.Fatal(wcg.T(wcg.MsgErrHistory, err))
+	}
+	fmt.Fprintln(os.Stderr, wcg.T(wcg.MsgHistoryRecorded, path))
+}
+
+// exportTree exports the per-directory subtotals instead of the per-file rows
+func exportTree(counter *wcg.DirCounter) {
+	switch exportType {
+	case "csv":
+		csvData, err := counter.ExportTreeCSV(depth, exportPath)
+		if err != nil {
+			log.Fatal(wcg.T(wcg.MsgErrExportCSV, err))
+		}
+		fmt.Println(csvData)
+	case "excel":
+		if err := counter.ExportTreeExcel(depth, exportPath); err != nil {
+			log.Fatal(wcg.T(wcg.MsgErrExportExcel, err))
+		}
+		fmt.Println(wcg.T(wcg.MsgExcelExported, exportPath))
+	case "json":
+		jsonData, err := counter.ExportTreeJSON(depth, jsonOutputPath())
+		if err != nil {
+			log.Fatal(wcg.T(wcg.MsgErrExportJSON, err))
+		}
+		fmt.Println(jsonData)
+	default:
+		fmt.Println(counter.ExportTreeTable(depth))
+	}
+}
+
+// exportGroups exports the aggregates of --group-by instead of the per-file rows
+func exportGroups(counter *wcg.DirCounter) {
+	by, err := wcg.ParseGroupBy(groupBy)
+	if err != nil {
+		log.Fatal(wcg.T(wcg.MsgErrInvalidGroupBy, err))
+	}
+
+	switch exportType {
+	case "csv":
+		csvData, err := counter.ExportGroupCSV(by, exportPath)
+		if err != nil {
+			log.Fatal(wcg.T(wcg.MsgErrExportCSV, err))
+		}
+		fmt.Println(csvData)
+	case "excel":
+		if err := counter.ExportGroupExcel(by, exportPath); err != nil {
+			log.Fatal(wcg.T(wcg.MsgErrExportExcel, err))
+		}
+		fmt.Println(wcg.T(wcg.MsgExcelExported, exportPath))
+	case "json":
+		jsonData, err := counter.ExportGroupJSON(by, jsonOutputPath())
+		if err != nil {
+			log.Fatal(wcg.T(wcg.MsgErrExportJSON, err))
+		}
+		fmt.Println(jsonData)
+	default:
+		fmt.Println(counter.ExportGroupTable(by))
+	}
+}
+
+// buildQuery builds the query from --where, --sort, --desc and --top, or nil if none is given
+func buildQuery() *wcg.Query {
+	if len(where) == 0 && sortBy == "" && top == 0 {
+		return nil
+	}
+
+	query := &wcg.Query{SortBy: sortBy, Desc: sortDesc, Top: top}
+	for _, expr := range where {
+		filter, err := wcg.ParseFilter(expr)
+		if err != nil {
+			log.Fatal(wcg.T(wcg.MsgErrInvalidQuery, err))
+		}
+		query.Where = append(query.Where, filter)
+	}
+	return query
+}
+
 func runFileCounter(filePath string) {
 	// Validate file path
 	if _, err := os.Stat(filePath); os.IsNotExist(err) {
-		log.Fatalf("Error: File does not exist: %s", filePath)
+		log.Fatal(wcg.T(wcg.MsgErrFileNotExist, filePath))
 	}
 
 	pathDisplayMode := wcg.PathDisplayAbsolute
@@ -101,65 +547,558 @@ func runFileCounter(filePath string) {
 	}
 
 	counter := wcg.NewFileCounterWithPathMode(filePath, pathDisplayMode)
+	counter.SetOptions(countOptions)
+	if columns := reportColumns(); len(columns) > 0 {
+		if err := counter.SetColumns(columns...); err != nil {
+			log.Fatal(wcg.T(wcg.MsgErrInvalidColumns, err))
+		}
+	}
 	if err := counter.Count(); err != nil {
-		log.Fatalf("Error counting characters in file: %v", err)
+		log.Fatal(wcg.T(wcg.MsgErrCountFile, err))
 	}
 
 	switch exportType {
 	case "csv":
 		csvData, err := counter.ExportCSV(exportPath)
 		if err != nil {
-			log.Fatalf("Error exporting to CSV: %v", err)
+			log.Fatal(wcg.T(wcg.MsgErrExportCSV, err))
 		}
 		fmt.Println(csvData)
 	case "excel":
 		if err := counter.ExportExcel(exportPath); err != nil {
-			log.Fatalf("Error exporting to Excel: %v", err)
+			log.Fatal(wcg.T(wcg.MsgErrExportExcel, err))
+		}
+		fmt.Println(wcg.T(wcg.MsgExcelExported, exportPath))
+	case "json":
+		jsonData, err := counter.ExportJSON(jsonOutputPath())
+		if err != nil {
+			log.Fatal(wcg.T(wcg.MsgErrExportJSON, err))
 		}
-		fmt.Printf("Excel file exported to: %s\n", exportPath)
+		fmt.Println(jsonData)
+	case "template":
+		output, err := counter.ExportTemplate(templateName, templateOutputPath())
+		if err != nil {
+			log.Fatal(wcg.T(wcg.MsgErrRenderTemplate, err))
+		}
+		fmt.Println(output)
 	default:
 		fmt.Println(counter.ExportTable())
 	}
 }
 
+// templateOutputPath returns the file the rendered template is written to.
+// The rendered report is only printed when --exportPath keeps its Excel default.
+func templateOutputPath() string {
+	if exportPath == wcg.DefaultExportPath {
+		return ""
+	}
+	return exportPath
+}
+
+// jsonOutputPath returns the file the JSON report is written to, following templateOutputPath
+func jsonOutputPath() string {
+	return templateOutputPath()
+}
+
+var (
+	historyPeriod string
+	historyFiles  bool
+)
+
+var historyCmd = &cobra.Command{
+	Use:   "history [path]",
+	Short: "Show the progress recorded by count --record",
+	Args:  cobra.MaximumNArgs(1),
+	Run:   runHistory,
+}
+
+func runHistory(cmd *cobra.Command, args []string) {
+	dirPath := "."
+	if len(args) > 0 {
+		dirPath = args[0]
+	}
+	path := historyFile
+	if path == "" {
+		path = wcg.HistoryFilePath(dirPath)
+	}
+
+	records, err := wcg.LoadHistory(path)
+	if err != nil {
+		log.Fatal(wcg.T(wcg.MsgErrHistory, err))
+	}
+	report, err := wcg.NewHistoryReport(records, historyPeriod)
+	if err != nil {
+		log.Fatal(wcg.T(wcg.MsgErrHistory, err))
+	}
+
+	view := wcg.HistoryViewPeriods
+	if historyFiles {
+		view = wcg.HistoryViewFiles
+	}
+
+	switch exportType {
+	case "csv":
+		csvData, err := report.ExportCSV(view, exportPath)
+		if err != nil {
+			log.Fatal(wcg.T(wcg.MsgErrExportCSV, err))
+		}
+		fmt.Println(csvData)
+	case "excel":
+		if err := report.ExportExcel(view, exportPath); err != nil {
+			log.Fatal(wcg.T(wcg.MsgErrExportExcel, err))
+		}
+		fmt.Println(wcg.T(wcg.MsgExcelExported, exportPath))
+	case "json":
+		jsonData, err := report.ExportJSON(jsonOutputPath())
+		if err != nil {
+			log.Fatal(wcg.T(wcg.MsgErrExportJSON, err))
+		}
+		fmt.Println(jsonData)
+	case "table":
+		table, err := report.ExportTable(view)
+		if err != nil {
+			log.Fatal(wcg.T(wcg.MsgErrHistory, err))
+		}
+		fmt.Println(table)
+	default:
+		log.Fatal(wcg.T(wcg.MsgErrExportType, exportType, "table, csv, excel, json"))
+	}
+}
+
+var goal wcg.Goal
+
+var goalCmd = &cobra.Command{
+	Use:   "goal [path]",
+	Short: "Show the progress of writing goals, exit with 1 if a minimum is not met",
+	Args:  cobra.MaximumNArgs(1),
+	Run:   runGoal,
+}
+
+func runGoal(cmd *cobra.Command, args []string) {
+	dirPath := "."
+	if len(args) > 0 {
+		dirPath = args[0]
+	}
+	path := configPath(dirPath)
+	if path == "" {
+		path = wcg.ConfigFilePath(dirPath)
+	}
+
+	goals := projectConfig.Goals
+	if goal.Target > 0 {
+		if goal.Name == "" {
+			goal.Name = filepath.Base(wcg.ToAbsolutePath(dirPath))
+		}
+		goals = append(goals, goal)
+	}
+	if len(goals) == 0 {
+		log.Fatal(wcg.T(wcg.MsgNoGoals, path))
+	}
+
+	counter := newDirCounter(dirPath)
+	if err := counter.Count(); err != nil {
+		log.Fatal(wcg.T(wcg.MsgErrCountDir, err))
+	}
+	progress, err := wcg.EvaluateGoals(counter, goals)
+	if err != nil {
+		log.Fatal(wcg.T(wcg.MsgErrGoal, err))
+	}
+
+	switch exportType {
+	case "csv":
+		csvData, err := wcg.ExportGoalsCSV(progress, exportPath)
+		if err != nil {
+			log.Fatal(wcg.T(wcg.MsgErrExportCSV, err))
+		}
+		fmt.Println(csvData)
+	case "json":
+		jsonData, err := wcg.ExportGoalsJSON(progress, jsonOutputPath())
+		if err != nil {
+			log.Fatal(wcg.T(wcg.MsgErrExportJSON, err))
+		}
+		fmt.Println(jsonData)
+	default:
+		fmt.Println(wcg.ExportGoalsTable(progress))
+	}
+
+	if wcg.GoalsFailed(progress) {
+		fmt.Fprintln(os.Stderr, wcg.T(wcg.MsgGoalsFailed))
+		os.Exit(1)
+	}
+}
+
+// Exit codes of the check command
+const (
+	checkExitViolations = 1
+	checkExitError      = 2
+)
+
+var checkRules []string
+
+var checkCmd = &cobra.Command{
+	Use:   "check [path]",
+	Short: "Check files against length rules, exit with 1 on violations and 2 on errors",
+	Args:  cobra.MaximumNArgs(1),
+	Run:   runCheck,
+}
+
+// checkFatal reports a tool error of the check command, so it is not mistaken for violations
+func checkFatal(msg string) {
+	fmt.Fprintln(os.Stderr, msg)
+	os.Exit(checkExitError)
+}
+
+func runCheck(cmd *cobra.Command, args []string) {
+	dirPath := "."
+	if len(args) > 0 {
+		dirPath = args[0]
+	}
+	if _, err := os.Stat(dirPath); os.IsNotExist(err) {
+		checkFatal(wcg.T(wcg.MsgErrDirNotExist, dirPath))
+	}
+	path := configPath(dirPath)
+	if path == "" {
+		path = wcg.ConfigFilePath(dirPath)
+	}
+
+	rules := projectConfig.Check
+	for _, r := range checkRules {
+		rule, err := wcg.ParseCheckRule(r)
+		if err != nil {
+			checkFatal(wcg.T(wcg.MsgErrCheck, err))
+		}
+		rules = append(rules, rule)
+	}
+	if len(rules) == 0 {
+		checkFatal(wcg.T(wcg.MsgNoRules, path))
+	}
+
+	counter := newDirCounter(dirPath)
+	cache := enableCache(counter, dirPath)
+	if err := counter.Count(); err != nil {
+		checkFatal(wcg.T(wcg.MsgErrCountDir, err))
+	}
+	saveCache(cache)
+	report, err := wcg.Check(counter, rules)
+	if err != nil {
+		checkFatal(wcg.T(wcg.MsgErrCheck, err))
+	}
+
+	switch exportType {
+	case "csv":
+		csvData, err := report.ExportCSV(exportPath)
+		if err != nil {
+			checkFatal(wcg.T(wcg.MsgErrExportCSV, err))
+		}
+		fmt.Println(csvData)
+	case "json":
+		jsonData, err := report.ExportJSON(jsonOutputPath())
+		if err != nil {
+			checkFatal(wcg.T(wcg.MsgErrExportJSON, err))
+		}
+		fmt.Println(jsonData)
+	default:
+		if report.Passed() {
+			fmt.Println(wcg.T(wcg.MsgCheckPassed, report.Files, report.Rules))
+		} else {
+			fmt.Println(report.ExportTable())
+		}
+	}
+
+	if !report.Passed() {
+		fmt.Fprintln(os.Stderr, wcg.T(wcg.MsgCheckFailed, len(report.Violations), report.Rules, report.Files))
+		os.Exit(checkExitViolations)
+	}
+}
+
+var gitDir string
+
+var diffCmd = &cobra.Command{
+	Use:   "diff <old> <new>",
+	Short: "Compare the counts of two directories, JSON reports or git revisions (with --git)",
+	Args:  cobra.ExactArgs(2),
+	Run:   runDiff,
+}
+
+// loadSnapshot counts a directory or reads a JSON report exported by count -e json
+func loadSnapshot(path string) *wcg.Snapshot {
+	info, err := os.Stat(path)
+	if err != nil {
+		log.Fatal(wcg.T(wcg.MsgErrFileNotExist, path))
+	}
+	if !info.IsDir() {
+		snapshot, err := wcg.LoadJSONSnapshot(path)
+		if err != nil {
+			log.Fatal(wcg.T(wcg.MsgErrDiff, err))
+		}
+		return snapshot
+	}
+
+	counter := newDirCounter(path)
+	if err := counter.Count(); err != nil {
+		log.Fatal(wcg.T(wcg.MsgErrCountDir, err))
+	}
+	return wcg.NewSnapshot(counter)
+}
+
+func runDiff(cmd *cobra.Command, args []string) {
+	var oldSnapshot, newSnapshot *wcg.Snapshot
+	if gitDir != "" {
+		ignores := append(wcg.DiscoverIgnoreFile(), excludePattern...)
+		snapshots := make([]*wcg.Snapshot, 2)
+		for i, rev := range args {
+			snapshot, err := wcg.GitSnapshotWithOptions(gitDir, rev, countOptions, ignores...)
+			if err != nil {
+				log.Fatal(wcg.T(wcg.MsgErrDiff, err))
+			}
+			snapshots[i] = snapshot
+		}
+		oldSnapshot, newSnapshot = snapshots[0], snapshots[1]
+	} else {
+		oldSnapshot, newSnapshot = loadSnapshot(args[0]), loadSnapshot(args[1])
+	}
+
+	report := wcg.DiffSnapshots(oldSnapshot, newSnapshot)
+	switch exportType {
+	case "csv":
+		csvData, err := report.ExportCSV(exportPath)
+		if err != nil {
+			log.Fatal(wcg.T(wcg.MsgErrExportCSV, err))
+		}
+		fmt.Println(csvData)
+	case "excel":
+		if err := report.ExportExcel(exportPath); err != nil {
+			log.Fatal(wcg.T(wcg.MsgErrExportExcel, err))
+		}
+		fmt.Println(wcg.T(wcg.MsgExcelExported, exportPath))
+	case "json":
+		jsonData, err := report.ExportJSON(jsonOutputPath())
+		if err != nil {
+			log.Fatal(wcg.T(wcg.MsgErrExportJSON, err))
+		}
+		fmt.Println(jsonData)
+	default:
+		fmt.Println(report.ExportTable())
+	}
+}
+
 var (
-	host string
-	port int
+	blameMatrix bool
+	blameField  string
 )
 
+var blameCmd = &cobra.Command{
+	Use:   "blame [path]",
+	Short: "Attribute the counts of a git repository to the authors of the lines",
+	Args:  cobra.MaximumNArgs(1),
+	Run:   runBlame,
+}
+
+func runBlame(cmd *cobra.Command, args []string) {
+	dirPath := "."
+	if len(args) > 0 {
+		dirPath = args[0]
+	}
+
+	counter := newDirCounter(dirPath)
+	counter.SetGitRevision(gitRev)
+	if err := counter.Count(); err != nil {
+		log.Fatal(wcg.T(wcg.MsgErrCountDir, err))
+	}
+	report, err := wcg.Blame(counter, blameField)
+	if err != nil {
+		log.Fatal(wcg.T(wcg.MsgErrBlame, err))
+	}
+
+	view := wcg.BlameViewAuthors
+	if blameMatrix {
+		view = wcg.BlameViewMatrix
+	}
+	switch exportType {
+	case "csv":
+		csvData, err := report.ExportCSV(view, exportPath)
+		if err != nil {
+			log.Fatal(wcg.T(wcg.MsgErrExportCSV, err))
+		}
+		fmt.Println(csvData)
+	case "excel":
+		if err := report.ExportExcel(view, exportPath); err != nil {
+			log.Fatal(wcg.T(wcg.MsgErrExportExcel, err))
+		}
+		fmt.Println(wcg.T(wcg.MsgExcelExported, exportPath))
+	case "json":
+		jsonData, err := report.ExportJSON(jsonOutputPath())
+		if err != nil {
+			log.Fatal(wcg.T(wcg.MsgErrExportJSON, err))
+		}
+		fmt.Println(jsonData)
+	default:
+		fmt.Println(report.ExportTable(view))
+	}
+}
+
+var serverConfig = wcg.DefaultServerConfig()
+
+// accessLog enables the access log of the server, on stderr
+var accessLog bool
+
+// apiKeyFile has more API keys of the server, one per line
+var apiKeyFile string
+
+// secretFlags are the server flags redacted by config show
+var secretFlags = []string{"api-key"}
+
 var serverCmd = &cobra.Command{
 	Use:   "server",
-	Short: "Run wordcounter as a server, only support pure text content",
+	Short: "Run wordcounter as a server to count text and uploaded files",
 	Run:   runWordCounterServer,
 }
 
 func runWordCounterServer(cmd *cobra.Command, args []string) {
 	srv := wcg.NewWordCounterServer()
-	if err := srv.Run(port); err != nil {
-		log.Fatal(err)
+	srv.Config = serverConfig
+	if accessLog {
+		srv.Config.AccessLog = slog.New(slog.NewJSONHandler(os.Stderr, nil))
+	}
+	if apiKeyFile != "" {
+		keys, err := wcg.LoadAPIKeys(apiKeyFile)
+		if err != nil {
+			log.Fatal(wcg.T(wcg.MsgErrServer, err))
+		}
+		srv.Config.APIKeys = append(append([]string{}, serverConfig.APIKeys...), keys...)
+	}
+	if err := srv.Start(); err != nil {
+		log.Fatal(wcg.T(wcg.MsgErrServer, err))
 	}
+	fmt.Println(wcg.T(wcg.MsgServerListening, srv.URL()))
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	if err := srv.Serve(ctx); err != nil {
+		log.Fatal(wcg.T(wcg.MsgErrServer, err))
+	}
+	fmt.Println(wcg.T(wcg.MsgServerStopped))
 }
 
 // Execute adds all child commands to the root command and sets flags appropriately.
 // This is called by main.main(). It only needs to happen once to the rootCmd.
 func main() {
-	err := rootCmd.Execute()
+	cmd, err := rootCmd.ExecuteC()
 	if err != nil {
+		if cmd == checkCmd {
+			os.Exit(checkExitError)
+		}
 		os.Exit(1)
 	}
 }
 
+// addCountOptionFlags adds the flags of the counting options to the command
+func addCountOptionFlags(cmd *cobra.Command) {
+	cmd.Flags().BoolVarP(&countOptions.StripMarkdown, "strip-markdown", "", false, "count the text of Markdown files without their syntax and front matter")
+	cmd.Flags().BoolVarP(&countOptions.ExcludePunctuation, "exclude-punctuation", "", false, "leave punctuation out of the character counts")
+	cmd.Flags().BoolVarP(&countOptions.CountWords, "count-words", "", false, "count words, every Chinese character being a word, and add the words column")
+}
+
 func init() {
 	countCmd.Flags().StringVarP(&mode, "mode", "m", "dir", "count from file or directory: dir or file")
-	countCmd.Flags().StringVarP(&exportType, "export", "e", "table", "export type: table, csv, or excel. table is default")
+	countCmd.Flags().StringVarP(&exportType, "export", "e", "table", "export type: table, csv, excel, json or template. table is default")
 	countCmd.Flags().StringVarP(&exportPath, "exportPath", "", "counter.xlsx", "export path only for csv and excel")
 	countCmd.Flags().StringArrayVarP(&excludePattern, "exclude", "", []string{}, "you can specify multiple patterns by call multiple times")
 	countCmd.Flags().BoolVarP(&withTotal, "total", "", false, "enable total count only work for mode=dir")
 	countCmd.Flags().BoolVarP(&relativePath, "relative", "r", false, "show relative paths instead of absolute paths")
+	countCmd.Flags().StringVarP(&templateName, "template", "", wcg.DefaultTemplate,
+		fmt.Sprintf("template file or bundled template (%s), only for export=template", strings.Join(wcg.BundledTemplates(), ", ")))
+
+	countCmd.Flags().StringSliceVarP(&columns, "columns", "", nil,
+		fmt.Sprintf("comma separated columns to report, in order: %s", strings.Join(wcg.AvailableColumns(), ", ")))
+	countCmd.Flags().StringVarP(&sortBy, "sort", "", "", "sort files by a column, only work for mode=dir")
+	countCmd.Flags().BoolVarP(&sortDesc, "desc", "", false, "sort in descending order")
+	countCmd.Flags().IntVarP(&top, "top", "", 0, "only report the first N files after filtering and sorting")
+	countCmd.Flags().StringArrayVarP(&where, "where", "", []string{},
+		"filter files like 'total_chars < 500' or \"file ~ 'ch*.md'\", multiple filters must all match")
+	countCmd.Flags().BoolVarP(&tree, "tree", "", false, "report subtotals per directory as a tree, only work for mode=dir")
+	countCmd.Flags().IntVarP(&depth, "depth", "", 0, "maximum directory depth of --tree, 0 means unlimited")
+	countCmd.Flags().StringVarP(&groupBy, "group-by", "", "",
+		"report totals per group instead of per file: ext, dir or meta:<front matter key>, only work for mode=dir")
+	countCmd.Flags().BoolVarP(&record, "record", "", false, "append the result to the history file, only work for mode=dir")
+	countCmd.Flags().StringVarP(&historyFile, "history-file", "", "", "history file, default is .wcg/history.jsonl in the counted directory")
+	countCmd.Flags().BoolVarP(&watch, "watch", "w", false, "recount changed files until interrupted, only work for mode=dir")
+	countCmd.Flags().BoolVarP(&watchPoll, "poll", "", false, "poll for changes instead of using filesystem notifications, only for --watch")
+	countCmd.Flags().DurationVarP(&watchInterval, "interval", "", wcg.DefaultWatchInterval, "polling interval of --watch, also the delay to batch changes")
+	countCmd.Flags().BoolVarP(&noCache, "no-cache", "", false, "count every file instead of reusing the results of unchanged files from .wcg/cache.json")
+	addCountOptionFlags(countCmd)
+	countCmd.Flags().StringVarP(&gitRev, "rev", "", "",
+		"count a commit, branch or tag of the git repository instead of the working tree, ':' for the staging index, only work for mode=dir")
+
+	historyCmd.Flags().StringVarP(&historyPeriod, "period", "", wcg.HistoryPeriodDay, "group the progress by day or week")
+	historyCmd.Flags().BoolVarP(&historyFiles, "files", "", false, "show the growth of each file instead of each period")
+	historyCmd.Flags().StringVarP(&historyFile, "history-file", "", "", "history file, default is .wcg/history.jsonl in the given directory")
+	historyCmd.Flags().StringVarP(&exportType, "export", "e", "table", "export type: table, csv, excel or json. table is default")
+	historyCmd.Flags().StringVarP(&exportPath, "exportPath", "", "counter.xlsx", "export path only for csv, excel and json")
+	goalCmd.Flags().StringVarP(&goal.Name, "name", "", "", "name of the goal given by flags, default is the directory name")
+	goalCmd.Flags().IntVarP(&goal.Target, "target", "", 0, "add a goal for the whole directory with this target")
+	goalCmd.Flags().StringVarP(&goal.Field, "field", "", wcg.ColumnChineseChars, "field of the --target goal: "+strings.Join(wcg.StatsFields, ", "))
+	goalCmd.Flags().IntVarP(&goal.Minimum, "minimum", "", 0, "minimum of the --target goal, the command fails below it")
+	goalCmd.Flags().StringVarP(&goal.Deadline, "deadline", "", "", "deadline of the --target goal, like 2025-12-31")
+	addCountOptionFlags(goalCmd)
+	goalCmd.Flags().StringArrayVarP(&excludePattern, "exclude", "", []string{}, "you can specify multiple patterns by call multiple times")
+	goalCmd.Flags().StringVarP(&exportType, "export", "e", "table", "export type: table, csv or json. table is default")
+	goalCmd.Flags().StringVarP(&exportPath, "exportPath", "", "counter.xlsx", "export path only for csv and json")
+	checkCmd.Flags().StringArrayVarP(&checkRules, "rule", "", []string{}, "rule like 'ch*.md:chinese_chars>=2000,<=8000', you can specify multiple rules by call multiple times")
+	checkCmd.Flags().BoolVarP(&noCache, "no-cache", "", false, "count every file instead of reusing the results of unchanged files from .wcg/cache.json")
+	addCountOptionFlags(checkCmd)
+	checkCmd.Flags().StringArrayVarP(&excludePattern, "exclude", "", []string{}, "you can specify multiple patterns by call multiple times")
+	checkCmd.Flags().StringVarP(&exportType, "export", "e", "table", "export type: table, csv or json. table is default")
+	checkCmd.Flags().StringVarP(&exportPath, "exportPath", "", "counter.xlsx", "export path only for csv and json")
+	diffCmd.Flags().StringVarP(&gitDir, "git", "", "", "compare two revisions of the git repository containing this directory")
+	diffCmd.Flags().Lookup("git").NoOptDefVal = "."
+	diffCmd.Flags().StringArrayVarP(&excludePattern, "exclude", "", []string{}, "you can specify multiple patterns by call multiple times")
+	diffCmd.Flags().StringVarP(&exportType, "export", "e", "table", "export type: table, csv, excel or json. table is default")
+	diffCmd.Flags().StringVarP(&exportPath, "exportPath", "", "counter.xlsx", "export path only for csv, excel and json")
+	blameCmd.Flags().BoolVarP(&blameMatrix, "matrix", "", false, "show a file by author matrix instead of the author summary")
+	blameCmd.Flags().StringVarP(&blameField, "field", "", wcg.ColumnChineseChars,
+		"field to rank authors and fill the matrix: "+strings.Join(wcg.StatsFields, ", "))
+	blameCmd.Flags().StringVarP(&gitRev, "rev", "", "", "blame a commit, branch or tag instead of the working tree")
+	blameCmd.Flags().StringArrayVarP(&excludePattern, "exclude", "", []string{}, "you can specify multiple patterns by call multiple times")
+	blameCmd.Flags().StringVarP(&exportType, "export", "e", "table", "export type: table, csv, excel or json. table is default")
+	blameCmd.Flags().StringVarP(&exportPath, "exportPath", "", "counter.xlsx", "export path only for csv, excel and json")
+
+	rootCmd.PersistentFlags().StringVarP(&lang, "lang", "", "",
+		fmt.Sprintf("language of headers and messages: %s. detected from LC_ALL, LC_MESSAGES or LANG by default", strings.Join(wcg.SupportedLanguages(), ", ")))
+	rootCmd.PersistentFlags().StringVarP(&configFile, "config", "", "",
+		"configuration file, default is .wcg.yaml or .wcg.toml in the target directory or its nearest parent having one")
+	rootCmd.PersistentFlags().StringVarP(&configProfile, "profile", "", "", "named profile of the configuration file to apply")
 
-	serverCmd.Flags().StringVarP(&host, "host", "", "127.0.0.1", "host")
-	serverCmd.Flags().IntVarP(&port, "port", "p", 8080, "port")
+	serverCmd.Flags().StringVarP(&serverConfig.Host, "host", "", wcg.DefaultHost, "host to listen on, empty for all interfaces")
+	serverCmd.Flags().IntVarP(&serverConfig.Port, "port", "p", wcg.DefaultPort, "port to listen on, 0 picks a free port")
+	serverCmd.Flags().DurationVarP(&serverConfig.ReadTimeout, "read-timeout", "", wcg.DefaultReadTimeout, "maximum duration for reading a request, including the body")
+	serverCmd.Flags().DurationVarP(&serverConfig.WriteTimeout, "write-timeout", "", wcg.DefaultWriteTimeout, "maximum duration for writing a response")
+	serverCmd.Flags().DurationVarP(&serverConfig.IdleTimeout, "idle-timeout", "", wcg.DefaultIdleTimeout, "how long keep-alive connections wait for the next request")
+	serverCmd.Flags().DurationVarP(&serverConfig.ShutdownTimeout, "shutdown-timeout", "", wcg.DefaultShutdownTimeout,
+		"how long to wait for in-flight requests on SIGINT or SIGTERM before closing connections")
+	serverCmd.Flags().IntVarP(&serverConfig.MaxHeaderBytes, "max-header-bytes", "", wcg.DefaultMaxHeaderBytes, "maximum size of request headers")
+	serverCmd.Flags().IntVarP(&serverConfig.MaxBatchItems, "max-batch-items", "", wcg.DefaultMaxBatchItems, "maximum number of documents of a batch request, 0 for no limit")
+	serverCmd.Flags().Int64VarP(&serverConfig.MaxBodySize, "max-body-size", "", wcg.DefaultMaxBodySize, "maximum size of a request body in bytes, 0 for no limit")
+	serverCmd.Flags().BoolVarP(&accessLog, "access-log", "", false, "log every request to stderr as a JSON line")
+	serverCmd.Flags().StringSliceVarP(&serverConfig.APIKeys, "api-key", "", nil, "API key required as a bearer token or X-API-Key header, repeat for more keys")
+	serverCmd.Flags().StringVarP(&apiKeyFile, "api-key-file", "", "", "file of API keys, one per line")
+	serverCmd.Flags().Float64VarP(&serverConfig.IPRateLimit.Rate, "ip-rate-limit", "", 0, "requests per second of every client IP, 0 for no limit")
+	serverCmd.Flags().IntVarP(&serverConfig.IPRateLimit.Burst, "ip-rate-burst", "", 1, "requests of a client IP allowed at once")
+	serverCmd.Flags().Float64VarP(&serverConfig.KeyRateLimit.Rate, "key-rate-limit", "", 0, "requests per second of every API key, 0 for no limit")
+	serverCmd.Flags().IntVarP(&serverConfig.KeyRateLimit.Burst, "key-rate-burst", "", 1, "requests of an API key allowed at once")
+	serverCmd.Flags().StringSliceVarP(&serverConfig.CORSOrigins, "cors-origin", "", nil, "origin allowed to call the API from browsers, e.g. https://editor.example.com, * for any")
+	serverCmd.Flags().BoolVarP(&serverConfig.TrustProxy, "trust-proxy", "", false, "take client IPs from X-Forwarded-For, when behind a reverse proxy")
+	serverCmd.Flags().StringVarP(&serverConfig.Socket, "socket", "", "", "unix domain socket to listen on instead of --host and --port")
+	serverCmd.Flags().StringVarP(&serverConfig.TLSCertFile, "tls-cert", "", "", "certificate file to serve HTTPS, reloaded when it changes")
+	serverCmd.Flags().StringVarP(&serverConfig.TLSKeyFile, "tls-key", "", "", "private key file of --tls-cert")
 
 	rootCmd.AddCommand(countCmd)
 	rootCmd.AddCommand(serverCmd)
+	rootCmd.AddCommand(historyCmd)
+	rootCmd.AddCommand(goalCmd)
+	rootCmd.AddCommand(checkCmd)
+	rootCmd.AddCommand(diffCmd)
+	rootCmd.AddCommand(blameCmd)
+	configCmd.AddCommand(configShowCmd)
+	rootCmd.AddCommand(configCmd)
 }
@@ -1,7 +1,16 @@
 package main
 
 import (
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
 	"testing"
+	"time"
+
+	wcg "github.com/100gle/wordcounter"
+	"github.com/spf13/cobra"
 )
 
 // Test basic functionality without testing log.Fatal calls
@@ -44,3 +53,138 @@ func TestGlobalVariables(t *testing.T) {
 		t.Errorf("Expected excludePattern to be ['*.tmp'], got %v", excludePattern)
 	}
 }
+
+func TestApplyConfig(t *testing.T) {
+	cmd := &cobra.Command{Use: "count"}
+	var (
+		export   string
+		total    bool
+		top      int
+		exclude  []string
+		columns  []string
+		interval time.Duration
+	)
+	cmd.Flags().StringVarP(&export, "export", "e", "table", "")
+	cmd.Flags().BoolVarP(&total, "total", "", false, "")
+	cmd.Flags().IntVarP(&top, "top", "", 0, "")
+	cmd.Flags().StringArrayVarP(&exclude, "exclude", "", []string{}, "")
+	cmd.Flags().StringSliceVarP(&columns, "columns", "", nil, "")
+	cmd.Flags().DurationVarP(&interval, "interval", "", time.Second, "")
+	if err := cmd.Flags().Parse([]string{"-e", "csv"}); err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	err := applyConfig(cmd, wcg.FlagDefaults{
+		"export":   "json",
+		"total":    true,
+		"top":      5,
+		"exclude":  []any{"drafts", "*.tmp"},
+		"columns":  "file",
+		"interval": "2s",
+	})
+	if err != nil {
+		t.Fatalf("applyConfig() error = %v", err)
+	}
+	if export != "csv" {
+		t.Errorf("export = %s, want the flag given on the command line", export)
+	}
+	if !total || top != 5 || interval != 2*time.Second {
+		t.Errorf("total, top, interval = %v, %d, %v", total, top, interval)
+	}
+	if len(exclude) != 2 || exclude[1] != "*.tmp" || len(columns) != 1 || columns[0] != "file" {
+		t.Errorf("exclude, columns = %v, %v", exclude, columns)
+	}
+
+	values := flagValues(cmd)
+	if values["total"] != true || values["top"] != 5 || values["export"] != "csv" || values["interval"] != "2s" {
+		t.Errorf("flagValues() = %v", values)
+	}
+
+	for _, invalid := range []wcg.FlagDefaults{{"missing": 1}, {"top": "many"}} {
+		if err := applyConfig(cmd, invalid); err == nil {
+			t.Errorf("applyConfig(%v) should return error", invalid)
+		}
+	}
+}
+
+func TestApplyCountingConfig(t *testing.T) {
+	withTotal = false
+	defer func() {
+		excludePattern, countOptions = []string{}, wcg.CountOptions{}
+	}()
+
+	err := applyCountingConfig(blameCmd, wcg.FlagDefaults{
+		"exclude":        []any{"drafts"},
+		"strip-markdown": true,
+		"total":          true,
+	})
+	if err != nil {
+		t.Fatalf("applyCountingConfig() error = %v", err)
+	}
+	if len(excludePattern) != 1 || excludePattern[0] != "drafts" {
+		t.Errorf("excludePattern = %v, want [drafts]", excludePattern)
+	}
+	if !countOptions.StripMarkdown {
+		t.Error("countOptions.StripMarkdown = false, want the count section value")
+	}
+	if withTotal {
+		t.Error("withTotal = true, want only the counting flags applied")
+	}
+}
+
+func TestCheckExitCodes(t *testing.T) {
+	// Run by the test itself as a subprocess, as the command exits
+	if args := os.Getenv("WCG_TEST_ARGS"); args != "" {
+		rootCmd.SetArgs(strings.Split(args, "\n"))
+		main()
+		os.Exit(0)
+	}
+
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "ch1.md"), []byte("你好"), 0644); err != nil {
+		t.Fatalf("Failed to write test file: %v", err)
+	}
+	badConfig := filepath.Join(t.TempDir(), ".wcg.yaml")
+	if err := os.WriteFile(badConfig, []byte("count: [unclosed\n"), 0644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+	punctuated := t.TempDir()
+	if err := os.WriteFile(filepath.Join(punctuated, "ch1.md"), []byte("你好，"), 0644); err != nil {
+		t.Fatalf("Failed to write test file: %v", err)
+	}
+	countConfig := filepath.Join(t.TempDir(), ".wcg.yaml")
+	if err := os.WriteFile(countConfig, []byte("count:\n  total: true\n  exclude-punctuation: true\n"), 0644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	tests := []struct {
+		name string
+		args []string
+		want int
+	}{
+		{"pass", []string{"check", dir, "--rule", "*.md:chinese_chars>=1"}, 0},
+		{"violations", []string{"check", dir, "--rule", "*.md:chinese_chars>=100"}, checkExitViolations},
+		{"unsupported language", []string{"check", dir, "--lang", "xx", "--rule", "*.md:chinese_chars>=1"}, checkExitError},
+		{"invalid config", []string{"check", dir, "--config", badConfig, "--rule", "*.md:chinese_chars>=1"}, checkExitError},
+		{"without count config", []string{"check", punctuated, "--rule", "*.md:total_chars<=2"}, checkExitViolations},
+		{"count config", []string{"check", punctuated, "--config", countConfig, "--rule", "*.md:total_chars<=2"}, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd := exec.Command(os.Args[0], "-test.run=^TestCheckExitCodes$")
+			cmd.Env = append(os.Environ(), "WCG_TEST_ARGS="+strings.Join(tt.args, "\n"))
+			err := cmd.Run()
+
+			code := 0
+			var exitErr *exec.ExitError
+			if errors.As(err, &exitErr) {
+				code = exitErr.ExitCode()
+			} else if err != nil {
+				t.Fatalf("Failed to run the command: %v", err)
+			}
+			if code != tt.want {
+				t.Errorf("wcg %s exited with %d, want %d", strings.Join(tt.args, " "), code, tt.want)
+			}
+		})
+	}
+}
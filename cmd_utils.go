@@ -7,8 +7,9 @@ import (
 
 // ExportConfig holds configuration for export operations
 type ExportConfig struct {
-	Type string
-	Path string
+	Type     string
+	Path     string
+	Template string // Template name or path, only used by the template export type
 }
 
 // CounterExporter provides common export functionality for counters
@@ -17,6 +18,8 @@ type CounterExporter struct {
 		ExportCSV(filename ...string) (string, error)
 		ExportExcel(filename ...string) error
 		ExportTable() string
+		ExportTemplate(name string, filename ...string) (string, error)
+		ExportJSON(filename ...string) (string, error)
 	}
 	config ExportConfig
 }
@@ -26,6 +29,8 @@ func NewCounterExporter(counter interface {
 	ExportCSV(filename ...string) (string, error)
 	ExportExcel(filename ...string) error
 	ExportTable() string
+	ExportTemplate(name string, filename ...string) (string, error)
+	ExportJSON(filename ...string) (string, error)
 }, config ExportConfig) *CounterExporter {
 	return &CounterExporter{
 		counter: counter,
@@ -42,6 +47,10 @@ func (ce *CounterExporter) Export() error {
 		return ce.exportExcel()
 	case ExportTypeTable:
 		return ce.exportTable()
+	case ExportTypeTemplate:
+		return ce.exportTemplate()
+	case ExportTypeJSON:
+		return ce.exportJSON()
 	default:
 		return NewInvalidInputError(fmt.Sprintf("unsupported export type: %s", ce.config.Type))
 	}
@@ -71,6 +80,31 @@ func (ce *CounterExporter) exportTable() error {
 	return nil
 }
 
+func (ce *CounterExporter) exportTemplate() error {
+	name := ce.config.Template
+	if name == "" {
+		name = DefaultTemplate
+	}
+
+	output, err := ce.counter.ExportTemplate(name, ce.config.Path)
+	if err != nil {
+		return NewExportError("template export", err)
+	}
+
+	fmt.Println(output)
+	return nil
+}
+
+func (ce *CounterExporter) exportJSON() error {
+	jsonData, err := ce.counter.ExportJSON(ce.config.Path)
+	if err != nil {
+		return NewExportError("JSON export", err)
+	}
+
+	fmt.Println(jsonData)
+	return nil
+}
+
 // ValidatePath validates if a path exists
 func ValidatePath(path string) error {
 	if path == "" {
@@ -87,11 +121,11 @@ func ValidatePath(path string) error {
 // ValidateExportType validates if an export type is supported
 func ValidateExportType(exportType string) error {
 	switch exportType {
-	case ExportTypeTable, ExportTypeCSV, ExportTypeExcel:
+	case ExportTypeTable, ExportTypeCSV, ExportTypeExcel, ExportTypeTemplate, ExportTypeJSON:
 		return nil
 	default:
-		return NewInvalidInputError(fmt.Sprintf("unsupported export type: %s, supported types: %s, %s, %s",
-			exportType, ExportTypeTable, ExportTypeCSV, ExportTypeExcel))
+		return NewInvalidInputError(fmt.Sprintf("unsupported export type: %s, supported types: %s, %s, %s, %s, %s",
+			exportType, ExportTypeTable, ExportTypeCSV, ExportTypeExcel, ExportTypeTemplate, ExportTypeJSON))
 	}
 }
 
@@ -69,6 +69,16 @@ func TestValidateExportType(t *testing.T) {
 			exportType: "excel",
 			wantErr:    false,
 		},
+		{
+			name:       "Valid template type",
+			exportType: "template",
+			wantErr:    false,
+		},
+		{
+			name:       "Valid json type",
+			exportType: "json",
+			wantErr:    false,
+		},
 		{
 			name:       "Invalid type",
 			exportType: "invalid",
@@ -158,6 +168,16 @@ func TestCounterExporter_Export(t *testing.T) {
 			exportType: "excel",
 			wantErr:    false,
 		},
+		{
+			name:       "Export template",
+			exportType: "template",
+			wantErr:    false,
+		},
+		{
+			name:       "Export JSON",
+			exportType: "json",
+			wantErr:    false,
+		},
 		{
 			name:       "Invalid export type",
 			exportType: "invalid",
@@ -188,7 +208,7 @@ func TestCounterExporter_Export(t *testing.T) {
 			}
 
 			// Clean up created files
-			if !tt.wantErr && (tt.exportType == "excel" || tt.exportType == "csv") {
+			if !tt.wantErr && (tt.exportType == "excel" || tt.exportType == "csv" || tt.exportType == "template" || tt.exportType == "json") {
 				if _, err := os.Stat(outputPath); err == nil {
 					os.Remove(outputPath)
 				}
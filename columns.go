@@ -0,0 +1,194 @@
+package wordcounter
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+)
+
+// Column keys
+const (
+	ColumnFile            = "file"
+	ColumnLines           = "lines"
+	ColumnChineseChars    = "chinese_chars"
+	ColumnNonChineseChars = "non_chinese_chars"
+	ColumnTotalChars      = "total_chars"
+	ColumnChineseRatio    = "chinese_ratio"
+	ColumnSize            = "size"
+	ColumnModified        = "modified"
+	ColumnPercent         = "percent"
+	ColumnReadingTime     = "reading_time"
+	ColumnWords           = "words"
+)
+
+// Reading speeds used to estimate the reading time, in characters per minute
+const (
+	ReadingSpeedChinese    = 400
+	ReadingSpeedNonChinese = 1000
+)
+
+// ModifiedTimeLayout is the layout of the modified column
+const ModifiedTimeLayout = "2006-01-02 15:04:05"
+
+// DefaultColumns are the columns reported when no columns are selected
+var DefaultColumns = []string{
+	ColumnFile,
+	ColumnLines,
+	ColumnChineseChars,
+	ColumnNonChineseChars,
+	ColumnTotalChars,
+}
+
+// columnInput holds everything a column value can be derived from.
+// The total row uses the summed stats and size and the latest modified time.
+type columnInput struct {
+	path       string
+	stats      Stats
+	size       int64
+	modTime    time.Time
+	grandTotal int // total characters of all reported files
+}
+
+type column struct {
+	header MessageKey
+	value  func(in *columnInput) any
+}
+
+var columnRegistry = map[string]column{
+	ColumnFile: {
+		header: MsgHeaderFile,
+		value:  func(in *columnInput) any { return in.path },
+	},
+	ColumnLines: {
+		header: MsgHeaderLines,
+		value:  func(in *columnInput) any { return in.stats.Lines },
+	},
+	ColumnChineseChars: {
+		header: MsgHeaderChineseChars,
+		value:  func(in *columnInput) any { return in.stats.ChineseChars },
+	},
+	ColumnNonChineseChars: {
+		header: MsgHeaderNonChineseChars,
+		value:  func(in *columnInput) any { return in.stats.NonChineseChars },
+	},
+	ColumnTotalChars: {
+		header: MsgHeaderTotalChars,
+		value:  func(in *columnInput) any { return in.stats.TotalChars },
+	},
+	ColumnChineseRatio: {
+		header: MsgHeaderChineseRatio,
+		value: func(in *columnInput) any {
+			return roundTo(ratio(in.stats.ChineseChars, in.stats.TotalChars), 4)
+		},
+	},
+	ColumnSize: {
+		header: MsgHeaderSize,
+		value:  func(in *columnInput) any { return in.size },
+	},
+	ColumnModified: {
+		header: MsgHeaderModified,
+		value: func(in *columnInput) any {
+			if in.modTime.IsZero() {
+				return ""
+			}
+			return in.modTime.Format(ModifiedTimeLayout)
+		},
+	},
+	ColumnPercent: {
+		header: MsgHeaderShare,
+		value: func(in *columnInput) any {
+			return roundTo(ratio(in.stats.TotalChars, in.grandTotal)*100, 2)
+		},
+	},
+	ColumnReadingTime: {
+		header: MsgHeaderReadingTime,
+		value:  func(in *columnInput) any { return roundTo(readingMinutes(&in.stats), 2) },
+	},
+	ColumnWords: {
+		header: MsgHeaderWords,
+		value:  func(in *columnInput) any { return in.stats.Words },
+	},
+}
+
+// AvailableColumns returns all column keys that can be selected
+func AvailableColumns() []string {
+	return []string{
+		ColumnFile,
+		ColumnLines,
+		ColumnChineseChars,
+		ColumnNonChineseChars,
+		ColumnTotalChars,
+		ColumnChineseRatio,
+		ColumnSize,
+		ColumnModified,
+		ColumnPercent,
+		ColumnReadingTime,
+		ColumnWords,
+	}
+}
+
+// ParseColumns parses a comma separated list of column keys such as "file,lines,chinese_ratio"
+func ParseColumns(spec string) ([]string, error) {
+	var keys []string
+	for _, key := range strings.Split(spec, ",") {
+		key = strings.TrimSpace(key)
+		if key != "" {
+			keys = append(keys, key)
+		}
+	}
+	if err := ValidateColumns(keys); err != nil {
+		return nil, err
+	}
+	return keys, nil
+}
+
+// ValidateColumns validates that all keys are known columns and that at least one is given
+func ValidateColumns(keys []string) error {
+	if len(keys) == 0 {
+		return NewInvalidInputError("at least one column is required")
+	}
+	for _, key := range keys {
+		if _, ok := columnRegistry[key]; !ok {
+			return NewInvalidInputError(fmt.Sprintf("unsupported column: %s, supported columns: %s",
+				key, strings.Join(AvailableColumns(), ", "))).WithContext("column", key)
+		}
+	}
+	return nil
+}
+
+// columnHeader returns the header row for the given columns in the current language
+func columnHeader(keys []string) Row {
+	if len(keys) == 0 {
+		keys = DefaultColumns
+	}
+
+	header := make(Row, 0, len(keys))
+	for _, key := range keys {
+		header = append(header, T(columnRegistry[key].header))
+	}
+	return header
+}
+
+// columnRow returns the values of the given columns for one input
+func columnRow(keys []string, in *columnInput) Row {
+	if len(keys) == 0 {
+		keys = DefaultColumns
+	}
+
+	row := make(Row, 0, len(keys))
+	for _, key := range keys {
+		row = append(row, columnRegistry[key].value(in))
+	}
+	return row
+}
+
+// readingMinutes estimates the reading time in minutes
+func readingMinutes(s *Stats) float64 {
+	return float64(s.ChineseChars)/ReadingSpeedChinese + float64(s.NonChineseChars)/ReadingSpeedNonChinese
+}
+
+func roundTo(value float64, decimals int) float64 {
+	pow := math.Pow(10, float64(decimals))
+	return math.Round(value*pow) / pow
+}
@@ -0,0 +1,130 @@
+package wordcounter_test
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	wcg "github.com/100gle/wordcounter"
+)
+
+func TestParseColumns(t *testing.T) {
+	tests := []struct {
+		name    string
+		spec    string
+		want    []string
+		wantErr bool
+	}{
+		{
+			name: "Single column",
+			spec: "file",
+			want: []string{"file"},
+		},
+		{
+			name: "Columns with spaces",
+			spec: "file, chinese_ratio ,reading_time",
+			want: []string{"file", "chinese_ratio", "reading_time"},
+		},
+		{
+			name:    "Unknown column",
+			spec:    "file,unknown",
+			wantErr: true,
+		},
+		{
+			name:    "Empty spec",
+			spec:    " , ",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := wcg.ParseColumns(tt.spec)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseColumns() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseColumns() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDirCounter_SetColumns(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"a.md": "你好世界",
+		"b.md": "你好, world",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write test file: %v", err)
+		}
+	}
+	modTime := time.Date(2024, 1, 2, 3, 4, 5, 0, time.Local)
+	if err := os.Chtimes(filepath.Join(dir, "b.md"), modTime, modTime); err != nil {
+		t.Fatalf("Failed to set modified time: %v", err)
+	}
+
+	dc := wcg.NewDirCounterWithPathMode(dir, wcg.PathDisplayRelative)
+	dc.EnableTotal()
+	if err := dc.SetColumns("total_chars", "file", "unknown"); err == nil {
+		t.Errorf("DirCounter.SetColumns() expected error for unknown column")
+	}
+	if err := dc.SetColumns("file", "total_chars", "chinese_ratio", "size", "percent", "reading_time"); err != nil {
+		t.Fatalf("DirCounter.SetColumns() error = %v", err)
+	}
+	if err := dc.Count(); err != nil {
+		t.Fatalf("DirCounter.Count() error = %v", err)
+	}
+
+	wantHeader := wcg.Row{"File", "TotalChars", "ChineseRatio", "Size", "Share", "ReadingMinutes"}
+	if got := dc.GetHeader(); !reflect.DeepEqual(got, wantHeader) {
+		t.Errorf("DirCounter.GetHeader() = %v, want %v", got, wantHeader)
+	}
+
+	wantRows := []wcg.Row{
+		{"a.md", 4, 1.0, int64(12), 30.77, 0.01},
+		{"b.md", 9, 0.2222, int64(13), 69.23, 0.01},
+		{"Total", 13, 0.4615, int64(25), 100.0, 0.02},
+	}
+	if got := dc.GetRows(); !reflect.DeepEqual(got, wantRows) {
+		t.Errorf("DirCounter.GetRows() = %v, want %v", got, wantRows)
+	}
+
+	if err := dc.SetColumns("modified"); err != nil {
+		t.Fatalf("DirCounter.SetColumns() error = %v", err)
+	}
+	rows := dc.GetRows()
+	if got := rows[1][0]; got != modTime.Format(wcg.ModifiedTimeLayout) {
+		t.Errorf("DirCounter.GetRows() modified = %v, want %v", got, modTime.Format(wcg.ModifiedTimeLayout))
+	}
+	if got := rows[2][0]; got != rows[0][0] && got != rows[1][0] {
+		t.Errorf("DirCounter.GetRows() total modified = %v, want the latest modified time", got)
+	}
+}
+
+func TestFileCounter_SetColumns(t *testing.T) {
+	fc := wcg.NewFileCounterWithPathMode("testdata/foo.md", wcg.PathDisplayRelative)
+	if err := fc.SetColumns(); err == nil {
+		t.Errorf("FileCounter.SetColumns() expected error for empty columns")
+	}
+	if !reflect.DeepEqual(fc.Columns(), wcg.DefaultColumns) {
+		t.Errorf("FileCounter.Columns() = %v, want %v", fc.Columns(), wcg.DefaultColumns)
+	}
+	if err := fc.SetColumns("chinese_chars", "file"); err != nil {
+		t.Fatalf("FileCounter.SetColumns() error = %v", err)
+	}
+	if err := fc.Count(); err != nil {
+		t.Fatalf("FileCounter.Count() error = %v", err)
+	}
+
+	want := []wcg.Row{{"ChineseChars", "File"}, {12, "testdata/foo.md"}}
+	if got := append([]wcg.Row{fc.GetHeader()}, fc.GetRows()...); !reflect.DeepEqual(got, want) {
+		t.Errorf("FileCounter header and rows = %v, want %v", got, want)
+	}
+	if fc.Size() == 0 || fc.ModTime().IsZero() {
+		t.Errorf("FileCounter.Size() = %d, ModTime() = %v, want both set after Count", fc.Size(), fc.ModTime())
+	}
+}
@@ -0,0 +1,167 @@
+package wordcounter
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// FlagDefaults are default values of command line flags, keyed by flag name such as "total" or "exportPath"
+type FlagDefaults map[string]any
+
+// ProjectConfig is the project configuration file, see ConfigFileName
+type ProjectConfig struct {
+	Count    FlagDefaults              `yaml:"count,omitempty" toml:"count,omitempty" json:"count,omitempty"`    // Defaults of wcg count flags
+	Server   FlagDefaults              `yaml:"server,omitempty" toml:"server,omitempty" json:"server,omitempty"` // Defaults of wcg server flags
+	Profiles map[string]*ProjectConfig `yaml:"profiles,omitempty" toml:"profiles,omitempty" json:"profiles,omitempty"`
+	Goals    []Goal                    `yaml:"goals,omitempty" toml:"goals,omitempty" json:"goals,omitempty"`
+	Check    []CheckRule               `yaml:"check,omitempty" toml:"check,omitempty" json:"check,omitempty"`
+}
+
+// ConfigFilePath returns the configuration file of a directory
+func ConfigFilePath(dirname string) string {
+	return filepath.Join(ToAbsolutePath(dirname), ConfigFileName)
+}
+
+// FindConfigFile looks for the configuration file, ConfigFileName or ConfigTOMLName,
+// in the directory of path and its parents. Returns an empty string if there is none.
+func FindConfigFile(path string) string {
+	dir := ToAbsolutePath(path)
+	if info, err := os.Stat(dir); err == nil && !info.IsDir() {
+		dir = filepath.Dir(dir)
+	}
+
+	for {
+		for _, name := range []string{ConfigFileName, ConfigTOMLName} {
+			candidate := filepath.Join(dir, name)
+			if info, err := os.Stat(candidate); err == nil && !info.IsDir() {
+				return candidate
+			}
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+}
+
+// LoadProjectConfig reads a project configuration, as TOML if the file ends with .toml and YAML otherwise.
+// A missing file, or an empty path, is not an error and yields an empty configuration.
+func LoadProjectConfig(path string) (*ProjectConfig, error) {
+	config := &ProjectConfig{}
+	if path == "" {
+		return config, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return config, nil
+		}
+		return nil, NewFileReadError(path, err)
+	}
+
+	if strings.EqualFold(filepath.Ext(path), ".toml") {
+		err = toml.Unmarshal(data, config)
+	} else {
+		err = yaml.Unmarshal(data, config)
+	}
+	if err != nil {
+		return nil, NewInvalidInputError("invalid configuration file").WithContext("path", path).WithContext("cause", err.Error())
+	}
+	if err := config.validate(); err != nil {
+		return nil, err
+	}
+	for name, profile := range config.Profiles {
+		if profile == nil {
+			continue
+		}
+		if len(profile.Profiles) > 0 {
+			return nil, NewInvalidInputError(fmt.Sprintf("profile %q cannot define profiles", name)).WithContext("path", path)
+		}
+		if err := profile.validate(); err != nil {
+			return nil, err
+		}
+	}
+	return config, nil
+}
+
+// validate checks the goals and check rules
+func (c *ProjectConfig) validate() error {
+	for i := range c.Goals {
+		if err := c.Goals[i].Validate(); err != nil {
+			return err
+		}
+	}
+	for i := range c.Check {
+		if err := c.Check[i].Validate(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ProfileNames returns the names of the profiles in alphabetical order
+func (c *ProjectConfig) ProfileNames() []string {
+	names := make([]string, 0, len(c.Profiles))
+	for name := range c.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// WithProfile returns the configuration with the named profile applied: its flag defaults
+// override those of the configuration, and its goals and check rules replace them if given.
+// An empty name returns the configuration without profiles.
+func (c *ProjectConfig) WithProfile(name string) (*ProjectConfig, error) {
+	merged := &ProjectConfig{
+		Count:  mergeFlagDefaults(c.Count, nil),
+		Server: mergeFlagDefaults(c.Server, nil),
+		Goals:  c.Goals,
+		Check:  c.Check,
+	}
+	if name == "" {
+		return merged, nil
+	}
+
+	profile, found := c.Profiles[name]
+	if !found {
+		return nil, NewInvalidInputError(fmt.Sprintf("unknown profile: %s, available profiles: %s",
+			name, strings.Join(c.ProfileNames(), ", "))).WithContext("profile", name)
+	}
+	if profile == nil {
+		return merged, nil
+	}
+
+	merged.Count = mergeFlagDefaults(merged.Count, profile.Count)
+	merged.Server = mergeFlagDefaults(merged.Server, profile.Server)
+	if profile.Goals != nil {
+		merged.Goals = profile.Goals
+	}
+	if profile.Check != nil {
+		merged.Check = profile.Check
+	}
+	return merged, nil
+}
+
+// mergeFlagDefaults copies base and overrides its values with those of override
+func mergeFlagDefaults(base, override FlagDefaults) FlagDefaults {
+	if base == nil && override == nil {
+		return nil
+	}
+	merged := FlagDefaults{}
+	for name, value := range base {
+		merged[name] = value
+	}
+	for name, value := range override {
+		merged[name] = value
+	}
+	return merged
+}
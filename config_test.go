@@ -0,0 +1,194 @@
+package wordcounter_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	wcg "github.com/100gle/wordcounter"
+)
+
+func TestLoadProjectConfig(t *testing.T) {
+	dir := t.TempDir()
+	path := wcg.ConfigFilePath(dir)
+	if path != filepath.Join(dir, ".wcg.yaml") {
+		t.Errorf("ConfigFilePath() = %s", path)
+	}
+
+	config, err := wcg.LoadProjectConfig(path)
+	if err != nil || len(config.Goals) != 0 {
+		t.Fatalf("LoadProjectConfig() of missing file = %+v, %v, want empty config", config, err)
+	}
+
+	content := "goals:\n  - name: book\n    target: 80000\n    deadline: 2030-12-31\n  - name: part1\n    path: part1/*\n    field: total_chars\n    target: 20000\n    minimum: 5000\ncheck:\n  - path: ch*.md\n    min: 2000\n"
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+	config, err = wcg.LoadProjectConfig(path)
+	if err != nil {
+		t.Fatalf("LoadProjectConfig() error = %v", err)
+	}
+	want := []wcg.Goal{
+		{Name: "book", Target: 80000, Deadline: "2030-12-31"},
+		{Name: "part1", Path: "part1/*", Field: "total_chars", Target: 20000, Minimum: 5000},
+	}
+	if len(config.Goals) != len(want) {
+		t.Fatalf("LoadProjectConfig() goals = %+v, want %+v", config.Goals, want)
+	}
+	for i := range want {
+		if config.Goals[i] != want[i] {
+			t.Errorf("LoadProjectConfig() goal %d = %+v, want %+v", i, config.Goals[i], want[i])
+		}
+	}
+
+	if len(config.Check) != 1 || config.Check[0] != (wcg.CheckRule{Path: "ch*.md", Min: 2000}) {
+		t.Errorf("LoadProjectConfig() check = %+v", config.Check)
+	}
+
+	for _, invalid := range []string{"goals: [", "goals:\n  - name: book\n", "check:\n  - path: ch*.md\n"} {
+		if err := os.WriteFile(path, []byte(invalid), 0644); err != nil {
+			t.Fatalf("Failed to write config: %v", err)
+		}
+		if _, err := wcg.LoadProjectConfig(path); err == nil {
+			t.Errorf("LoadProjectConfig(%q) should return error", invalid)
+		}
+	}
+}
+
+func TestFindConfigFile(t *testing.T) {
+	root := t.TempDir()
+	nested := filepath.Join(root, "book", "part1")
+	if err := os.MkdirAll(nested, 0755); err != nil {
+		t.Fatalf("Failed to create directory: %v", err)
+	}
+	chapter := filepath.Join(nested, "ch01.md")
+	if err := os.WriteFile(chapter, []byte("第一章"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+
+	if got := wcg.FindConfigFile(nested); strings.HasPrefix(got, root) {
+		t.Fatalf("FindConfigFile() = %s before any config is written", got)
+	}
+
+	rootConfig := filepath.Join(root, ".wcg.yaml")
+	if err := os.WriteFile(rootConfig, []byte("count:\n  total: true\n"), 0644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+	bookConfig := filepath.Join(root, "book", ".wcg.toml")
+	if err := os.WriteFile(bookConfig, []byte("[count]\nrelative = true\n\n[[goals]]\nname = \"book\"\ntarget = 1000\n\n[[check]]\npath = \"*.md\"\nmax = 500\n"), 0644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	tests := []struct {
+		name string
+		path string
+		want string
+	}{
+		{"nearest parent", nested, bookConfig},
+		{"file", chapter, bookConfig},
+		{"own directory", filepath.Join(root, "book"), bookConfig},
+		{"root", root, rootConfig},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := wcg.FindConfigFile(tt.path); got != tt.want {
+				t.Errorf("FindConfigFile(%s) = %s, want %s", tt.path, got, tt.want)
+			}
+		})
+	}
+
+	config, err := wcg.LoadProjectConfig(bookConfig)
+	if err != nil {
+		t.Fatalf("LoadProjectConfig() of TOML error = %v", err)
+	}
+	if config.Count["relative"] != true {
+		t.Errorf("LoadProjectConfig() of TOML count = %v", config.Count)
+	}
+	if len(config.Goals) != 1 || config.Goals[0].Name != "book" || config.Goals[0].Target != 1000 {
+		t.Errorf("LoadProjectConfig() of TOML goals = %+v", config.Goals)
+	}
+	if len(config.Check) != 1 || config.Check[0].Path != "*.md" || config.Check[0].Max != 500 {
+		t.Errorf("LoadProjectConfig() of TOML check = %+v", config.Check)
+	}
+}
+
+func TestProjectConfig_WithProfile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".wcg.yaml")
+	content := `count:
+  total: true
+  export: csv
+server:
+  port: 9090
+goals:
+  - name: book
+    target: 80000
+check:
+  - path: ch*.md
+    min: 2000
+profiles:
+  blog:
+    count:
+      export: json
+      exclude: [drafts]
+    check:
+      - max: 3000
+  book:
+`
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+	config, err := wcg.LoadProjectConfig(path)
+	if err != nil {
+		t.Fatalf("LoadProjectConfig() error = %v", err)
+	}
+	if names := config.ProfileNames(); len(names) != 2 || names[0] != "blog" || names[1] != "book" {
+		t.Errorf("ProfileNames() = %v", names)
+	}
+
+	base, err := config.WithProfile("")
+	if err != nil {
+		t.Fatalf("WithProfile(\"\") error = %v", err)
+	}
+	if base.Count["export"] != "csv" || base.Server["port"] != 9090 || len(base.Profiles) != 0 {
+		t.Errorf("WithProfile(\"\") = %+v", base)
+	}
+
+	blog, err := config.WithProfile("blog")
+	if err != nil {
+		t.Fatalf("WithProfile(blog) error = %v", err)
+	}
+	if blog.Count["export"] != "json" || blog.Count["total"] != true || blog.Count["exclude"] == nil {
+		t.Errorf("WithProfile(blog) count = %v", blog.Count)
+	}
+	if len(blog.Check) != 1 || blog.Check[0].Max != 3000 {
+		t.Errorf("WithProfile(blog) check = %+v, want the rules of the profile", blog.Check)
+	}
+	if len(blog.Goals) != 1 || blog.Goals[0].Name != "book" {
+		t.Errorf("WithProfile(blog) goals = %+v, want the goals of the configuration", blog.Goals)
+	}
+	if config.Count["export"] != "csv" {
+		t.Errorf("WithProfile() modified the configuration: %v", config.Count)
+	}
+
+	book, err := config.WithProfile("book")
+	if err != nil || book.Count["export"] != "csv" {
+		t.Errorf("WithProfile(book) = %+v, %v, want the configuration unchanged", book, err)
+	}
+
+	if _, err := config.WithProfile("missing"); err == nil {
+		t.Error("WithProfile(missing) should return error")
+	}
+
+	for _, invalid := range []string{
+		"profiles:\n  blog:\n    check:\n      - path: ch*.md\n",
+		"profiles:\n  blog:\n    profiles:\n      draft: {}\n",
+	} {
+		if err := os.WriteFile(path, []byte(invalid), 0644); err != nil {
+			t.Fatalf("Failed to write config: %v", err)
+		}
+		if _, err := wcg.LoadProjectConfig(path); err == nil {
+			t.Errorf("LoadProjectConfig(%q) should return error", invalid)
+		}
+	}
+}
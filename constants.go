@@ -1,10 +1,14 @@
 package wordcounter
 
+import "time"
+
 // Export types
 const (
-	ExportTypeTable = "table"
-	ExportTypeCSV   = "csv"
-	ExportTypeExcel = "excel"
+	ExportTypeTable    = "table"
+	ExportTypeCSV      = "csv"
+	ExportTypeExcel    = "excel"
+	ExportTypeTemplate = "template"
+	ExportTypeJSON     = "json"
 )
 
 // Mode types
@@ -26,20 +30,43 @@ const (
 	DefaultPort       = 8080
 	DefaultMode       = ModeDir
 	DefaultExportType = ExportTypeTable
+	DefaultTemplate   = "markdown"
+)
+
+// Server defaults
+const (
+	DefaultReadTimeout     = 30 * time.Second
+	DefaultWriteTimeout    = 30 * time.Second
+	DefaultIdleTimeout     = 2 * time.Minute
+	DefaultShutdownTimeout = 10 * time.Second
+	DefaultMaxHeaderBytes  = 1 << 20
+	DefaultMaxBatchItems   = 1000
+	DefaultMaxBodySize     = 32 << 20
 )
 
 // Server configuration
 const (
-	ServerAppName = "WordCounter"
-	APIVersion    = "v1"
-	APIBasePath   = "/" + APIVersion + "/wordcounter"
-	PingEndpoint  = APIBasePath + "/ping"
-	CountEndpoint = APIBasePath + "/count"
+	ServerAppName   = "WordCounter"
+	APIVersion      = "v1"
+	APIBasePath     = "/" + APIVersion + "/wordcounter"
+	PingEndpoint    = APIBasePath + "/ping"
+	CountEndpoint   = APIBasePath + "/count"
+	UploadEndpoint  = APIBasePath + "/upload"
+	BatchEndpoint   = APIBasePath + "/batch"
+	OpenAPIEndpoint = APIBasePath + "/openapi.json"
+	DocsEndpoint    = APIBasePath + "/docs"
+	MetricsEndpoint = "/metrics"
 )
 
 // File patterns
 const (
 	IgnoreFileName = ".wcignore"
+	// DataDirName is the directory below the counted directory where wordcounter keeps its data
+	DataDirName     = ".wcg"
+	HistoryFileName = "history.jsonl"
+	CacheFileName   = "cache.json"
+	ConfigFileName  = ".wcg.yaml"
+	ConfigTOMLName  = ".wcg.toml"
 )
 
 // Worker pool configuration
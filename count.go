@@ -32,6 +32,8 @@ package wordcounter
 
 import (
 	"fmt"
+	"io"
+	"unicode"
 	"unicode/utf8"
 )
 
@@ -40,7 +42,8 @@ import (
 // including lines, Chinese characters, non-Chinese characters, and total characters.
 // Stats is embedded to allow direct access to statistical fields.
 type Counter struct {
-	*Stats // Embedded statistics for direct field access
+	*Stats  // Embedded statistics for direct field access
+	options CountOptions
 }
 
 // NewCounter creates a new Counter instance with initialized statistics.
@@ -49,6 +52,21 @@ func NewCounter() *Counter {
 	return &Counter{Stats: &Stats{}}
 }
 
+// NewCounterWithOptions creates a Counter that counts with the given options
+func NewCounterWithOptions(options CountOptions) *Counter {
+	return &Counter{Stats: &Stats{}, options: options}
+}
+
+// SetOptions changes the options of the following counts
+func (c *Counter) SetOptions(options CountOptions) {
+	c.options = options
+}
+
+// Options returns the counting options
+func (c *Counter) Options() CountOptions {
+	return c.options
+}
+
 // GetStats returns the counting statistics for backward compatibility
 func (c *Counter) GetStats() *Stats {
 	return c.Stats
@@ -119,18 +137,114 @@ func isChinese(r rune) bool {
 //   - Local variables to reduce struct field access overhead
 //
 // Empty data is handled gracefully and returns zero counts for all statistics.
+// The options of the counter may strip Markdown first, skip punctuation and count words.
 func (c *Counter) CountBytes(data []byte) error {
+	if c.options.StripMarkdown {
+		data = stripMarkdown(data)
+	}
+
+	text := c.newTextCounter()
+	text.write(data)
+	text.close()
+	return nil
+}
 
+// CountReader counts the text read from r in chunks, without reading it all into memory,
+// like CountBytes counts the whole text. The statistics include the text read before an error.
+func (c *Counter) CountReader(r io.Reader) error {
+	text := c.newTextCounter()
+	defer text.close()
+
+	if c.options.StripMarkdown {
+		return stripMarkdownReader(r, text.write)
+	}
+	return readRunes(r, text.write)
+}
+
+// readChunkSize is the size of the chunks read by CountReader
+const readChunkSize = 32 * 1024
+
+// readRunes reads r in chunks and writes them, each ending on a rune boundary
+func readRunes(r io.Reader, write func([]byte)) error {
+	buf := make([]byte, readChunkSize)
+	pending := 0
+	for {
+		n, err := r.Read(buf[pending:])
+		data := buf[:pending+n]
+		cut := len(data)
+		if err == nil {
+			cut = runeBoundary(data)
+		}
+		write(data[:cut])
+		pending = copy(buf, data[cut:])
+
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// runeBoundary returns the length of data without a trailing incomplete rune
+func runeBoundary(data []byte) int {
+	for i := len(data) - 1; i >= 0 && i >= len(data)-utf8.UTFMax; i-- {
+		if utf8.RuneStart(data[i]) {
+			if utf8.FullRune(data[i:]) {
+				return len(data)
+			}
+			return i
+		}
+	}
+	return len(data)
+}
+
+// textCounter adds the statistics of text given in pieces to a Counter
+type textCounter struct {
+	*Counter
+	inWord   bool // The last piece ended in a word
+	nonEmpty bool
+}
+
+// newTextCounter starts counting a text
+func (c *Counter) newTextCounter() *textCounter {
+	return &textCounter{Counter: c}
+}
+
+// write counts a piece of the text, which must end on a rune boundary
+func (t *textCounter) write(data []byte) {
 	// Use local variables to minimize struct field access overhead
 	lines := 0
 	chineseChars := 0
 	nonChineseChars := 0
+	words := 0
+	inWord := t.inWord
 
 	// Single-pass processing: count lines and characters simultaneously
 	for i := 0; i < len(data); {
 		r, size := utf8.DecodeRune(data[i:])
+		if t.options.CountWords {
+			// A word is a Chinese character or a run of other letters and digits, apostrophes included
+			switch {
+			case unicode.IsLetter(r) || unicode.IsDigit(r):
+				if isChinese(r) {
+					words++
+					inWord = false
+				} else if !inWord {
+					words++
+					inWord = true
+				}
+			case inWord && (r == '\'' || r == '’'):
+			default:
+				inWord = false
+			}
+		}
+
 		if r == '\n' {
 			lines++
+		} else if t.options.ExcludePunctuation && unicode.IsPunct(r) {
+			// Left out of the character counts
 		} else {
 			// Count non-newline characters
 			if isChinese(r) {
@@ -142,17 +256,20 @@ func (c *Counter) CountBytes(data []byte) error {
 		i += size
 	}
 
-	// Line counting logic: number of newlines + 1 (if there's any content)
-	// This correctly handles cases like "line1\nline2\nline3" (2 newlines = 3 lines)
-	if len(data) > 0 {
-		lines++ // Add 1 for the content itself
-	}
-
 	// Update statistics in batch to minimize memory writes
-	c.Lines += lines
-	c.ChineseChars += chineseChars
-	c.NonChineseChars += nonChineseChars
-	c.TotalChars += chineseChars + nonChineseChars
+	t.Lines += lines
+	t.ChineseChars += chineseChars
+	t.NonChineseChars += nonChineseChars
+	t.TotalChars += chineseChars + nonChineseChars
+	t.Words += words
+	t.inWord = inWord
+	t.nonEmpty = t.nonEmpty || len(data) > 0
+}
 
-	return nil
+// close ends the text. Line counting logic: number of newlines + 1 (if there's any content)
+// This correctly handles cases like "line1\nline2\nline3" (2 newlines = 3 lines)
+func (t *textCounter) close() {
+	if t.nonEmpty {
+		t.Lines++ // Add 1 for the content itself
+	}
 }
@@ -1,8 +1,12 @@
 package wordcounter_test
 
 import (
+	"errors"
+	"io"
+	"runtime"
 	"strings"
 	"testing"
+	"testing/iotest"
 
 	"github.com/100gle/wordcounter"
 )
@@ -448,3 +452,86 @@ func BenchmarkIsChinese(b *testing.B) {
 		tc.CountBytes(data)
 	}
 }
+
+func TestCounter_CountReader(t *testing.T) {
+	markdown := "---\ntitle: 测试\n---\n# 标题\n\n引用**加粗** <!-- 跨\n行注释 -->尾巴\n\n```\n代码\n```\n"
+	tests := []struct {
+		name    string
+		content string
+		options wordcounter.CountOptions
+	}{
+		{"empty", "", wordcounter.CountOptions{}},
+		{"multibyte", strings.Repeat("你好，world!\n", 5000), wordcounter.CountOptions{CountWords: true}},
+		{"no trailing newline", "Don't panic\n你好", wordcounter.CountOptions{CountWords: true, ExcludePunctuation: true}},
+		{"markdown", markdown, wordcounter.CountOptions{StripMarkdown: true}},
+		{"unterminated front matter", "---\ntitle: 测试\n正文", wordcounter.CountOptions{StripMarkdown: true}},
+		{"markdown with BOM", "\xef\xbb\xbf---\na: b\n---\n正文\n", wordcounter.CountOptions{StripMarkdown: true}},
+		{"long markdown line", "# " + strings.Repeat("**粗体**和[链接](a.md)", 20000), wordcounter.CountOptions{StripMarkdown: true}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			want := wordcounter.NewCounterWithOptions(tt.options)
+			if err := want.CountBytes([]byte(tt.content)); err != nil {
+				t.Fatalf("Counter.CountBytes() error = %v", err)
+			}
+
+			// One byte at a time splits every multibyte character across reads
+			got := wordcounter.NewCounterWithOptions(tt.options)
+			if err := got.CountReader(iotest.OneByteReader(strings.NewReader(tt.content))); err != nil {
+				t.Fatalf("Counter.CountReader() error = %v", err)
+			}
+			if *got.Stats != *want.Stats {
+				t.Errorf("Counter.CountReader() = %+v, want %+v as CountBytes", *got.Stats, *want.Stats)
+			}
+		})
+	}
+
+	readErr := errors.New("read failed")
+	for _, options := range []wordcounter.CountOptions{{}, {StripMarkdown: true}} {
+		tc := wordcounter.NewCounterWithOptions(options)
+		if err := tc.CountReader(iotest.ErrReader(readErr)); !errors.Is(err, readErr) {
+			t.Errorf("Counter.CountReader(%+v) error = %v, want %v", options, err, readErr)
+		}
+	}
+}
+
+// lineReader reads a single line of n bytes of Chinese characters, recording the peak heap while read
+type lineReader struct {
+	n, read  int
+	peakHeap uint64
+}
+
+func (r *lineReader) Read(p []byte) (int, error) {
+	if r.read >= r.n {
+		return 0, io.EOF
+	}
+	if r.read%(1<<20) < len(p) {
+		var stats runtime.MemStats
+		runtime.ReadMemStats(&stats)
+		r.peakHeap = max(r.peakHeap, stats.HeapAlloc)
+	}
+	n := min(len(p), r.n-r.read)
+	for i := range p[:n] {
+		p[i] = "中"[(r.read+i)%3]
+	}
+	r.read += n
+	return n, nil
+}
+
+func TestCounter_CountReaderLongLine(t *testing.T) {
+	const size = 30 << 20 // Whole characters, about MaxBodySize
+	// The first piece starts with a heading and ends in the middle of a character
+	counter := wordcounter.NewCounterWithOptions(wordcounter.CountOptions{StripMarkdown: true})
+	reader := &lineReader{n: size}
+	if err := counter.CountReader(io.MultiReader(strings.NewReader("# "), reader)); err != nil {
+		t.Fatalf("Counter.CountReader() error = %v", err)
+	}
+	if want := (wordcounter.Stats{Lines: 1, ChineseChars: size / 3, TotalChars: size / 3}); *counter.Stats != want {
+		t.Errorf("Counter.CountReader() = %+v, want %+v", *counter.Stats, want)
+	}
+	// Markdown stripping holds a piece of the line in memory, not the line
+	if reader.peakHeap > size/2 {
+		t.Errorf("Counter.CountReader() of a %d MiB line used %d MiB of heap", size>>20, reader.peakHeap>>20)
+	}
+}
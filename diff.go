@@ -0,0 +1,224 @@
+package wordcounter
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Statuses of a file in a diff
+const (
+	DiffAdded    = "added"
+	DiffRemoved  = "removed"
+	DiffModified = "modified"
+)
+
+// Snapshot is the stats of every file of a counted tree
+type Snapshot struct {
+	Root  string           `json:"root"`
+	Files map[string]Stats `json:"files"` // Keyed by slash separated path relative to Root
+	Total Stats            `json:"total"`
+}
+
+// NewSnapshot takes the stats of all files counted by the DirCounter
+func NewSnapshot(dc *DirCounter) *Snapshot {
+	root := ToAbsolutePath(dc.dirname)
+	snapshot := &Snapshot{Root: root, Files: make(map[string]Stats, len(dc.fileCounters))}
+
+	for _, fc := range dc.fileCounters {
+		relPath, err := filepath.Rel(root, fc.FileName)
+		if err != nil {
+			relPath = fc.FileName
+		}
+		snapshot.add(filepath.ToSlash(relPath), fc.Stats)
+	}
+	return snapshot
+}
+
+// add records the stats of a file
+func (s *Snapshot) add(path string, stats *Stats) {
+	s.Files[path] = *stats
+	s.Total.add(stats)
+}
+
+// LoadJSONSnapshot reads a report written by the JSON exporter of DirCounter.
+// The report must include the file column. Absolute paths are made relative to
+// their common directory, so reports of different checkouts can be compared.
+func LoadJSONSnapshot(path string) (*Snapshot, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, NewFileNotFoundError(path, err)
+		}
+		return nil, NewFileReadError(path, err)
+	}
+
+	var report JSONReport
+	if err := json.Unmarshal(data, &report); err != nil {
+		return nil, NewInvalidInputError("invalid JSON report").WithContext("path", path).WithContext("cause", err.Error())
+	}
+
+	files := make(map[string]Stats, len(report.Files))
+	for _, object := range report.Files {
+		name, ok := object[ColumnFile].(string)
+		if !ok {
+			return nil, NewInvalidInputError("JSON report must include the file column").WithContext("path", path)
+		}
+		stats := Stats{}
+		for _, field := range StatsFields {
+			value, _ := object[field].(float64)
+			stats.set(field, int(value))
+		}
+		files[filepath.ToSlash(name)] = stats
+	}
+
+	root := commonDir(files)
+	snapshot := &Snapshot{Root: root, Files: make(map[string]Stats, len(files))}
+	for name, stats := range files {
+		if root != "" {
+			name = strings.TrimPrefix(name, root+"/")
+		}
+		snapshot.add(name, &stats)
+	}
+	return snapshot, nil
+}
+
+// commonDir returns the deepest directory containing all paths if they are all absolute, or ""
+func commonDir(files map[string]Stats) string {
+	var dir string
+	first := true
+	for name := range files {
+		if !filepath.IsAbs(filepath.FromSlash(name)) {
+			return ""
+		}
+		parent := filepath.ToSlash(filepath.Dir(filepath.FromSlash(name)))
+		if first {
+			dir, first = parent, false
+			continue
+		}
+		for dir != parent && !strings.HasPrefix(parent, strings.TrimSuffix(dir, "/")+"/") {
+			next := filepath.ToSlash(filepath.Dir(filepath.FromSlash(dir)))
+			if next == dir {
+				break
+			}
+			dir = next
+		}
+	}
+	return strings.TrimSuffix(dir, "/")
+}
+
+// GitSnapshot counts the files of a revision below dir, a directory of a local git repository.
+// Files and directories matching the ignore patterns are skipped.
+func GitSnapshot(dir, rev string, ignores ...string) (*Snapshot, error) {
+	return GitSnapshotWithOptions(dir, rev, CountOptions{}, ignores...)
+}
+
+// GitSnapshotWithOptions is like GitSnapshot, counting the files with the given options
+func GitSnapshotWithOptions(dir, rev string, options CountOptions, ignores ...string) (*Snapshot, error) {
+	dc := NewDirCounter(dir, ignores...)
+	dc.SetOptions(options)
+	dc.SetGitRevision(rev)
+	if err := dc.Count(); err != nil {
+		return nil, err
+	}
+
+	snapshot := NewSnapshot(dc)
+	snapshot.Root += "@" + rev
+	return snapshot, nil
+}
+
+// FileDiff is the change of a file between two snapshots
+type FileDiff struct {
+	File   string `json:"file"`
+	Status string `json:"status"` // DiffAdded, DiffRemoved or DiffModified
+	Old    Stats  `json:"old"`
+	New    Stats  `json:"new"`
+	Delta  Stats  `json:"delta"`
+}
+
+// DiffReport lists the files whose stats changed between two snapshots
+type DiffReport struct {
+	Old   string     `json:"old"`
+	New   string     `json:"new"`
+	Files []FileDiff `json:"files"`
+	Total FileDiff   `json:"total"`
+}
+
+// DiffSnapshots compares two snapshots. Files with the same stats are left out.
+func DiffSnapshots(oldSnapshot, newSnapshot *Snapshot) *DiffReport {
+	report := &DiffReport{
+		Old:   oldSnapshot.Root,
+		New:   newSnapshot.Root,
+		Files: []FileDiff{},
+		Total: FileDiff{
+			Old:   oldSnapshot.Total,
+			New:   newSnapshot.Total,
+			Delta: newSnapshot.Total.sub(&oldSnapshot.Total),
+		},
+	}
+
+	for name, newStats := range newSnapshot.Files {
+		oldStats, found := oldSnapshot.Files[name]
+		switch {
+		case !found:
+			report.Files = append(report.Files, FileDiff{File: name, Status: DiffAdded, New: newStats, Delta: newStats})
+		case oldStats != newStats:
+			report.Files = append(report.Files, FileDiff{File: name, Status: DiffModified, Old: oldStats, New: newStats, Delta: newStats.sub(&oldStats)})
+		}
+	}
+	for name, oldStats := range oldSnapshot.Files {
+		if _, found := newSnapshot.Files[name]; !found {
+			report.Files = append(report.Files, FileDiff{
+				File: name, Status: DiffRemoved, Old: oldStats, Delta: (&Stats{}).sub(&oldStats),
+			})
+		}
+	}
+
+	sort.Slice(report.Files, func(i, j int) bool {
+		return report.Files[i].File < report.Files[j].File
+	})
+	return report
+}
+
+// diffStatusKeys maps the statuses to their messages
+var diffStatusKeys = map[string]MessageKey{
+	DiffAdded:    MsgDiffAdded,
+	DiffRemoved:  MsgDiffRemoved,
+	DiffModified: MsgDiffModified,
+}
+
+// headerAndRows returns the delta of every Stats field per file and the total
+func (r *DiffReport) headerAndRows() []Row {
+	header := Row{T(MsgHeaderFile), T(MsgHeaderStatus)}
+	header = append(header, (&Stats{}).Header()...)
+	data := []Row{header}
+
+	for _, file := range r.Files {
+		row := Row{file.File, T(diffStatusKeys[file.Status])}
+		data = append(data, append(row, file.Delta.ToRow()...))
+	}
+	total := Row{T(MsgTotal), ""}
+	return append(data, append(total, r.Total.Delta.ToRow()...))
+}
+
+// ExportTable renders the deltas as a table
+func (r *DiffReport) ExportTable() string {
+	return exportToTable(r.headerAndRows())
+}
+
+// ExportCSV exports the deltas as CSV
+func (r *DiffReport) ExportCSV(filename ...string) (string, error) {
+	return exportToCSV(r.headerAndRows(), filename...)
+}
+
+// ExportExcel exports the deltas to an Excel file
+func (r *DiffReport) ExportExcel(filename ...string) error {
+	return exportToExcel(r.headerAndRows(), filename...)
+}
+
+// ExportJSON exports the whole report, including old and new stats, as JSON
+func (r *DiffReport) ExportJSON(filename ...string) (string, error) {
+	return exportToJSON(r, filename...)
+}
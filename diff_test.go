@@ -0,0 +1,239 @@
+package wordcounter_test
+
+import (
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	wcg "github.com/100gle/wordcounter"
+)
+
+// countSnapshot counts a directory and takes its snapshot
+func countSnapshot(t *testing.T, dir string, ignores ...string) *wcg.Snapshot {
+	t.Helper()
+
+	dc := wcg.NewDirCounter(dir, ignores...)
+	if err := dc.Count(); err != nil {
+		t.Fatalf("DirCounter.Count() error = %v", err)
+	}
+	return wcg.NewSnapshot(dc)
+}
+
+// runGit runs a git command in dir, skipping the test if git is not installed
+func runGit(t *testing.T, dir string, args ...string) {
+	t.Helper()
+
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	args = append([]string{"-C", dir, "-c", "user.name=test", "-c", "user.email=test@example.com"}, args...)
+	if out, err := exec.Command("git", args...).CombinedOutput(); err != nil {
+		t.Fatalf("git %v failed: %v\n%s", args, err, out)
+	}
+}
+
+// createGitBook creates a git repository from createBookDir with two commits tagged v1 and v2.
+// v2 extends intro.md, removes part2/ch03.md and adds part2/ch04.md.
+func createGitBook(t *testing.T) string {
+	t.Helper()
+
+	dir := createBookDir(t)
+	runGit(t, dir, "init", "-q")
+	runGit(t, dir, "add", ".")
+	runGit(t, dir, "commit", "-q", "-m", "v1")
+	runGit(t, dir, "tag", "v1")
+
+	if err := os.WriteFile(filepath.Join(dir, "intro.md"), []byte("前言\n新的一段"), 0644); err != nil {
+		t.Fatalf("Failed to write test file: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "part2", "ch04.md"), []byte("第四章"), 0644); err != nil {
+		t.Fatalf("Failed to write test file: %v", err)
+	}
+	runGit(t, dir, "rm", "-q", "part2/ch03.md")
+	runGit(t, dir, "add", ".")
+	runGit(t, dir, "commit", "-q", "-m", "v2")
+	runGit(t, dir, "tag", "v2")
+	return dir
+}
+
+// wantBookDiff is the diff between v1 and v2 of createGitBook
+var wantBookDiff = []wcg.FileDiff{
+	{
+		File: "intro.md", Status: wcg.DiffModified,
+		Old:   wcg.Stats{Lines: 1, ChineseChars: 2, TotalChars: 2},
+		New:   wcg.Stats{Lines: 2, ChineseChars: 6, TotalChars: 6},
+		Delta: wcg.Stats{Lines: 1, ChineseChars: 4, TotalChars: 4},
+	},
+	{
+		File: "part2/ch03.md", Status: wcg.DiffRemoved,
+		Old:   wcg.Stats{Lines: 1, ChineseChars: 3, NonChineseChars: 4, TotalChars: 7},
+		Delta: wcg.Stats{Lines: -1, ChineseChars: -3, NonChineseChars: -4, TotalChars: -7},
+	},
+	{
+		File: "part2/ch04.md", Status: wcg.DiffAdded,
+		New:   wcg.Stats{Lines: 1, ChineseChars: 3, TotalChars: 3},
+		Delta: wcg.Stats{Lines: 1, ChineseChars: 3, TotalChars: 3},
+	},
+}
+
+func assertFileDiffs(t *testing.T, got, want []wcg.FileDiff) {
+	t.Helper()
+
+	if len(got) != len(want) {
+		t.Fatalf("DiffReport.Files = %+v, want %+v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("DiffReport.Files[%d] = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestDiffSnapshots(t *testing.T) {
+	oldDir := createBookDir(t)
+	oldSnapshot := countSnapshot(t, oldDir)
+
+	newDir := createGitBook(t)
+	newSnapshot := countSnapshot(t, newDir, ".git")
+
+	report := wcg.DiffSnapshots(oldSnapshot, newSnapshot)
+	assertFileDiffs(t, report.Files, wantBookDiff)
+	if report.Old != oldDir || report.New != newDir {
+		t.Errorf("DiffReport sources = %s, %s, want %s, %s", report.Old, report.New, oldDir, newDir)
+	}
+
+	same := wcg.DiffSnapshots(oldSnapshot, oldSnapshot)
+	if len(same.Files) != 0 || same.Total.Delta != (wcg.Stats{}) {
+		t.Errorf("DiffSnapshots() of the same snapshot = %+v, want no changes", same)
+	}
+}
+
+func TestGitSnapshot(t *testing.T) {
+	dir := createGitBook(t)
+
+	oldSnapshot, err := wcg.GitSnapshot(dir, "v1")
+	if err != nil {
+		t.Fatalf("GitSnapshot() error = %v", err)
+	}
+	newSnapshot, err := wcg.GitSnapshot(dir, "v2")
+	if err != nil {
+		t.Fatalf("GitSnapshot() error = %v", err)
+	}
+	if len(oldSnapshot.Files) != 5 || oldSnapshot.Total.ChineseChars != 14 {
+		t.Errorf("GitSnapshot(v1) = %+v, want 5 files with 14 Chinese chars", oldSnapshot)
+	}
+
+	report := wcg.DiffSnapshots(oldSnapshot, newSnapshot)
+	assertFileDiffs(t, report.Files, wantBookDiff)
+	wantTotal := wcg.Stats{Lines: 1, ChineseChars: 4, NonChineseChars: -4, TotalChars: 0}
+	if report.Total.Delta != wantTotal {
+		t.Errorf("DiffReport.Total.Delta = %+v, want %+v", report.Total.Delta, wantTotal)
+	}
+
+	// Ignored directories and files are skipped, and subdirectories list their own files
+	ignored, err := wcg.GitSnapshot(dir, "v1", "part1", "*.txt")
+	if err != nil {
+		t.Fatalf("GitSnapshot() with ignores error = %v", err)
+	}
+	if len(ignored.Files) != 2 {
+		t.Errorf("GitSnapshot() with ignores files = %v, want intro.md and part2/ch03.md", ignored.Files)
+	}
+	sub, err := wcg.GitSnapshot(filepath.Join(dir, "part1"), "v1")
+	if err != nil {
+		t.Fatalf("GitSnapshot() of a subdirectory error = %v", err)
+	}
+	if _, found := sub.Files["sec/ch02.md"]; !found || len(sub.Files) != 2 {
+		t.Errorf("GitSnapshot() of a subdirectory files = %v", sub.Files)
+	}
+
+	if _, err := wcg.GitSnapshot(dir, "missing"); err == nil {
+		t.Error("GitSnapshot() with unknown revision should return error")
+	}
+}
+
+func TestLoadJSONSnapshot(t *testing.T) {
+	dir := createBookDir(t)
+	out := t.TempDir()
+
+	for _, mode := range []string{wcg.PathDisplayAbsolute, wcg.PathDisplayRelative} {
+		t.Run(mode, func(t *testing.T) {
+			dc := wcg.NewDirCounterWithPathMode(dir, mode)
+			dc.EnableTotal()
+			if err := dc.Count(); err != nil {
+				t.Fatalf("DirCounter.Count() error = %v", err)
+			}
+			path := filepath.Join(out, mode+".json")
+			if _, err := dc.ExportJSON(path); err != nil {
+				t.Fatalf("DirCounter.ExportJSON() error = %v", err)
+			}
+
+			snapshot, err := wcg.LoadJSONSnapshot(path)
+			if err != nil {
+				t.Fatalf("LoadJSONSnapshot() error = %v", err)
+			}
+			want := wcg.NewSnapshot(dc)
+			if len(snapshot.Files) != len(want.Files) || snapshot.Total != want.Total {
+				t.Fatalf("LoadJSONSnapshot() = %+v, want %+v", snapshot, want)
+			}
+			for name, stats := range want.Files {
+				if snapshot.Files[name] != stats {
+					t.Errorf("LoadJSONSnapshot() file %s = %+v, want %+v", name, snapshot.Files[name], stats)
+				}
+			}
+		})
+	}
+
+	invalid := filepath.Join(out, "invalid.json")
+	for _, content := range []string{"{", `{"files": [{"lines": 1}]}`} {
+		if err := os.WriteFile(invalid, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write test file: %v", err)
+		}
+		if _, err := wcg.LoadJSONSnapshot(invalid); err == nil {
+			t.Errorf("LoadJSONSnapshot(%q) should return error", content)
+		}
+	}
+	if _, err := wcg.LoadJSONSnapshot(filepath.Join(out, "missing.json")); err == nil {
+		t.Error("LoadJSONSnapshot() of missing file should return error")
+	}
+}
+
+func TestDiffReport_Export(t *testing.T) {
+	report := &wcg.DiffReport{Old: "v1", New: "v2", Files: wantBookDiff}
+	report.Total.Delta = wcg.Stats{Lines: 1, ChineseChars: 4, NonChineseChars: -4}
+
+	table := report.ExportTable()
+	for _, want := range []string{"STATUS", "intro.md", "modified", "removed", "added", "Total"} {
+		if !strings.Contains(table, want) {
+			t.Errorf("DiffReport.ExportTable() missing %q:\n%s", want, table)
+		}
+	}
+
+	csvData, err := report.ExportCSV()
+	if err != nil {
+		t.Fatalf("DiffReport.ExportCSV() error = %v", err)
+	}
+	if !strings.Contains(csvData, "part2/ch03.md,removed,-1,-3,-4,-7") || !strings.Contains(csvData, "Total,,1,4,-4,0") {
+		t.Errorf("DiffReport.ExportCSV() = %s", csvData)
+	}
+
+	excelPath := filepath.Join(t.TempDir(), "diff.xlsx")
+	if err := report.ExportExcel(excelPath); err != nil {
+		t.Fatalf("DiffReport.ExportExcel() error = %v", err)
+	}
+	if _, err := os.Stat(excelPath); err != nil {
+		t.Errorf("DiffReport.ExportExcel() did not create the file: %v", err)
+	}
+
+	jsonData, err := report.ExportJSON()
+	if err != nil {
+		t.Fatalf("DiffReport.ExportJSON() error = %v", err)
+	}
+	var decoded wcg.DiffReport
+	if err := json.Unmarshal([]byte(jsonData), &decoded); err != nil {
+		t.Fatalf("DiffReport.ExportJSON() returned invalid JSON: %v", err)
+	}
+	assertFileDiffs(t, decoded.Files, wantBookDiff)
+}
@@ -1,9 +1,11 @@
 package wordcounter
 
 import (
+	"errors"
 	"os"
 	"path/filepath"
 	"runtime"
+	"sort"
 	"strings"
 	"sync"
 )
@@ -14,6 +16,11 @@ type DirCounter struct {
 	fileCounters    []*FileCounter
 	withTotal       bool
 	pathDisplayMode string
+	columns         []string
+	query           *Query
+	git             *gitSource
+	cache           *Cache
+	options         CountOptions
 }
 
 func NewDirCounter(dirname string, ignores ...string) *DirCounter {
@@ -40,6 +47,74 @@ func (dc *DirCounter) GetFileCounters() []*FileCounter {
 	return dc.fileCounters
 }
 
+// SetOptions sets the counting options of the files counted from now on
+func (dc *DirCounter) SetOptions(options CountOptions) {
+	dc.options = options
+}
+
+// Options returns the counting options
+func (dc *DirCounter) Options() CountOptions {
+	return dc.options
+}
+
+// SetColumns selects and orders the columns of GetHeader and GetRows.
+// See AvailableColumns for the supported keys.
+func (dc *DirCounter) SetColumns(keys ...string) error {
+	if err := ValidateColumns(keys); err != nil {
+		return err
+	}
+	dc.columns = keys
+	return nil
+}
+
+// Columns returns the selected columns, DefaultColumns if none were selected
+func (dc *DirCounter) Columns() []string {
+	if len(dc.columns) == 0 {
+		return DefaultColumns
+	}
+	return dc.columns
+}
+
+// SetQuery sets the query that filters, sorts and limits the reported files.
+// It applies to GetRows, the exporters and FilteredFileCounters; pass nil to report all files.
+func (dc *DirCounter) SetQuery(q *Query) error {
+	if q != nil {
+		if err := q.Validate(); err != nil {
+			return err
+		}
+	}
+	dc.query = q
+	return nil
+}
+
+// FilteredFileCounters returns the counted files selected by the query, in report order.
+// Without a query this is the same as GetFileCounters.
+func (dc *DirCounter) FilteredFileCounters() []*FileCounter {
+	if dc.query == nil {
+		return dc.fileCounters
+	}
+	return dc.query.Apply(dc.fileCounters)
+}
+
+// SetGitRevision makes Count read the files from a commit, branch or tag of the git repository
+// containing the directory instead of the working tree. GitIndex selects the staging index and
+// an empty revision the working tree again. Ignore patterns apply as usual.
+func (dc *DirCounter) SetGitRevision(rev string) {
+	if rev == "" {
+		dc.git = nil
+		return
+	}
+	dc.git = &gitSource{rev: rev}
+}
+
+// GitRevision returns the revision set with SetGitRevision, empty for the working tree
+func (dc *DirCounter) GitRevision() string {
+	if dc.git == nil {
+		return ""
+	}
+	return dc.git.rev
+}
+
 // GetIgnoreList returns the current ignore patterns.
 // This allows inspection of the configured ignore patterns.
 func (dc *DirCounter) GetIgnoreList() []string {
@@ -49,6 +124,14 @@ func (dc *DirCounter) GetIgnoreList() []string {
 func (dc *DirCounter) Count() error {
 	absPath := ToAbsolutePath(dc.dirname)
 
+	if dc.git != nil {
+		filePaths, err := dc.git.list(dc, absPath)
+		if err != nil {
+			return err
+		}
+		return dc.processFilesConcurrently(filePaths)
+	}
+
 	// First pass: collect all files to process
 	var filePaths []string
 	err := filepath.Walk(absPath, func(path string, info os.FileInfo, err error) error {
@@ -77,9 +160,117 @@ func (dc *DirCounter) Count() error {
 	return dc.processFilesConcurrently(filePaths)
 }
 
-// processFilesConcurrently processes files using a worker pool pattern while preserving order
-func (dc *DirCounter) processFilesConcurrently(filePaths []string) error {
-	// Determine optimal number of workers
+// Refresh recounts files below the directory after they changed on disk, without walking the whole tree.
+// Paths of new files are added, paths that no longer exist or are ignored are removed, and a directory
+// path refreshes every file below it. Refresh only works for the working tree.
+func (dc *DirCounter) Refresh(paths ...string) error {
+	if dc.git != nil {
+		return NewInvalidInputError("refresh is not supported for git revisions")
+	}
+
+	root := ToAbsolutePath(dc.dirname)
+	for _, path := range paths {
+		path = ToAbsolutePath(path)
+		relPath, err := filepath.Rel(root, path)
+		if err != nil || relPath == "." || relPath == ".." || strings.HasPrefix(relPath, ".."+string(filepath.Separator)) {
+			// Not below the directory
+			continue
+		}
+		if err := dc.refreshPath(path, filepath.ToSlash(relPath)); err != nil {
+			return err
+		}
+	}
+
+	sort.Slice(dc.fileCounters, func(i, j int) bool {
+		return lessPath(dc.fileCounters[i].FileName, dc.fileCounters[j].FileName)
+	})
+	return nil
+}
+
+// refreshPath recounts a file or the files of a directory, or removes them
+func (dc *DirCounter) refreshPath(path, relPath string) error {
+	info, err := os.Stat(path)
+	if err != nil || dc.isIgnoredRelPath(relPath) {
+		dc.removeFiles(path)
+		return nil
+	}
+
+	if info.IsDir() {
+		dc.removeFiles(path)
+		var filePaths []string
+		err := filepath.Walk(path, func(p string, info os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+			if dc.IsIgnored(p) {
+				if info.IsDir() {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			if !info.IsDir() {
+				filePaths = append(filePaths, p)
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		fileCounters := dc.fileCounters
+		if err := dc.processFilesConcurrently(filePaths); err != nil {
+			dc.fileCounters = fileCounters
+			return err
+		}
+		dc.fileCounters = append(fileCounters, dc.fileCounters...)
+		return nil
+	}
+
+	fc := dc.newFileCounter(path)
+	if err := dc.countFile(fc); err != nil {
+		var wcErr *WordCounterError
+		if errors.As(err, &wcErr) && wcErr.Type == ErrorTypeFileNotFound {
+			// Removed while refreshing
+			dc.removeFiles(path)
+			return nil
+		}
+		return err
+	}
+	for i, existing := range dc.fileCounters {
+		if existing.FileName == path {
+			dc.fileCounters[i] = fc
+			return nil
+		}
+	}
+	dc.fileCounters = append(dc.fileCounters, fc)
+	return nil
+}
+
+// removeFiles drops the file or the files below the directory path
+func (dc *DirCounter) removeFiles(path string) {
+	prefix := path + string(filepath.Separator)
+	kept := dc.fileCounters[:0]
+	for _, fc := range dc.fileCounters {
+		if fc.FileName != path && !strings.HasPrefix(fc.FileName, prefix) {
+			kept = append(kept, fc)
+		}
+	}
+	dc.fileCounters = kept
+}
+
+// lessPath orders paths like filepath.Walk, comparing them element by element
+func lessPath(a, b string) bool {
+	as := strings.Split(a, string(filepath.Separator))
+	bs := strings.Split(b, string(filepath.Separator))
+	for i := 0; i < len(as) && i < len(bs); i++ {
+		if as[i] != bs[i] {
+			return as[i] < bs[i]
+		}
+	}
+	return len(as) < len(bs)
+}
+
+// workerCount determines the optimal number of workers for the number of jobs
+func workerCount(jobs int) int {
 	numWorkers := runtime.NumCPU()
 	if numWorkers < MinWorkers {
 		numWorkers = MinWorkers
@@ -87,9 +278,15 @@ func (dc *DirCounter) processFilesConcurrently(filePaths []string) error {
 	if numWorkers > MaxWorkers {
 		numWorkers = MaxWorkers
 	}
-	if len(filePaths) < numWorkers {
-		numWorkers = len(filePaths)
+	if jobs < numWorkers {
+		numWorkers = jobs
 	}
+	return numWorkers
+}
+
+// processFilesConcurrently processes files using a worker pool pattern while preserving order
+func (dc *DirCounter) processFilesConcurrently(filePaths []string) error {
+	numWorkers := workerCount(len(filePaths))
 
 	// Create a job structure that includes index to preserve order
 	type job struct {
@@ -114,26 +311,8 @@ func (dc *DirCounter) processFilesConcurrently(filePaths []string) error {
 		go func() {
 			defer wg.Done()
 			for j := range jobs {
-				var originalPath string
-				if dc.pathDisplayMode == PathDisplayRelative {
-					// Calculate relative path from the directory being scanned
-					relPath, err := filepath.Rel(ToAbsolutePath(dc.dirname), j.filePath)
-					if err != nil {
-						originalPath = filepath.Base(j.filePath) // fallback to basename
-					} else {
-						originalPath = relPath
-					}
-				} else {
-					originalPath = j.filePath
-				}
-
-				fc := &FileCounter{
-					Counter:         NewCounter(),
-					FileName:        j.filePath,
-					originalPath:    originalPath,
-					pathDisplayMode: dc.pathDisplayMode,
-				}
-				err := fc.Count()
+				fc := dc.newFileCounter(j.filePath)
+				err := dc.countFile(fc)
 				results <- result{index: j.index, fc: fc, err: err}
 			}
 		}()
@@ -172,6 +351,43 @@ func (dc *DirCounter) processFilesConcurrently(filePaths []string) error {
 	return nil
 }
 
+// newFileCounter creates the FileCounter of a file below the directory, displayed in the path mode of dc
+func (dc *DirCounter) newFileCounter(filePath string) *FileCounter {
+	var originalPath string
+	if dc.pathDisplayMode == PathDisplayRelative {
+		// Calculate relative path from the directory being scanned
+		relPath, err := filepath.Rel(ToAbsolutePath(dc.dirname), filePath)
+		if err != nil {
+			originalPath = filepath.Base(filePath) // fallback to basename
+		} else {
+			originalPath = relPath
+		}
+	} else {
+		originalPath = filePath
+	}
+
+	return &FileCounter{
+		Counter:         NewCounterWithOptions(dc.options),
+		FileName:        filePath,
+		originalPath:    originalPath,
+		pathDisplayMode: dc.pathDisplayMode,
+	}
+}
+
+// countFile counts a file from the working tree, the cache or the git revision
+func (dc *DirCounter) countFile(fc *FileCounter) error {
+	root := ToAbsolutePath(dc.dirname)
+	if dc.git != nil {
+		return dc.git.count(fc, root)
+	}
+	if dc.cache != nil {
+		if relPath, err := filepath.Rel(root, fc.FileName); err == nil {
+			return dc.cache.count(fc, filepath.ToSlash(relPath))
+		}
+	}
+	return fc.Count()
+}
+
 func (dc *DirCounter) IsIgnored(filename string) bool {
 	for _, pattern := range dc.ignoreList {
 		if strings.HasPrefix(pattern, "/") {
@@ -193,6 +409,19 @@ func (dc *DirCounter) IsIgnored(filename string) bool {
 	return false
 }
 
+// isIgnoredRelPath reports whether a slash separated path relative to the counted directory,
+// or one of its parent directories, is ignored
+func (dc *DirCounter) isIgnoredRelPath(relPath string) bool {
+	path := ToAbsolutePath(dc.dirname)
+	for _, part := range strings.Split(relPath, "/") {
+		path = filepath.Join(path, part)
+		if dc.IsIgnored(path) {
+			return true
+		}
+	}
+	return false
+}
+
 // IsIgnoredWithError checks if a file should be ignored and returns any pattern matching errors
 func (dc *DirCounter) IsIgnoredWithError(filename string) (bool, error) {
 	for _, pattern := range dc.ignoreList {
@@ -225,32 +454,42 @@ func (dc *DirCounter) Ignore(pattern string) {
 
 // GetHeader returns the header row (implements Counter interface)
 func (dc *DirCounter) GetHeader() Row {
-	if len(dc.fileCounters) == 0 {
-		return Row{"File", "Lines", "ChineseChars", "NonChineseChars", "TotalChars"}
-	}
-	return dc.fileCounters[0].GetHeader()
+	return columnHeader(dc.columns)
 }
 
 func (dc *DirCounter) GetRows() []Row {
-	data := make([]Row, 0, len(dc.fileCounters))
+	fcs := dc.FilteredFileCounters()
+	data := make([]Row, 0, len(fcs)+1)
 
-	for _, fc := range dc.fileCounters {
-		row := fc.GetRow()
+	grandTotal := dc.grandTotal()
+	for _, fc := range fcs {
+		row := columnRow(dc.columns, fc.columnInput(grandTotal))
 		data = append(data, row)
 	}
 
 	if dc.withTotal {
-		data = append(data, getTotal(dc.fileCounters))
+		data = append(data, getTotal(fcs, dc.columns, grandTotal))
 	}
 
 	return data
 }
 
+// grandTotal returns the characters of all counted files, which percentages are relative to,
+// not only of the selected ones
+func (dc *DirCounter) grandTotal() int {
+	total := 0
+	for _, fc := range dc.fileCounters {
+		total += fc.TotalChars
+	}
+	return total
+}
+
 func (dc *DirCounter) GetHeaderAndRows() []Row {
-	data := make([]Row, 0, len(dc.fileCounters))
-	header := dc.fileCounters[0].GetHeader()
+	header := dc.GetHeader()
+	rows := dc.GetRows()
+	data := make([]Row, 0, len(rows)+1)
 	data = append(data, header)
-	data = append(data, dc.GetRows()...)
+	data = append(data, rows...)
 
 	return data
 }
@@ -265,7 +504,26 @@ func (dc *DirCounter) ExportExcel(filename ...string) error {
 	return exportToExcel(data, filename...)
 }
 
+// ExportJSON exports the report as a JSONReport, with the total if enabled
+func (dc *DirCounter) ExportJSON(filename ...string) (string, error) {
+	rows := dc.GetRows()
+	var total Row
+	if dc.withTotal {
+		rows, total = rows[:len(rows)-1], rows[len(rows)-1]
+	}
+	return exportToJSON(newJSONReport(dc.Columns(), rows, total), filename...)
+}
+
 func (dc *DirCounter) ExportTable() string {
 	data := dc.GetHeaderAndRows()
 	return exportToTable(data)
 }
+
+// ExportTemplate renders the counting results through the named template.
+// See RenderTemplate for how the name is resolved.
+func (dc *DirCounter) ExportTemplate(name string, filename ...string) (string, error) {
+	fcs := dc.FilteredFileCounters()
+	data := NewTemplateData(ToAbsolutePath(dc.dirname), ModeDir, fcs)
+	data.setReport(dc.Columns(), fcs, dc.grandTotal())
+	return exportToTemplate(data, name, filename...)
+}
@@ -1,6 +1,7 @@
 package wordcounter_test
 
 import (
+	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -670,3 +671,100 @@ func TestDirCounter_ProcessFilesConcurrentlyRelativePathError(t *testing.T) {
 		t.Errorf("Expected to find relative paths in output")
 	}
 }
+
+func TestDirCounter_ExportJSON(t *testing.T) {
+	dc := wcg.NewDirCounterWithPathMode("testdata", wcg.PathDisplayRelative)
+	dc.EnableTotal()
+	if err := dc.Count(); err != nil {
+		t.Fatalf("DirCounter.Count() error = %v", err)
+	}
+
+	jsonData, err := dc.ExportJSON()
+	if err != nil {
+		t.Fatalf("DirCounter.ExportJSON() error = %v", err)
+	}
+
+	var report wcg.JSONReport
+	if err := json.Unmarshal([]byte(jsonData), &report); err != nil {
+		t.Fatalf("Failed to parse JSON report: %v", err)
+	}
+	if !reflect.DeepEqual(report.Columns, wcg.DefaultColumns) {
+		t.Errorf("JSONReport.Columns = %v, want %v", report.Columns, wcg.DefaultColumns)
+	}
+	if len(report.Files) != len(dc.GetFileCounters()) {
+		t.Errorf("JSONReport.Files has %d entries, want %d", len(report.Files), len(dc.GetFileCounters()))
+	}
+	if report.Total == nil || report.Total["file"] != "Total" {
+		t.Errorf("JSONReport.Total = %v, want the total row", report.Total)
+	}
+	for _, file := range report.Files {
+		if file["file"] == "foo.md" && file["chinese_chars"] != float64(12) {
+			t.Errorf("JSONReport file foo.md = %v, want 12 chinese_chars", file)
+		}
+	}
+}
+
+func TestDirCounter_Refresh(t *testing.T) {
+	dir := createBookDir(t)
+	dc := wcg.NewDirCounterWithPathMode(dir, wcg.PathDisplayRelative, "*.txt")
+	if err := dc.Count(); err != nil {
+		t.Fatalf("DirCounter.Count() error = %v", err)
+	}
+
+	write := func(name, content string) string {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("Failed to create directory: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write test file: %v", err)
+		}
+		return path
+	}
+
+	changed := []string{
+		write("intro.md", "新的前言"),
+		write("part1/a.md", "新文件"),
+		write("part3/b/c.md", "新目录"),
+		write("notes.txt", "忽略"),
+		filepath.Join(dir, "part3"),
+		filepath.Join(dir, "part1", "missing.md"),
+		filepath.Join(filepath.Dir(dir), "outside.md"),
+	}
+	if err := os.RemoveAll(filepath.Join(dir, "part2")); err != nil {
+		t.Fatalf("Failed to remove directory: %v", err)
+	}
+	changed = append(changed, filepath.Join(dir, "part2"))
+
+	if err := dc.Refresh(changed...); err != nil {
+		t.Fatalf("DirCounter.Refresh() error = %v", err)
+	}
+
+	want := []string{"intro.md", "part1/a.md", "part1/ch01.md", "part1/sec/ch02.md", "part3/b/c.md"}
+	fcs := dc.GetFileCounters()
+	if len(fcs) != len(want) {
+		t.Fatalf("DirCounter.Refresh() files = %d, want %v", len(fcs), want)
+	}
+	for i, fc := range fcs {
+		if fc.FileName != filepath.Join(dir, filepath.FromSlash(want[i])) {
+			t.Errorf("file %d = %s, want %s", i, fc.FileName, want[i])
+		}
+	}
+	if fcs[0].ChineseChars != 4 || fcs[4].ChineseChars != 3 {
+		t.Errorf("DirCounter.Refresh() did not recount intro.md and part3/b/c.md")
+	}
+
+	// A refreshed tree reports the same as a full count
+	full := wcg.NewDirCounterWithPathMode(dir, wcg.PathDisplayRelative, "*.txt")
+	if err := full.Count(); err != nil {
+		t.Fatalf("DirCounter.Count() error = %v", err)
+	}
+	if !reflect.DeepEqual(full.GetRows(), dc.GetRows()) {
+		t.Errorf("DirCounter.Refresh() rows = %v, want %v", dc.GetRows(), full.GetRows())
+	}
+
+	dc.SetGitRevision("HEAD")
+	if err := dc.Refresh(changed...); err == nil {
+		t.Error("DirCounter.Refresh() with a git revision should return error")
+	}
+}
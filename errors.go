@@ -2,6 +2,8 @@ package wordcounter
 
 import (
 	"fmt"
+	"math"
+	"time"
 )
 
 // WordCounterError represents different types of errors that can occur in wordcounter
@@ -32,8 +34,40 @@ const (
 	ErrorTypeExport
 	// ErrorTypeServer indicates a server-related error
 	ErrorTypeServer
+	// ErrorTypeGit indicates a failed git command
+	ErrorTypeGit
+	// ErrorTypeRequestTooLarge indicates a request body over the size limit
+	ErrorTypeRequestTooLarge
+	// ErrorTypeUnauthorized indicates a request without a valid API key
+	ErrorTypeUnauthorized
+	// ErrorTypeRateLimited indicates a client over its rate limit
+	ErrorTypeRateLimited
 )
 
+// errorCodes are the machine-readable codes of the error types
+var errorCodes = map[ErrorType]string{
+	ErrorTypeFileNotFound:    "file_not_found",
+	ErrorTypeFileRead:        "file_read_failed",
+	ErrorTypeFileWrite:       "file_write_failed",
+	ErrorTypeInvalidInput:    "invalid_input",
+	ErrorTypeInvalidPath:     "invalid_path",
+	ErrorTypePatternMatch:    "invalid_pattern",
+	ErrorTypeExport:          "export_failed",
+	ErrorTypeServer:          "server_error",
+	ErrorTypeGit:             "git_failed",
+	ErrorTypeRequestTooLarge: "request_too_large",
+	ErrorTypeUnauthorized:    "unauthorized",
+	ErrorTypeRateLimited:     "rate_limited",
+}
+
+// Code returns a stable machine-readable code of the error type, e.g. "invalid_input"
+func (t ErrorType) Code() string {
+	if code, ok := errorCodes[t]; ok {
+		return code
+	}
+	return "unknown"
+}
+
 // Error implements the error interface
 func (e *WordCounterError) Error() string {
 	if e.Cause != nil {
@@ -111,3 +145,27 @@ func NewExportError(operation string, cause error) *WordCounterError {
 func NewServerError(message string, cause error) *WordCounterError {
 	return NewError(ErrorTypeServer, message, cause)
 }
+
+// NewGitError creates a git command error
+func NewGitError(command string, cause error) *WordCounterError {
+	return NewError(ErrorTypeGit, fmt.Sprintf("git command failed: %s", command), cause).
+		WithContext("command", command)
+}
+
+// NewRequestTooLargeError creates an error for a request body over limit bytes
+func NewRequestTooLargeError(limit int64) *WordCounterError {
+	return NewError(ErrorTypeRequestTooLarge, fmt.Sprintf("request body exceeds %d bytes", limit), nil).
+		WithContext("limit", limit)
+}
+
+// NewUnauthorizedError creates an error for a request without a valid API key
+func NewUnauthorizedError(message string) *WordCounterError {
+	return NewError(ErrorTypeUnauthorized, message, nil)
+}
+
+// NewRateLimitError creates an error for a client over its rate limit, which may retry after the delay
+func NewRateLimitError(retryAfter time.Duration) *WordCounterError {
+	seconds := int(math.Ceil(retryAfter.Seconds()))
+	return NewError(ErrorTypeRateLimited, fmt.Sprintf("rate limit exceeded, retry after %ds", seconds), nil).
+		WithContext("retry_after", seconds)
+}
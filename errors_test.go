@@ -3,6 +3,7 @@ package wordcounter_test
 import (
 	"errors"
 	"testing"
+	"time"
 
 	wcg "github.com/100gle/wordcounter"
 )
@@ -62,6 +63,24 @@ func TestWordCounterError(t *testing.T) {
 			wantMsg:  "failed to start server: port in use",
 			wantType: wcg.ErrorTypeServer,
 		},
+		{
+			name:     "Git error",
+			err:      wcg.NewGitError("git ls-tree -r v1.0", errors.New("exit status 128")),
+			wantMsg:  "git command failed: git ls-tree -r v1.0: exit status 128",
+			wantType: wcg.ErrorTypeGit,
+		},
+		{
+			name:     "Request too large error",
+			err:      wcg.NewRequestTooLargeError(1024),
+			wantMsg:  "request body exceeds 1024 bytes",
+			wantType: wcg.ErrorTypeRequestTooLarge,
+		},
+		{
+			name:     "Rate limit error",
+			err:      wcg.NewRateLimitError(1500 * time.Millisecond),
+			wantMsg:  "rate limit exceeded, retry after 2s",
+			wantType: wcg.ErrorTypeRateLimited,
+		},
 	}
 
 	for _, tt := range tests {
@@ -252,3 +271,20 @@ func TestWordCounterError_WithContextComplexTypes(t *testing.T) {
 		t.Errorf("Expected struct with Name=test, Value=123, got %+v", actualStruct)
 	}
 }
+
+func TestErrorType_Code(t *testing.T) {
+	codes := map[string]bool{}
+	for errorType := wcg.ErrorTypeFileNotFound; errorType <= wcg.ErrorTypeRateLimited; errorType++ {
+		code := errorType.Code()
+		if code == "unknown" || codes[code] {
+			t.Errorf("ErrorType(%d).Code() = %q, want a unique code", errorType, code)
+		}
+		codes[code] = true
+	}
+	if code := wcg.ErrorTypeInvalidInput.Code(); code != "invalid_input" {
+		t.Errorf("ErrorTypeInvalidInput.Code() = %q, want invalid_input", code)
+	}
+	if code := wcg.ErrorType(-1).Code(); code != "unknown" {
+		t.Errorf("ErrorType(-1).Code() = %q, want unknown", code)
+	}
+}
@@ -1,7 +1,9 @@
 package wordcounter
 
 import (
+	"bytes"
 	"encoding/csv"
+	"encoding/json"
 	"fmt"
 	"os"
 
@@ -49,13 +51,6 @@ func exportToCSV(data []Row, filename ...string) (string, error) {
 
 // exportToExcel exports data to Excel format
 func exportToExcel(data []Row, filename ...string) error {
-	if len(data) == 0 {
-		return NewInvalidInputError("no data to export")
-	}
-
-	f := excelize.NewFile()
-	defer f.Close()
-
 	defaultFilename := "counter.xlsx"
 	if len(filename) > 0 {
 		absPath, err := toAbsolutePathWithError(filename[0])
@@ -65,22 +60,55 @@ func exportToExcel(data []Row, filename ...string) error {
 		defaultFilename = absPath
 	}
 
+	f, err := newExcelFile(data)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	if err := f.SaveAs(defaultFilename); err != nil {
+		return NewFileWriteError(defaultFilename, err)
+	}
+	return nil
+}
+
+// exportToExcelBytes exports data to an Excel workbook in memory
+func exportToExcelBytes(data []Row) ([]byte, error) {
+	f, err := newExcelFile(data)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	buf, err := f.WriteToBuffer()
+	if err != nil {
+		return nil, NewExportError("Excel export", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// newExcelFile creates a workbook with the data in its first sheet
+func newExcelFile(data []Row) (*excelize.File, error) {
+	if len(data) == 0 {
+		return nil, NewInvalidInputError("no data to export")
+	}
+
+	f := excelize.NewFile()
 	index, err := f.NewSheet("Sheet1")
 	if err != nil {
-		return NewExportError("Excel export - create sheet", err)
+		f.Close()
+		return nil, NewExportError("Excel export - create sheet", err)
 	}
 
 	for rowIndex, row := range data {
 		if err := f.SetSheetRow("Sheet1", fmt.Sprintf("A%d", rowIndex+1), &row); err != nil {
-			return NewExportError(fmt.Sprintf("Excel export - set row %d", rowIndex+1), err)
+			f.Close()
+			return nil, NewExportError(fmt.Sprintf("Excel export - set row %d", rowIndex+1), err)
 		}
 	}
 
 	f.SetActiveSheet(index)
-	if err := f.SaveAs(defaultFilename); err != nil {
-		return NewFileWriteError(defaultFilename, err)
-	}
-	return nil
+	return f, nil
 }
 
 // exportToTable exports data to table format
@@ -98,6 +126,64 @@ func exportToTable(data []Row) string {
 	return w.Render()
 }
 
+// JSONReport is the document written by the JSON exporters.
+// File and total entries are keyed by column key, e.g. "chinese_chars".
+type JSONReport struct {
+	Columns []string         `json:"columns"`
+	Files   []map[string]any `json:"files"`
+	Total   map[string]any   `json:"total,omitempty"`
+}
+
+// newJSONReport builds a JSON report from rows of the given columns.
+// The total row is optional.
+func newJSONReport(columns []string, rows []Row, total Row) *JSONReport {
+	toObject := func(row Row) map[string]any {
+		object := make(map[string]any, len(columns))
+		for i, key := range columns {
+			if i < len(row) {
+				object[key] = row[i]
+			}
+		}
+		return object
+	}
+
+	report := &JSONReport{
+		Columns: columns,
+		Files:   make([]map[string]any, 0, len(rows)),
+	}
+	for _, row := range rows {
+		report.Files = append(report.Files, toObject(row))
+	}
+	if total != nil {
+		report.Total = toObject(total)
+	}
+	return report
+}
+
+// exportToJSON exports any value as indented JSON and optionally writes it to a file
+func exportToJSON(v any, filename ...string) (string, error) {
+	// Keep globs and rules such as "<=" readable instead of escaping them for HTML
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(v); err != nil {
+		return "", NewExportError("JSON export", err)
+	}
+	data := bytes.TrimSuffix(buf.Bytes(), []byte("\n"))
+
+	if len(filename) > 0 && filename[0] != "" {
+		absPath, err := toAbsolutePathWithError(filename[0])
+		if err != nil {
+			return "", NewExportError("JSON export", err)
+		}
+		if err := os.WriteFile(absPath, data, 0644); err != nil {
+			return "", NewFileWriteError(absPath, err)
+		}
+	}
+	return string(data), nil
+}
+
 // getHeaderAndRows is a helper function that combines header and rows from a Counter
 func getHeaderAndRows(c Countable) []Row {
 	header := c.GetHeader()
@@ -5,6 +5,7 @@ import (
 	"io"
 	"os"
 	"path/filepath"
+	"time"
 )
 
 // FileCounter provides character counting functionality for individual files.
@@ -16,6 +17,12 @@ type FileCounter struct {
 	FileName        string // Absolute path to the file being analyzed
 	originalPath    string // Original path as provided by user
 	pathDisplayMode string // Path display mode: absolute or relative
+	columns         []string
+	size            int64
+	modTime         time.Time
+	frontMatter     []byte // YAML front matter, parsed by Metadata on first use
+	metadata        map[string]any
+	metadataParsed  bool
 }
 
 // NewFileCounter creates a new FileCounter instance for the specified file.
@@ -58,35 +65,73 @@ func NewFileCounterWithPathMode(filename string, pathDisplayMode string) *FileCo
 //   - FileNotFoundError: if the file doesn't exist
 //   - FileReadError: if there are I/O errors during reading or counting
 func (fc *FileCounter) Count() error {
+	data, err := fc.readFile()
+	if err != nil {
+		return err
+	}
+	return fc.countData(data)
+}
+
+// readFile reads the whole file, records its size and modification time and warns if it is empty
+func (fc *FileCounter) readFile() ([]byte, error) {
 	file, err := os.Open(fc.FileName)
 	if err != nil {
 		if os.IsNotExist(err) {
-			return NewFileNotFoundError(fc.FileName, err)
+			return nil, NewFileNotFoundError(fc.FileName, err)
 		}
-		return NewFileReadError(fc.FileName, err)
+		return nil, NewFileReadError(fc.FileName, err)
 	}
 	defer file.Close()
 
+	if info, err := file.Stat(); err == nil {
+		fc.size = info.Size()
+		fc.modTime = info.ModTime()
+	}
+
 	// Read entire file at once for better performance and simpler logic
 	// This avoids issues with splitting lines/characters across buffer boundaries
 	data, err := io.ReadAll(file)
 	if err != nil {
-		return NewFileReadError(fc.FileName, err)
+		return nil, NewFileReadError(fc.FileName, err)
 	}
 
 	// Check for empty file and issue warning
 	if len(data) == 0 {
-		displayPath := fc.getDisplayPath()
-		fmt.Fprintf(os.Stderr, "Warning: Empty file detected: %s\n", displayPath)
+		fc.warnEmpty()
 	}
+	return data, nil
+}
 
+// warnEmpty warns that the file is empty
+func (fc *FileCounter) warnEmpty() {
+	fmt.Fprintln(os.Stderr, T(MsgWarnEmptyFile, fc.getDisplayPath()))
+}
+
+// countData counts the file content and keeps its front matter
+func (fc *FileCounter) countData(data []byte) error {
+	fc.setFrontMatter(frontMatterBlock(data))
 	if err := fc.CountBytes(data); err != nil {
 		return NewFileReadError(fc.FileName, err)
 	}
-
 	return nil
 }
 
+// setFrontMatter replaces the front matter, nil if the file has none
+func (fc *FileCounter) setFrontMatter(block []byte) {
+	fc.frontMatter, fc.metadata, fc.metadataParsed = block, nil, false
+}
+
+// Metadata returns the fields of the YAML front matter of the file as of the last Count,
+// or nil if the file has no front matter. It is parsed on the first call, so that
+// counting does not pay for YAML unless something reads the metadata.
+func (fc *FileCounter) Metadata() map[string]any {
+	if !fc.metadataParsed {
+		fc.metadata = parseFrontMatter(fc.frontMatter)
+		fc.metadataParsed = true
+	}
+	return fc.metadata
+}
+
 // GetStats returns the counting statistics from the internal Counter.
 // This method provides access to the detailed character counting results
 // after Count() has been called.
@@ -94,10 +139,48 @@ func (fc *FileCounter) GetStats() *Stats {
 	return fc.Stats
 }
 
+// Size returns the file size in bytes as of the last Count
+func (fc *FileCounter) Size() int64 {
+	return fc.size
+}
+
+// ModTime returns the modification time of the file as of the last Count
+func (fc *FileCounter) ModTime() time.Time {
+	return fc.modTime
+}
+
+// SetColumns selects and orders the columns of GetHeader and GetRow.
+// See AvailableColumns for the supported keys.
+func (fc *FileCounter) SetColumns(keys ...string) error {
+	if err := ValidateColumns(keys); err != nil {
+		return err
+	}
+	fc.columns = keys
+	return nil
+}
+
+// Columns returns the selected columns, DefaultColumns if none were selected
+func (fc *FileCounter) Columns() []string {
+	if len(fc.columns) == 0 {
+		return DefaultColumns
+	}
+	return fc.columns
+}
+
 func (fc *FileCounter) GetRow() Row {
-	displayPath := fc.getDisplayPath()
-	row := append(Row{displayPath}, fc.ToRow()...)
-	return row
+	return columnRow(fc.columns, fc.columnInput(fc.TotalChars))
+}
+
+// columnInput returns the column values source of this file, with the
+// total characters of all reported files used for percentages
+func (fc *FileCounter) columnInput(grandTotal int) *columnInput {
+	return &columnInput{
+		path:       fc.getDisplayPath(),
+		stats:      *fc.Stats,
+		size:       fc.size,
+		modTime:    fc.modTime,
+		grandTotal: grandTotal,
+	}
 }
 
 // getDisplayPath returns the path to display based on the path display mode
@@ -113,8 +196,7 @@ func (fc *FileCounter) getDisplayPath() string {
 }
 
 func (fc *FileCounter) GetHeader() Row {
-	headers := append(Row{"File"}, fc.Header()...)
-	return headers
+	return columnHeader(fc.columns)
 }
 
 func (fc *FileCounter) ExportCSV(filename ...string) (string, error) {
@@ -125,10 +207,23 @@ func (fc *FileCounter) ExportExcel(filename ...string) error {
 	return ExportCounterExcel(fc, filename...)
 }
 
+// ExportJSON exports the result as a JSONReport with a single file entry
+func (fc *FileCounter) ExportJSON(filename ...string) (string, error) {
+	return exportToJSON(newJSONReport(fc.Columns(), fc.GetRows(), nil), filename...)
+}
+
 func (fc *FileCounter) ExportTable() string {
 	return ExportCounterTable(fc)
 }
 
+// ExportTemplate renders the counting result through the named template.
+// See RenderTemplate for how the name is resolved.
+func (fc *FileCounter) ExportTemplate(name string, filename ...string) (string, error) {
+	data := NewTemplateData(fc.FileName, ModeFile, []*FileCounter{fc})
+	data.setReport(fc.Columns(), []*FileCounter{fc}, fc.TotalChars)
+	return exportToTemplate(data, name, filename...)
+}
+
 // GetRows returns the data rows (implements Counter interface)
 func (fc *FileCounter) GetRows() []Row {
 	return []Row{fc.GetRow()}
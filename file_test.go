@@ -396,3 +396,24 @@ func TestFileCounter_CountPermissionDenied(t *testing.T) {
 		t.Errorf("Expected FileReadError, got: %v", err)
 	}
 }
+
+func TestFileCounter_ExportJSON(t *testing.T) {
+	fc := wcg.NewFileCounter("testdata/foo.md")
+	if err := fc.Count(); err != nil {
+		t.Fatalf("FileCounter.Count() error = %v", err)
+	}
+
+	output := filepath.Join(t.TempDir(), "report.json")
+	jsonData, err := fc.ExportJSON(output)
+	if err != nil {
+		t.Fatalf("FileCounter.ExportJSON() error = %v", err)
+	}
+	if !strings.Contains(jsonData, `"total_chars": 13`) || strings.Contains(jsonData, `"total":`) {
+		t.Errorf("FileCounter.ExportJSON() = %s", jsonData)
+	}
+
+	written, err := os.ReadFile(output)
+	if err != nil || string(written) != jsonData {
+		t.Errorf("FileCounter.ExportJSON() wrote %q, err = %v", written, err)
+	}
+}
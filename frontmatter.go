@@ -0,0 +1,56 @@
+package wordcounter
+
+import (
+	"bytes"
+
+	"gopkg.in/yaml.v3"
+)
+
+var frontMatterDelimiter = []byte("---")
+
+// frontMatterBlock returns the YAML front matter block delimited by "---" lines
+// at the very beginning of data, as used by Hugo, Jekyll and most Markdown tools.
+// Returns nil if there is no front matter.
+func frontMatterBlock(data []byte) []byte {
+	block, _, found := splitFrontMatter(data)
+	if !found {
+		return nil
+	}
+	return block
+}
+
+// parseFrontMatter parses a front matter block.
+// Returns nil if the block is empty or not valid YAML.
+func parseFrontMatter(block []byte) map[string]any {
+	if len(block) == 0 {
+		return nil
+	}
+	meta := map[string]any{}
+	if err := yaml.Unmarshal(block, &meta); err != nil {
+		return nil
+	}
+	return meta
+}
+
+// splitFrontMatter separates the front matter block, without its delimiters, from the rest of data
+func splitFrontMatter(data []byte) (block, body []byte, found bool) {
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf")) // UTF-8 BOM
+	if !bytes.HasPrefix(data, frontMatterDelimiter) {
+		return nil, data, false
+	}
+
+	lines := bytes.SplitAfter(data, []byte("\n"))
+	if len(bytes.TrimSpace(lines[0])) != len(frontMatterDelimiter) {
+		return nil, data, false
+	}
+
+	offset := len(lines[0])
+	for _, line := range lines[1:] {
+		offset += len(line)
+		if bytes.Equal(bytes.TrimSpace(line), frontMatterDelimiter) {
+			return block, data[offset:], true
+		}
+		block = append(block, line...)
+	}
+	return nil, data, false
+}
@@ -0,0 +1,161 @@
+package wordcounter
+
+import (
+	"bytes"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// GitIndex is the revision that selects the staging index instead of a commit
+const GitIndex = ":"
+
+// gitFile is a blob of a git tree
+type gitFile struct {
+	Path   string // Slash separated path relative to the listed directory
+	Object string // Blob object name
+}
+
+// runGit runs the local git binary in dir and returns its standard output
+func runGit(dir string, args ...string) ([]byte, error) {
+	cmd := exec.Command("git", append([]string{"-C", dir}, args...)...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	out, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			err = fmt.Errorf("%w: %s", err, msg)
+		}
+		return nil, NewGitError("git "+strings.Join(args, " "), err).WithContext("dir", dir)
+	}
+	return out, nil
+}
+
+// checkGitRevision rejects revisions starting with "-", which git would take for options such as "--output=<file>"
+func checkGitRevision(rev string) error {
+	if strings.HasPrefix(rev, "-") {
+		return NewInvalidInputError(fmt.Sprintf("invalid git revision: %s", rev)).WithContext("rev", rev)
+	}
+	return nil
+}
+
+// gitListFiles lists the blobs of the revision, or of the index for GitIndex, below dir
+// with paths relative to dir
+func gitListFiles(dir, rev string) ([]gitFile, error) {
+	if rev == GitIndex {
+		return gitListIndex(dir)
+	}
+	if err := checkGitRevision(rev); err != nil {
+		return nil, err
+	}
+
+	out, err := runGit(dir, "ls-tree", "-r", "-z", rev)
+	if err != nil {
+		return nil, err
+	}
+
+	var files []gitFile
+	for _, entry := range strings.Split(string(out), "\x00") {
+		// <mode> SP <type> SP <object> TAB <path>
+		info, path, found := strings.Cut(entry, "\t")
+		fields := strings.Fields(info)
+		if !found || len(fields) != 3 || fields[1] != "blob" {
+			continue
+		}
+		files = append(files, gitFile{Path: path, Object: fields[2]})
+	}
+	return files, nil
+}
+
+// gitListIndex lists the staged blobs below dir with paths relative to dir.
+// Unmerged entries are skipped.
+func gitListIndex(dir string) ([]gitFile, error) {
+	out, err := runGit(dir, "ls-files", "-s", "-z")
+	if err != nil {
+		return nil, err
+	}
+
+	var files []gitFile
+	for _, entry := range strings.Split(string(out), "\x00") {
+		// <mode> SP <object> SP <stage> TAB <path>
+		info, path, found := strings.Cut(entry, "\t")
+		fields := strings.Fields(info)
+		if !found || len(fields) != 3 || fields[2] != "0" {
+			continue
+		}
+		files = append(files, gitFile{Path: path, Object: fields[1]})
+	}
+	return files, nil
+}
+
+// gitCommitTime returns the committer date of the revision
+func gitCommitTime(dir, rev string) (time.Time, error) {
+	if err := checkGitRevision(rev); err != nil {
+		return time.Time{}, err
+	}
+	out, err := runGit(dir, "log", "-1", "--format=%ct", rev, "--")
+	if err != nil {
+		return time.Time{}, err
+	}
+	seconds, err := strconv.ParseInt(strings.TrimSpace(string(out)), 10, 64)
+	if err != nil {
+		return time.Time{}, NewGitError("git log -1 "+rev, err)
+	}
+	return time.Unix(seconds, 0), nil
+}
+
+// gitReadBlob returns the content of a blob
+func gitReadBlob(dir, object string) ([]byte, error) {
+	return runGit(dir, "cat-file", "blob", object)
+}
+
+// gitSource reads the files of a DirCounter from a revision of its git repository
+type gitSource struct {
+	rev     string
+	modTime time.Time         // Commit date, zero for the index
+	objects map[string]string // Blob of each absolute file name
+}
+
+// list enumerates the files of the revision below root that are not ignored by dc
+func (g *gitSource) list(dc *DirCounter, root string) ([]string, error) {
+	files, err := gitListFiles(root, g.rev)
+	if err != nil {
+		return nil, err
+	}
+	if g.rev != GitIndex {
+		if g.modTime, err = gitCommitTime(root, g.rev); err != nil {
+			return nil, err
+		}
+	}
+
+	g.objects = make(map[string]string, len(files))
+	filePaths := make([]string, 0, len(files))
+	for _, file := range files {
+		if dc.isIgnoredRelPath(file.Path) {
+			continue
+		}
+		path := filepath.Join(root, filepath.FromSlash(file.Path))
+		g.objects[path] = file.Object
+		filePaths = append(filePaths, path)
+	}
+	return filePaths, nil
+}
+
+// count reads the blob of the file and counts it
+func (g *gitSource) count(fc *FileCounter, root string) error {
+	data, err := gitReadBlob(root, g.objects[fc.FileName])
+	if err != nil {
+		return NewFileReadError(fc.FileName, err)
+	}
+
+	fc.size = int64(len(data))
+	fc.modTime = g.modTime
+	if len(data) == 0 {
+		fc.warnEmpty()
+	}
+	return fc.countData(data)
+}
@@ -0,0 +1,146 @@
+package wordcounter_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	wcg "github.com/100gle/wordcounter"
+)
+
+func TestDirCounter_SetGitRevision(t *testing.T) {
+	dir := createGitBook(t)
+
+	// Uncommitted and unstaged changes are not seen by revisions
+	if err := os.WriteFile(filepath.Join(dir, "draft.md"), []byte("草稿"), 0644); err != nil {
+		t.Fatalf("Failed to write test file: %v", err)
+	}
+
+	tests := []struct {
+		name      string
+		rev       string
+		ignores   []string
+		wantFiles []string
+		wantChars int
+	}{
+		{
+			name:      "tag",
+			rev:       "v1",
+			wantFiles: []string{"intro.md", "part1/ch01.md", "part1/sec/ch02.md", "part2/ch03.md", "part2/deep/a/b.txt"},
+			wantChars: 14,
+		},
+		{
+			name:      "branch with ignores",
+			rev:       "HEAD",
+			ignores:   []string{"deep", "/" + filepath.Join(dir, "part1")},
+			wantFiles: []string{"intro.md", "part2/ch04.md"},
+			wantChars: 9,
+		},
+		{
+			name:      "relative revision",
+			rev:       "v2~1",
+			ignores:   []string{"*.txt"},
+			wantFiles: []string{"intro.md", "part1/ch01.md", "part1/sec/ch02.md", "part2/ch03.md"},
+			wantChars: 13,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dc := wcg.NewDirCounterWithPathMode(dir, wcg.PathDisplayRelative, tt.ignores...)
+			dc.SetGitRevision(tt.rev)
+			if dc.GitRevision() != tt.rev {
+				t.Errorf("DirCounter.GitRevision() = %s, want %s", dc.GitRevision(), tt.rev)
+			}
+			if err := dc.Count(); err != nil {
+				t.Fatalf("DirCounter.Count() error = %v", err)
+			}
+
+			fcs := dc.GetFileCounters()
+			if len(fcs) != len(tt.wantFiles) {
+				t.Fatalf("DirCounter.Count() counted %d files, want %v", len(fcs), tt.wantFiles)
+			}
+			chars := 0
+			for i, fc := range fcs {
+				if fc.FileName != filepath.Join(dir, filepath.FromSlash(tt.wantFiles[i])) {
+					t.Errorf("file %d = %s, want %s", i, fc.FileName, tt.wantFiles[i])
+				}
+				if fc.ModTime().IsZero() || fc.Size() == 0 {
+					t.Errorf("file %s should have the commit date and blob size", fc.FileName)
+				}
+				chars += fc.ChineseChars
+			}
+			if chars != tt.wantChars {
+				t.Errorf("DirCounter.Count() Chinese chars = %d, want %d", chars, tt.wantChars)
+			}
+		})
+	}
+}
+
+func TestDirCounter_GitIndex(t *testing.T) {
+	dir := createGitBook(t)
+	if err := os.WriteFile(filepath.Join(dir, "intro.md"), []byte("暂存的前言"), 0644); err != nil {
+		t.Fatalf("Failed to write test file: %v", err)
+	}
+	runGit(t, dir, "add", "intro.md")
+	// The working tree changes again after staging
+	if err := os.WriteFile(filepath.Join(dir, "intro.md"), []byte("未暂存"), 0644); err != nil {
+		t.Fatalf("Failed to write test file: %v", err)
+	}
+
+	dc := wcg.NewDirCounter(filepath.Join(dir, "part2"))
+	dc.SetGitRevision(wcg.GitIndex)
+	if err := dc.Count(); err != nil {
+		t.Fatalf("DirCounter.Count() error = %v", err)
+	}
+	if got := len(dc.GetFileCounters()); got != 2 {
+		t.Errorf("DirCounter.Count() of the index below part2 counted %d files, want 2", got)
+	}
+
+	dc = wcg.NewDirCounter(dir, "part*")
+	dc.SetGitRevision(wcg.GitIndex)
+	if err := dc.Count(); err != nil {
+		t.Fatalf("DirCounter.Count() error = %v", err)
+	}
+	fcs := dc.GetFileCounters()
+	if len(fcs) != 1 || fcs[0].ChineseChars != 5 {
+		t.Errorf("DirCounter.Count() of the index = %d files, want the staged intro.md", len(fcs))
+	}
+
+	// An empty revision counts the working tree again
+	dc.SetGitRevision("")
+	if err := dc.Count(); err != nil {
+		t.Fatalf("DirCounter.Count() error = %v", err)
+	}
+	if dc.GitRevision() != "" || len(dc.GetFileCounters()) == 1 {
+		t.Error("DirCounter.SetGitRevision(\"\") should count the working tree")
+	}
+}
+
+func TestDirCounter_GitRevisionErrors(t *testing.T) {
+	dir := createGitBook(t)
+
+	dc := wcg.NewDirCounter(dir)
+	dc.SetGitRevision("missing")
+	if err := dc.Count(); err == nil {
+		t.Error("DirCounter.Count() with unknown revision should return error")
+	}
+
+	// Revisions are not taken for options
+	output := filepath.Join(t.TempDir(), "output")
+	dc = wcg.NewDirCounter(dir)
+	dc.SetGitRevision("--output=" + output)
+	var wcErr *wcg.WordCounterError
+	if err := dc.Count(); !errors.As(err, &wcErr) || wcErr.Type != wcg.ErrorTypeInvalidInput {
+		t.Errorf("DirCounter.Count() with an option as revision error = %v, want invalid input", err)
+	}
+	if _, err := os.Stat(output); !os.IsNotExist(err) {
+		t.Errorf("git wrote %s, the revision was taken for an option", output)
+	}
+
+	dc = wcg.NewDirCounter(t.TempDir())
+	dc.SetGitRevision("HEAD")
+	if err := dc.Count(); err == nil {
+		t.Error("DirCounter.Count() outside of a git repository should return error")
+	}
+}
@@ -0,0 +1,187 @@
+package wordcounter
+
+import (
+	"fmt"
+	"math"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// GoalDeadlineLayout is the date layout of goal deadlines
+const GoalDeadlineLayout = "2006-01-02"
+
+// goalProgressBarWidth is the number of cells of the progress bar
+const goalProgressBarWidth = 20
+
+// Goal is a writing target for the whole project or the files matching Path
+type Goal struct {
+	Name     string `yaml:"name" toml:"name" json:"name"`
+	Path     string `yaml:"path,omitempty" toml:"path,omitempty" json:"path,omitempty"`             // Glob relative to the counted directory, empty for all files
+	Field    string `yaml:"field,omitempty" toml:"field,omitempty" json:"field,omitempty"`          // Stats field, chinese_chars by default
+	Target   int    `yaml:"target" toml:"target" json:"target"`                                     // Number to reach
+	Minimum  int    `yaml:"minimum,omitempty" toml:"minimum,omitempty" json:"minimum,omitempty"`    // Number below which the goal fails
+	Deadline string `yaml:"deadline,omitempty" toml:"deadline,omitempty" json:"deadline,omitempty"` // Date formatted as 2006-01-02
+}
+
+// GoalProgress is the state of a goal for the current counts
+type GoalProgress struct {
+	Goal      Goal    `json:"goal"`
+	Files     int     `json:"files"`
+	Current   int     `json:"current"`
+	Percent   float64 `json:"percent"`
+	Remaining int     `json:"remaining"`
+	DaysLeft  int     `json:"days_left,omitempty"`  // Days until the end of the deadline, including today
+	DailyPace float64 `json:"daily_pace,omitempty"` // Required amount per day to meet the deadline
+	Overdue   bool    `json:"overdue,omitempty"`
+	Failed    bool    `json:"failed,omitempty"` // The current amount is below the minimum
+}
+
+// field returns the Stats field of the goal
+func (g *Goal) field() string {
+	if g.Field == "" {
+		return ColumnChineseChars
+	}
+	return g.Field
+}
+
+// Validate checks that the goal has a target, a known field, a valid path pattern and deadline
+func (g *Goal) Validate() error {
+	if g.Target <= 0 {
+		return NewInvalidInputError(fmt.Sprintf("goal %q must have a positive target", g.Name)).WithContext("goal", g.Name)
+	}
+	if g.Minimum < 0 || g.Minimum > g.Target {
+		return NewInvalidInputError(fmt.Sprintf("goal %q minimum must be between 0 and the target", g.Name)).WithContext("goal", g.Name)
+	}
+	if _, err := (&Stats{}).Value(g.field()); err != nil {
+		return err
+	}
+	if g.Path != "" {
+		if _, err := filepath.Match(g.Path, ""); err != nil {
+			return NewPatternMatchError(g.Path, err).WithContext("goal", g.Name)
+		}
+	}
+	if g.Deadline != "" {
+		if _, err := time.ParseInLocation(GoalDeadlineLayout, g.Deadline, time.Local); err != nil {
+			return NewInvalidInputError(fmt.Sprintf("goal %q deadline must be formatted as %s", g.Name, GoalDeadlineLayout)).
+				WithContext("goal", g.Name).WithContext("deadline", g.Deadline)
+		}
+	}
+	return nil
+}
+
+// matches reports whether a slash separated path relative to the counted directory belongs to the goal
+func (g *Goal) matches(relPath string) bool {
+	return g.Path == "" || matchPathPattern(g.Path, relPath)
+}
+
+// EvaluateGoals computes the progress of each goal from the files counted by the DirCounter
+func EvaluateGoals(dc *DirCounter, goals []Goal) ([]*GoalProgress, error) {
+	return evaluateGoals(dc, goals, time.Now())
+}
+
+func evaluateGoals(dc *DirCounter, goals []Goal, now time.Time) ([]*GoalProgress, error) {
+	root := ToAbsolutePath(dc.dirname)
+	result := make([]*GoalProgress, 0, len(goals))
+
+	for _, goal := range goals {
+		if err := goal.Validate(); err != nil {
+			return nil, err
+		}
+
+		progress := &GoalProgress{Goal: goal}
+		for _, fc := range dc.fileCounters {
+			relPath, err := filepath.Rel(root, fc.FileName)
+			if err != nil || !goal.matches(filepath.ToSlash(relPath)) {
+				continue
+			}
+			value, _ := fc.Stats.Value(goal.field())
+			progress.Files++
+			progress.Current += value
+		}
+
+		progress.Percent = roundTo(ratio(progress.Current, goal.Target)*100, 1)
+		progress.Remaining = max(goal.Target-progress.Current, 0)
+		progress.Failed = goal.Minimum > 0 && progress.Current < goal.Minimum
+
+		if goal.Deadline != "" {
+			deadline, _ := time.ParseInLocation(GoalDeadlineLayout, goal.Deadline, time.Local)
+			today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local)
+			progress.DaysLeft = int(math.Round(deadline.Sub(today).Hours()/24)) + 1
+			if progress.DaysLeft <= 0 {
+				progress.DaysLeft = 0
+				progress.Overdue = progress.Remaining > 0
+			} else if progress.Remaining > 0 {
+				progress.DailyPace = roundTo(float64(progress.Remaining)/float64(progress.DaysLeft), 1)
+			}
+		}
+
+		result = append(result, progress)
+	}
+	return result, nil
+}
+
+// GoalsFailed reports whether any goal is below its minimum
+func GoalsFailed(progress []*GoalProgress) bool {
+	for _, p := range progress {
+		if p.Failed {
+			return true
+		}
+	}
+	return false
+}
+
+// progressBar draws the percentage as a bar such as [#########-----------]
+func progressBar(percent float64) string {
+	filled := int(math.Min(percent, 100) / 100 * goalProgressBarWidth)
+	return "[" + strings.Repeat("#", filled) + strings.Repeat("-", goalProgressBarWidth-filled) + "]"
+}
+
+// goalHeaderAndRows returns the goal table
+func goalHeaderAndRows(progress []*GoalProgress) []Row {
+	data := []Row{{
+		T(MsgHeaderGoal), T(MsgHeaderField), T(MsgHeaderCurrent), T(MsgHeaderTarget), T(MsgHeaderProgress),
+		T(MsgHeaderRemaining), T(MsgHeaderDeadline), T(MsgHeaderDailyPace),
+	}}
+
+	for _, p := range progress {
+		status := progressBar(p.Percent) + fmt.Sprintf(" %.1f%%", p.Percent)
+		if p.Failed {
+			status += " " + T(MsgGoalBelowMinimum, p.Goal.Minimum)
+		}
+
+		deadline := p.Goal.Deadline
+		switch {
+		case deadline == "":
+		case p.Overdue:
+			deadline += " " + T(MsgGoalOverdue)
+		case p.DaysLeft == 0:
+			// The deadline has passed with the goal met
+			deadline += " " + T(MsgGoalDone)
+		case p.DaysLeft == 1:
+			deadline += " " + T(MsgGoalDueToday)
+		default:
+			deadline += " " + T(MsgGoalDaysLeft, p.DaysLeft)
+		}
+
+		data = append(data, Row{
+			p.Goal.Name, p.Goal.field(), p.Current, p.Goal.Target, status, p.Remaining, deadline, p.DailyPace,
+		})
+	}
+	return data
+}
+
+// ExportGoalsTable renders the goal progress as a table
+func ExportGoalsTable(progress []*GoalProgress) string {
+	return exportToTable(goalHeaderAndRows(progress))
+}
+
+// ExportGoalsCSV exports the goal progress as CSV
+func ExportGoalsCSV(progress []*GoalProgress, filename ...string) (string, error) {
+	return exportToCSV(goalHeaderAndRows(progress), filename...)
+}
+
+// ExportGoalsJSON exports the goal progress as JSON
+func ExportGoalsJSON(progress []*GoalProgress, filename ...string) (string, error) {
+	return exportToJSON(progress, filename...)
+}
@@ -0,0 +1,149 @@
+package wordcounter_test
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	wcg "github.com/100gle/wordcounter"
+)
+
+func TestGoal_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		goal    wcg.Goal
+		wantErr bool
+	}{
+		{name: "valid", goal: wcg.Goal{Name: "book", Target: 100, Minimum: 50, Deadline: "2030-01-01"}},
+		{name: "valid field and path", goal: wcg.Goal{Name: "part1", Path: "part1/*", Field: "total_chars", Target: 10}},
+		{name: "missing target", goal: wcg.Goal{Name: "book"}, wantErr: true},
+		{name: "minimum above target", goal: wcg.Goal{Name: "book", Target: 10, Minimum: 20}, wantErr: true},
+		{name: "unknown field", goal: wcg.Goal{Name: "book", Field: "paragraphs", Target: 10}, wantErr: true},
+		{name: "invalid path", goal: wcg.Goal{Name: "book", Path: "[", Target: 10}, wantErr: true},
+		{name: "invalid deadline", goal: wcg.Goal{Name: "book", Target: 10, Deadline: "31/12/2030"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.goal.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Goal.Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestEvaluateGoals(t *testing.T) {
+	dir := createBookDir(t)
+	dc := wcg.NewDirCounter(dir)
+	if err := dc.Count(); err != nil {
+		t.Fatalf("DirCounter.Count() error = %v", err)
+	}
+
+	deadline := time.Now().AddDate(0, 0, 9).Format(wcg.GoalDeadlineLayout)
+	past := time.Now().AddDate(0, 0, -1).Format(wcg.GoalDeadlineLayout)
+	goals := []wcg.Goal{
+		{Name: "book", Target: 20, Deadline: deadline},
+		{Name: "part1", Path: "part1/*", Target: 10, Minimum: 8},
+		{Name: "chapters", Path: "ch*.md", Field: "lines", Target: 2, Deadline: past},
+		{Name: "late", Target: 100, Deadline: past},
+	}
+
+	progress, err := wcg.EvaluateGoals(dc, goals)
+	if err != nil {
+		t.Fatalf("EvaluateGoals() error = %v", err)
+	}
+
+	want := []wcg.GoalProgress{
+		{Files: 5, Current: 14, Percent: 70, Remaining: 6, DaysLeft: 10, DailyPace: 0.6},
+		{Files: 1, Current: 5, Percent: 50, Remaining: 5, Failed: true},
+		{Files: 3, Current: 3, Percent: 150, Remaining: 0},
+		{Files: 5, Current: 14, Percent: 14, Remaining: 86, Overdue: true},
+	}
+	for i, w := range want {
+		got := *progress[i]
+		w.Goal = goals[i]
+		if got != w {
+			t.Errorf("EvaluateGoals()[%d] = %+v, want %+v", i, got, w)
+		}
+	}
+	if !wcg.GoalsFailed(progress) {
+		t.Error("GoalsFailed() = false, want true")
+	}
+	if wcg.GoalsFailed(progress[:1]) {
+		t.Error("GoalsFailed() of the book goal = true, want false")
+	}
+
+	if _, err := wcg.EvaluateGoals(dc, []wcg.Goal{{Name: "bad"}}); err == nil {
+		t.Error("EvaluateGoals() with invalid goal should return error")
+	}
+}
+
+func TestExportGoalsTable_Deadline(t *testing.T) {
+	dir := createBookDir(t)
+	dc := wcg.NewDirCounter(dir)
+	if err := dc.Count(); err != nil {
+		t.Fatalf("DirCounter.Count() error = %v", err)
+	}
+
+	today := time.Now().Format(wcg.GoalDeadlineLayout)
+	past := time.Now().AddDate(0, 0, -1).Format(wcg.GoalDeadlineLayout)
+	future := time.Now().AddDate(0, 0, 9).Format(wcg.GoalDeadlineLayout)
+	tests := []struct {
+		name     string
+		deadline string
+		target   int
+		want     string
+		notWant  string
+	}{
+		{"due today", today, 20, today + " (due today)", "days left"},
+		{"due today and met", today, 10, today + " (due today)", "days left"},
+		{"overdue", past, 20, past + " (overdue)", "days left"},
+		{"done", past, 10, past + " (done)", "days left"},
+		{"days left", future, 20, future + " (10 days left)", "due today"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			progress, err := wcg.EvaluateGoals(dc, []wcg.Goal{{Name: "book", Target: tt.target, Deadline: tt.deadline}})
+			if err != nil {
+				t.Fatalf("EvaluateGoals() error = %v", err)
+			}
+			table := wcg.ExportGoalsTable(progress)
+			if !strings.Contains(table, tt.want) || strings.Contains(table, tt.notWant) {
+				t.Errorf("ExportGoalsTable() = %s, want %q without %q", table, tt.want, tt.notWant)
+			}
+		})
+	}
+}
+
+func TestExportGoals(t *testing.T) {
+	progress := []*wcg.GoalProgress{
+		{Goal: wcg.Goal{Name: "book", Target: 100, Minimum: 60}, Files: 2, Current: 50, Percent: 50, Remaining: 50, Failed: true},
+	}
+
+	table := wcg.ExportGoalsTable(progress)
+	for _, want := range []string{"GOAL", "book", "[##########----------] 50.0%", "(below minimum 60)"} {
+		if !strings.Contains(table, want) {
+			t.Errorf("ExportGoalsTable() missing %q:\n%s", want, table)
+		}
+	}
+
+	csvData, err := wcg.ExportGoalsCSV(progress)
+	if err != nil {
+		t.Fatalf("ExportGoalsCSV() error = %v", err)
+	}
+	if !strings.HasPrefix(csvData, "Goal,Field,Current,Target") || !strings.Contains(csvData, "book,chinese_chars,50,100") {
+		t.Errorf("ExportGoalsCSV() = %s", csvData)
+	}
+
+	jsonData, err := wcg.ExportGoalsJSON(progress)
+	if err != nil {
+		t.Fatalf("ExportGoalsJSON() error = %v", err)
+	}
+	var decoded []wcg.GoalProgress
+	if err := json.Unmarshal([]byte(jsonData), &decoded); err != nil {
+		t.Fatalf("ExportGoalsJSON() returned invalid JSON: %v", err)
+	}
+	if len(decoded) != 1 || decoded[0] != *progress[0] {
+		t.Errorf("ExportGoalsJSON() decoded = %+v", decoded)
+	}
+}
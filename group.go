@@ -0,0 +1,212 @@
+package wordcounter
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Group-by kinds
+const (
+	GroupByExt        = "ext"
+	GroupByDir        = "dir"
+	GroupByMetaPrefix = "meta:"
+)
+
+// GroupNone is the key of files that have no value for the grouping key,
+// e.g. files without extension or without the front matter field
+const GroupNone = "(none)"
+
+// GroupAll is the key of the single group of the zero GroupBy
+const GroupAll = "(all)"
+
+// Group is the aggregate of all files sharing a key
+type Group struct {
+	Key   string `json:"key"`
+	Files int    `json:"files"`
+	Stats Stats  `json:"stats"`
+}
+
+// GroupReport is the document written by the JSON group exporter
+type GroupReport struct {
+	By     string   `json:"by"`
+	Groups []*Group `json:"groups"`
+	Total  *Group   `json:"total,omitempty"`
+}
+
+// GroupKeyFunc returns the keys a file belongs to.
+// A file with several keys, e.g. several tags, is added to each of their groups.
+type GroupKeyFunc func(fc *FileCounter) []string
+
+// GroupBy is a parsed grouping specification, its zero value puts all files in one group
+type GroupBy struct {
+	spec string
+	key  func(root string) GroupKeyFunc
+}
+
+// ParseGroupBy parses a grouping specification:
+//   - "ext": by lower-cased file extension
+//   - "dir": by top-level directory below the counted directory, "." for files in it
+//   - "meta:<key>": by a front matter field; list values such as tags put the file in several groups
+func ParseGroupBy(spec string) (GroupBy, error) {
+	switch {
+	case spec == GroupByExt:
+		return GroupBy{spec: spec, key: func(string) GroupKeyFunc { return groupByExt }}, nil
+	case spec == GroupByDir:
+		return GroupBy{spec: spec, key: groupByDir}, nil
+	case strings.HasPrefix(spec, GroupByMetaPrefix) && len(spec) > len(GroupByMetaPrefix):
+		field := strings.TrimPrefix(spec, GroupByMetaPrefix)
+		return GroupBy{spec: spec, key: func(string) GroupKeyFunc { return groupByMeta(field) }}, nil
+	default:
+		return GroupBy{}, NewInvalidInputError(fmt.Sprintf("unsupported group-by: %s, supported: %s, %s, %s<key>",
+			spec, GroupByExt, GroupByDir, GroupByMetaPrefix)).WithContext("group_by", spec)
+	}
+}
+
+// String returns the specification the GroupBy was parsed from
+func (g GroupBy) String() string {
+	return g.spec
+}
+
+func groupByAll(*FileCounter) []string {
+	return []string{GroupAll}
+}
+
+func groupByExt(fc *FileCounter) []string {
+	ext := strings.ToLower(filepath.Ext(fc.FileName))
+	if ext == "" {
+		return []string{GroupNone}
+	}
+	return []string{ext}
+}
+
+func groupByDir(root string) GroupKeyFunc {
+	return func(fc *FileCounter) []string {
+		relPath, err := filepath.Rel(root, fc.FileName)
+		if err != nil {
+			return []string{GroupNone}
+		}
+		first, _, found := strings.Cut(filepath.ToSlash(relPath), "/")
+		if !found {
+			return []string{"."}
+		}
+		return []string{first}
+	}
+}
+
+func groupByMeta(field string) GroupKeyFunc {
+	return func(fc *FileCounter) []string {
+		value, ok := fc.Metadata()[field]
+		if !ok || value == nil {
+			return []string{GroupNone}
+		}
+
+		values, isList := value.([]any)
+		if !isList {
+			return []string{fmt.Sprintf("%v", value)}
+		}
+		if len(values) == 0 {
+			return []string{GroupNone}
+		}
+
+		keys := make([]string, 0, len(values))
+		for _, v := range values {
+			keys = append(keys, fmt.Sprintf("%v", v))
+		}
+		return keys
+	}
+}
+
+// GroupFileCounters aggregates the files by the keys returned by keyFn.
+// Groups are sorted by key.
+func GroupFileCounters(fcs []*FileCounter, keyFn GroupKeyFunc) []*Group {
+	groups := map[string]*Group{}
+	for _, fc := range fcs {
+		seen := map[string]bool{}
+		for _, key := range keyFn(fc) {
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+
+			group, ok := groups[key]
+			if !ok {
+				group = &Group{Key: key}
+				groups[key] = group
+			}
+			group.add(fc.Stats)
+		}
+	}
+
+	result := make([]*Group, 0, len(groups))
+	for _, group := range groups {
+		result = append(result, group)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Key < result[j].Key
+	})
+	return result
+}
+
+func (g *Group) add(s *Stats) {
+	g.Files++
+	g.Stats.add(s)
+}
+
+func (g *Group) toRow() Row {
+	return append(Row{g.Key, g.Files}, g.Stats.ToRow()...)
+}
+
+// Groups aggregates the files selected by the query
+func (dc *DirCounter) Groups(by GroupBy) []*Group {
+	if by.key == nil {
+		return GroupFileCounters(dc.FilteredFileCounters(), groupByAll)
+	}
+	return GroupFileCounters(dc.FilteredFileCounters(), by.key(ToAbsolutePath(dc.dirname)))
+}
+
+// groupTotal sums all selected files once, so files in several groups are not counted twice
+func (dc *DirCounter) groupTotal() *Group {
+	total := &Group{Key: T(MsgTotal)}
+	for _, fc := range dc.FilteredFileCounters() {
+		total.add(fc.Stats)
+	}
+	return total
+}
+
+// groupHeaderAndRows returns the group rows, with the total if enabled
+func (dc *DirCounter) groupHeaderAndRows(by GroupBy) []Row {
+	data := []Row{append(Row{T(MsgHeaderGroup), T(MsgHeaderFiles)}, (&Stats{}).Header()...)}
+	for _, group := range dc.Groups(by) {
+		data = append(data, group.toRow())
+	}
+	if dc.withTotal {
+		data = append(data, dc.groupTotal().toRow())
+	}
+	return data
+}
+
+// ExportGroupTable renders the groups as a table
+func (dc *DirCounter) ExportGroupTable(by GroupBy) string {
+	return exportToTable(dc.groupHeaderAndRows(by))
+}
+
+// ExportGroupCSV exports the groups as CSV
+func (dc *DirCounter) ExportGroupCSV(by GroupBy, filename ...string) (string, error) {
+	return exportToCSV(dc.groupHeaderAndRows(by), filename...)
+}
+
+// ExportGroupExcel exports the groups to Excel
+func (dc *DirCounter) ExportGroupExcel(by GroupBy, filename ...string) error {
+	return exportToExcel(dc.groupHeaderAndRows(by), filename...)
+}
+
+// ExportGroupJSON exports the groups as a GroupReport
+func (dc *DirCounter) ExportGroupJSON(by GroupBy, filename ...string) (string, error) {
+	report := &GroupReport{By: by.String(), Groups: dc.Groups(by)}
+	if dc.withTotal {
+		report.Total = dc.groupTotal()
+	}
+	return exportToJSON(report, filename...)
+}
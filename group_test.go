@@ -0,0 +1,207 @@
+package wordcounter_test
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	wcg "github.com/100gle/wordcounter"
+)
+
+func createGroupDir(t *testing.T) string {
+	t.Helper()
+
+	dir := t.TempDir()
+	files := map[string]string{
+		"index.md":         "---\ntitle: 首页\ntags: [news]\n---\n首页",
+		"posts/a.md":       "---\ntitle: A\ntags:\n  - go\n  - news\n---\n文章",
+		"posts/b.txt":      "纯文本",
+		"drafts/c.md":      "---\ndraft: true\n---\n草稿内容",
+		"drafts/README":    "readme",
+		"drafts/broken.md": "---\ntags: [unclosed\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("Failed to create directory: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write test file: %v", err)
+		}
+	}
+	return dir
+}
+
+func TestParseGroupBy(t *testing.T) {
+	tests := []struct {
+		spec    string
+		wantErr bool
+	}{
+		{spec: "ext"},
+		{spec: "dir"},
+		{spec: "meta:tags"},
+		{spec: "meta:", wantErr: true},
+		{spec: "size", wantErr: true},
+		{spec: "", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			got, err := wcg.ParseGroupBy(tt.spec)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseGroupBy() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && got.String() != tt.spec {
+				t.Errorf("GroupBy.String() = %q, want %q", got.String(), tt.spec)
+			}
+		})
+	}
+}
+
+func TestDirCounter_Groups(t *testing.T) {
+	dir := createGroupDir(t)
+	dc := wcg.NewDirCounter(dir)
+	if err := dc.Count(); err != nil {
+		t.Fatalf("DirCounter.Count() error = %v", err)
+	}
+
+	tests := []struct {
+		spec      string
+		wantKeys  []string
+		wantFiles []int
+	}{
+		{spec: "ext", wantKeys: []string{"(none)", ".md", ".txt"}, wantFiles: []int{1, 4, 1}},
+		{spec: "dir", wantKeys: []string{".", "drafts", "posts"}, wantFiles: []int{1, 3, 2}},
+		{spec: "meta:tags", wantKeys: []string{"(none)", "go", "news"}, wantFiles: []int{4, 1, 2}},
+		{spec: "meta:draft", wantKeys: []string{"(none)", "true"}, wantFiles: []int{5, 1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			by, err := wcg.ParseGroupBy(tt.spec)
+			if err != nil {
+				t.Fatalf("ParseGroupBy() error = %v", err)
+			}
+
+			var keys []string
+			var files []int
+			for _, group := range dc.Groups(by) {
+				keys = append(keys, group.Key)
+				files = append(files, group.Files)
+			}
+			if !reflect.DeepEqual(keys, tt.wantKeys) || !reflect.DeepEqual(files, tt.wantFiles) {
+				t.Errorf("DirCounter.Groups() = %v %v, want %v %v", keys, files, tt.wantKeys, tt.wantFiles)
+			}
+		})
+	}
+
+	t.Run("zero value", func(t *testing.T) {
+		groups := dc.Groups(wcg.GroupBy{})
+		if len(groups) != 1 || groups[0].Key != wcg.GroupAll || groups[0].Files != 6 {
+			t.Errorf("DirCounter.Groups(GroupBy{}) = %+v, want a single group of all files", groups)
+		}
+	})
+}
+
+func TestGroupFileCounters(t *testing.T) {
+	dir := createGroupDir(t)
+	dc := wcg.NewDirCounter(dir)
+	if err := dc.Count(); err != nil {
+		t.Fatalf("DirCounter.Count() error = %v", err)
+	}
+
+	groups := wcg.GroupFileCounters(dc.GetFileCounters(), func(fc *wcg.FileCounter) []string {
+		if fc.ChineseChars > 0 {
+			return []string{"chinese", "chinese"}
+		}
+		return []string{"other"}
+	})
+	if len(groups) != 2 || groups[0].Key != "chinese" || groups[0].Files != 4 || groups[1].Files != 2 {
+		t.Errorf("GroupFileCounters() = %+v %+v", groups[0], groups[1])
+	}
+}
+
+func TestDirCounter_ExportGroups(t *testing.T) {
+	dir := createGroupDir(t)
+	dc := wcg.NewDirCounter(dir)
+	dc.EnableTotal()
+	if err := dc.Count(); err != nil {
+		t.Fatalf("DirCounter.Count() error = %v", err)
+	}
+	by, _ := wcg.ParseGroupBy("meta:tags")
+
+	table := dc.ExportGroupTable(by)
+	for _, want := range []string{"GROUP", "news", "Total"} {
+		if !strings.Contains(table, want) {
+			t.Errorf("DirCounter.ExportGroupTable() = \n%s\nwant it to contain %q", table, want)
+		}
+	}
+
+	csvData, err := dc.ExportGroupCSV(by)
+	if err != nil {
+		t.Fatalf("DirCounter.ExportGroupCSV() error = %v", err)
+	}
+	if !strings.HasPrefix(csvData, "Group,Files,Lines") || !strings.Contains(csvData, "\nTotal,6,") {
+		t.Errorf("DirCounter.ExportGroupCSV() = \n%s", csvData)
+	}
+
+	jsonData, err := dc.ExportGroupJSON(by)
+	if err != nil {
+		t.Fatalf("DirCounter.ExportGroupJSON() error = %v", err)
+	}
+	var report wcg.GroupReport
+	if err := json.Unmarshal([]byte(jsonData), &report); err != nil {
+		t.Fatalf("Failed to parse group JSON: %v", err)
+	}
+	if report.By != "meta:tags" || len(report.Groups) != 3 || report.Total == nil || report.Total.Files != 6 {
+		t.Errorf("DirCounter.ExportGroupJSON() = %s", jsonData)
+	}
+
+	if err := dc.ExportGroupExcel(by, filepath.Join(t.TempDir(), "groups.xlsx")); err != nil {
+		t.Errorf("DirCounter.ExportGroupExcel() error = %v", err)
+	}
+}
+
+func TestFileCounter_Metadata(t *testing.T) {
+	dir := createGroupDir(t)
+
+	tests := []struct {
+		name string
+		file string
+		want map[string]any
+	}{
+		{name: "Front matter", file: "posts/a.md", want: map[string]any{"title": "A", "tags": []any{"go", "news"}}},
+		{name: "No front matter", file: "posts/b.txt", want: nil},
+		{name: "Unclosed front matter", file: "drafts/broken.md", want: nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fc := wcg.NewFileCounter(filepath.Join(dir, tt.file))
+			if err := fc.Count(); err != nil {
+				t.Fatalf("FileCounter.Count() error = %v", err)
+			}
+			if got := fc.Metadata(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("FileCounter.Metadata() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	t.Run("Recount", func(t *testing.T) {
+		path := filepath.Join(dir, "posts", "a.md")
+		fc := wcg.NewFileCounter(path)
+		if err := fc.Count(); err != nil {
+			t.Fatalf("FileCounter.Count() error = %v", err)
+		}
+		fc.Metadata()
+		if err := os.WriteFile(path, []byte("---\ntitle: B\n---\n文章"), 0644); err != nil {
+			t.Fatalf("Failed to write test file: %v", err)
+		}
+		if err := fc.Count(); err != nil {
+			t.Fatalf("FileCounter.Count() error = %v", err)
+		}
+		if got, want := fc.Metadata(), map[string]any{"title": "B"}; !reflect.DeepEqual(got, want) {
+			t.Errorf("FileCounter.Metadata() after a recount = %v, want %v", got, want)
+		}
+	})
+}
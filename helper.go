@@ -3,6 +3,7 @@ package wordcounter
 import (
 	"fmt"
 	"path/filepath"
+	"strings"
 )
 
 // ToAbsolutePath detects if a path is absolute or not. If not, it converts path to absolute.
@@ -58,26 +59,32 @@ func convertToSliceOfString(input [][]interface{}) [][]string {
 	return result
 }
 
-func getTotal(fcs []*FileCounter) Row {
-	totalLines := 0
-	totalChineseChars := 0
-	totalNonChineseChars := 0
-	totalChars := 0
+// getTotal returns the total row of the given columns, labelled in the file column.
+// grandTotal is the number of characters percentages are relative to.
+func getTotal(fcs []*FileCounter, columns []string, grandTotal int) Row {
+	total := &columnInput{path: T(MsgTotal)}
 
 	for _, fc := range fcs {
-		totalLines += fc.Lines
-		totalChineseChars += fc.ChineseChars
-		totalNonChineseChars += fc.NonChineseChars
-		totalChars += fc.TotalChars
+		total.stats.Lines += fc.Lines
+		total.stats.ChineseChars += fc.ChineseChars
+		total.stats.NonChineseChars += fc.NonChineseChars
+		total.stats.TotalChars += fc.TotalChars
+		total.size += fc.size
+		if fc.modTime.After(total.modTime) {
+			total.modTime = fc.modTime
+		}
 	}
+	total.grandTotal = grandTotal
 
-	row := Row{
-		"Total",
-		totalLines,
-		totalChineseChars,
-		totalNonChineseChars,
-		totalChars,
-	}
+	return columnRow(columns, total)
+}
 
-	return row
+// matchPathPattern reports whether a slash separated path relative to the counted directory
+// matches the glob. Like ignore patterns, a pattern without separator matches the base name.
+func matchPathPattern(pattern, relPath string) bool {
+	if !strings.Contains(pattern, "/") {
+		relPath = filepath.Base(relPath)
+	}
+	matched, _ := filepath.Match(filepath.FromSlash(pattern), filepath.FromSlash(relPath))
+	return matched
 }
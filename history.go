@@ -0,0 +1,305 @@
+package wordcounter
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// History periods
+const (
+	HistoryPeriodDay  = "day"
+	HistoryPeriodWeek = "week"
+)
+
+// History report views
+const (
+	HistoryViewPeriods = "periods"
+	HistoryViewFiles   = "files"
+)
+
+// HistoryRecord is the result of one counting run as stored in the history file
+type HistoryRecord struct {
+	Timestamp time.Time        `json:"timestamp"`
+	Root      string           `json:"root"`
+	Files     map[string]Stats `json:"files"` // Keyed by slash separated path relative to Root
+	Total     Stats            `json:"total"`
+}
+
+// NewHistoryRecord creates a record of all files counted by the DirCounter
+func NewHistoryRecord(dc *DirCounter) *HistoryRecord {
+	snapshot := NewSnapshot(dc)
+	return &HistoryRecord{
+		Timestamp: time.Now(),
+		Root:      snapshot.Root,
+		Files:     snapshot.Files,
+		Total:     snapshot.Total,
+	}
+}
+
+// HistoryFilePath returns the default history file of a counted directory
+func HistoryFilePath(dirname string) string {
+	return filepath.Join(ToAbsolutePath(dirname), DataDirName, HistoryFileName)
+}
+
+// AppendHistory appends the record to a JSON-lines history file, creating it if needed
+func AppendHistory(path string, record *HistoryRecord) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return NewFileWriteError(path, err)
+	}
+
+	data, err := json.Marshal(record)
+	if err != nil {
+		return NewFileWriteError(path, err)
+	}
+
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return NewFileWriteError(path, err)
+	}
+	defer file.Close()
+
+	if _, err := file.Write(append(data, '\n')); err != nil {
+		return NewFileWriteError(path, err)
+	}
+	return nil
+}
+
+// LoadHistory reads all records of a history file sorted by time.
+// A missing history file is not an error and yields no records.
+func LoadHistory(path string) ([]*HistoryRecord, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, NewFileReadError(path, err)
+	}
+	defer file.Close()
+
+	var records []*HistoryRecord
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 64*1024*1024)
+	line := 0
+	for scanner.Scan() {
+		line++
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		record := &HistoryRecord{}
+		if err := json.Unmarshal(scanner.Bytes(), record); err != nil {
+			return nil, NewFileReadError(path, err).WithContext("line", line)
+		}
+		records = append(records, record)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, NewFileReadError(path, err)
+	}
+
+	sort.SliceStable(records, func(i, j int) bool {
+		return records[i].Timestamp.Before(records[j].Timestamp)
+	})
+	return records, nil
+}
+
+// PeriodDelta is the state at the end of a day or week and its change to the previous one
+type PeriodDelta struct {
+	Period string `json:"period"`
+	Files  int    `json:"files"`
+	Total  Stats  `json:"total"`
+	Delta  Stats  `json:"delta"`
+}
+
+// FileGrowth is the change of one file between the first and the last record
+type FileGrowth struct {
+	File  string `json:"file"`
+	Start Stats  `json:"start"`
+	End   Stats  `json:"end"`
+	Delta Stats  `json:"delta"`
+}
+
+// HistoryReport summarizes the progress recorded in the history
+type HistoryReport struct {
+	Period        string         `json:"period"`
+	Periods       []*PeriodDelta `json:"periods"`
+	Files         []*FileGrowth  `json:"files"`
+	CurrentStreak int            `json:"current_streak"` // Consecutive days up to today or yesterday with more characters
+	LongestStreak int            `json:"longest_streak"`
+}
+
+// NewHistoryReport builds a report of the records, which must be sorted by time.
+// The change of the first period is relative to the first record in it.
+func NewHistoryReport(records []*HistoryRecord, period string) (*HistoryReport, error) {
+	return newHistoryReport(records, period, time.Now())
+}
+
+func newHistoryReport(records []*HistoryRecord, period string, now time.Time) (*HistoryReport, error) {
+	if period != HistoryPeriodDay && period != HistoryPeriodWeek {
+		return nil, NewInvalidInputError(fmt.Sprintf("unsupported history period: %s, supported periods: %s, %s",
+			period, HistoryPeriodDay, HistoryPeriodWeek)).WithContext("period", period)
+	}
+
+	report := &HistoryReport{
+		Period:  period,
+		Periods: periodDeltas(records, period),
+		Files:   fileGrowth(records),
+	}
+	report.CurrentStreak, report.LongestStreak = streaks(periodDeltas(records, HistoryPeriodDay), now)
+	return report, nil
+}
+
+func periodKey(t time.Time, period string) string {
+	t = t.Local()
+	if period == HistoryPeriodWeek {
+		year, week := t.ISOWeek()
+		return fmt.Sprintf("%d-W%02d", year, week)
+	}
+	return t.Format("2006-01-02")
+}
+
+// periodDeltas keeps the last record of every period and subtracts the previous one
+func periodDeltas(records []*HistoryRecord, period string) []*PeriodDelta {
+	var deltas []*PeriodDelta
+	var previous Stats
+	for i, record := range records {
+		key := periodKey(record.Timestamp, period)
+		if len(deltas) == 0 || deltas[len(deltas)-1].Period != key {
+			if len(deltas) == 0 {
+				previous = records[i].Total
+			} else {
+				previous = deltas[len(deltas)-1].Total
+			}
+			deltas = append(deltas, &PeriodDelta{Period: key})
+		}
+
+		current := deltas[len(deltas)-1]
+		current.Files = len(record.Files)
+		current.Total = record.Total
+		current.Delta = record.Total.sub(&previous)
+	}
+	return deltas
+}
+
+// fileGrowth compares every file of the first and the last record, sorted by path
+func fileGrowth(records []*HistoryRecord) []*FileGrowth {
+	if len(records) == 0 {
+		return nil
+	}
+	first, last := records[0], records[len(records)-1]
+
+	files := map[string]*FileGrowth{}
+	for path, stats := range first.Files {
+		files[path] = &FileGrowth{File: path, Start: stats}
+	}
+	for path, stats := range last.Files {
+		if _, ok := files[path]; !ok {
+			files[path] = &FileGrowth{File: path}
+		}
+		files[path].End = stats
+	}
+
+	result := make([]*FileGrowth, 0, len(files))
+	for _, growth := range files {
+		growth.Delta = growth.End.sub(&growth.Start)
+		result = append(result, growth)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].File < result[j].File
+	})
+	return result
+}
+
+// streaks counts runs of consecutive days on which the total characters grew
+func streaks(days []*PeriodDelta, now time.Time) (current int, longest int) {
+	run := 0
+	var previousDay time.Time
+	for _, day := range days {
+		date, _ := time.ParseInLocation("2006-01-02", day.Period, time.Local)
+		switch {
+		case day.Delta.TotalChars <= 0:
+			run = 0
+		case run > 0 && date.Sub(previousDay) <= 24*time.Hour+time.Hour: // tolerate DST changes
+			run++
+		default:
+			run = 1
+		}
+		previousDay = date
+		if run > longest {
+			longest = run
+		}
+	}
+
+	if len(days) > 0 {
+		today := periodKey(now, HistoryPeriodDay)
+		yesterday := periodKey(now.AddDate(0, 0, -1), HistoryPeriodDay)
+		if last := days[len(days)-1].Period; last == today || last == yesterday {
+			current = run
+		}
+	}
+	return current, longest
+}
+
+// headerAndRows returns the table of the given view
+func (r *HistoryReport) headerAndRows(view string) ([]Row, error) {
+	statsHeader := (&Stats{}).Header()
+	deltaHeader := make(Row, 0, len(statsHeader))
+	for _, h := range statsHeader {
+		deltaHeader = append(deltaHeader, T(MsgHeaderDelta, h))
+	}
+
+	switch view {
+	case HistoryViewPeriods:
+		header := append(append(Row{T(MsgHeaderPeriod), T(MsgHeaderFiles)}, statsHeader...), deltaHeader...)
+		data := []Row{header}
+		for _, p := range r.Periods {
+			data = append(data, append(append(Row{p.Period, p.Files}, p.Total.ToRow()...), p.Delta.ToRow()...))
+		}
+		return data, nil
+	case HistoryViewFiles:
+		header := append(append(Row{T(MsgHeaderFile)}, statsHeader...), deltaHeader...)
+		data := []Row{header}
+		for _, f := range r.Files {
+			data = append(data, append(append(Row{f.File}, f.End.ToRow()...), f.Delta.ToRow()...))
+		}
+		return data, nil
+	default:
+		return nil, NewInvalidInputError(fmt.Sprintf("unsupported history view: %s, supported views: %s, %s",
+			view, HistoryViewPeriods, HistoryViewFiles)).WithContext("view", view)
+	}
+}
+
+// ExportTable renders the view as a table, followed by the streaks
+func (r *HistoryReport) ExportTable(view string) (string, error) {
+	data, err := r.headerAndRows(view)
+	if err != nil {
+		return "", err
+	}
+	return exportToTable(data) + "\n" + T(MsgHistoryStreak, r.CurrentStreak, r.LongestStreak), nil
+}
+
+// ExportCSV exports the view as CSV
+func (r *HistoryReport) ExportCSV(view string, filename ...string) (string, error) {
+	data, err := r.headerAndRows(view)
+	if err != nil {
+		return "", err
+	}
+	return exportToCSV(data, filename...)
+}
+
+// ExportExcel exports the view to Excel
+func (r *HistoryReport) ExportExcel(view string, filename ...string) error {
+	data, err := r.headerAndRows(view)
+	if err != nil {
+		return err
+	}
+	return exportToExcel(data, filename...)
+}
+
+// ExportJSON exports the whole report as JSON
+func (r *HistoryReport) ExportJSON(filename ...string) (string, error) {
+	return exportToJSON(r, filename...)
+}
@@ -0,0 +1,198 @@
+package wordcounter_test
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	wcg "github.com/100gle/wordcounter"
+)
+
+// historyRecord creates a record with one stats entry per file, days ago from now
+func historyRecord(daysAgo int, files map[string]int) *wcg.HistoryRecord {
+	record := &wcg.HistoryRecord{
+		Timestamp: time.Now().AddDate(0, 0, -daysAgo),
+		Root:      "/book",
+		Files:     map[string]wcg.Stats{},
+	}
+	for file, chars := range files {
+		record.Files[file] = wcg.Stats{Lines: 1, ChineseChars: chars, TotalChars: chars}
+		record.Total.Lines++
+		record.Total.ChineseChars += chars
+		record.Total.TotalChars += chars
+	}
+	return record
+}
+
+func TestAppendAndLoadHistory(t *testing.T) {
+	dir := createBookDir(t)
+	path := wcg.HistoryFilePath(dir)
+	if path != filepath.Join(dir, ".wcg", "history.jsonl") {
+		t.Errorf("HistoryFilePath() = %s", path)
+	}
+
+	records, err := wcg.LoadHistory(path)
+	if err != nil || len(records) != 0 {
+		t.Fatalf("LoadHistory() of missing file = %v, %v, want no records", records, err)
+	}
+
+	dc := wcg.NewDirCounter(dir)
+	if err := dc.Count(); err != nil {
+		t.Fatalf("DirCounter.Count() error = %v", err)
+	}
+	older := wcg.NewHistoryRecord(dc)
+	older.Timestamp = older.Timestamp.Add(-time.Hour)
+	newer := wcg.NewHistoryRecord(dc)
+
+	// Records are sorted by time when loaded, regardless of the order they were appended
+	for _, record := range []*wcg.HistoryRecord{newer, older} {
+		if err := wcg.AppendHistory(path, record); err != nil {
+			t.Fatalf("AppendHistory() error = %v", err)
+		}
+	}
+
+	records, err = wcg.LoadHistory(path)
+	if err != nil {
+		t.Fatalf("LoadHistory() error = %v", err)
+	}
+	if len(records) != 2 || !records[0].Timestamp.Before(records[1].Timestamp) {
+		t.Fatalf("LoadHistory() = %v, want 2 records sorted by time", records)
+	}
+	if got := records[1].Files["part1/sec/ch02.md"].ChineseChars; got != 3 {
+		t.Errorf("LoadHistory() part1/sec/ch02.md chinese chars = %d, want 3", got)
+	}
+	if records[1].Total.TotalChars != 18 || len(records[1].Files) != 5 {
+		t.Errorf("LoadHistory() total = %+v with %d files", records[1].Total, len(records[1].Files))
+	}
+
+	if err := os.WriteFile(path, []byte("{not json}\n"), 0644); err != nil {
+		t.Fatalf("Failed to write history: %v", err)
+	}
+	if _, err := wcg.LoadHistory(path); err == nil {
+		t.Errorf("LoadHistory() expected error for malformed history")
+	}
+}
+
+func TestNewHistoryReport(t *testing.T) {
+	records := []*wcg.HistoryRecord{
+		historyRecord(5, map[string]int{"a.md": 100}),
+		historyRecord(5, map[string]int{"a.md": 150}),
+		historyRecord(3, map[string]int{"a.md": 200}),
+		historyRecord(2, map[string]int{"a.md": 250, "b.md": 10}),
+		historyRecord(1, map[string]int{"a.md": 240, "b.md": 10}),
+		historyRecord(0, map[string]int{"b.md": 80}),
+	}
+	// Make sure the two records of the first day stay on the same day
+	records[1].Timestamp = records[0].Timestamp
+
+	report, err := wcg.NewHistoryReport(records, wcg.HistoryPeriodDay)
+	if err != nil {
+		t.Fatalf("NewHistoryReport() error = %v", err)
+	}
+
+	wantDeltas := []int{50, 50, 60, -10, -170}
+	if len(report.Periods) != 5 {
+		t.Fatalf("HistoryReport.Periods = %d periods, want 5", len(report.Periods))
+	}
+	for i, want := range wantDeltas {
+		if got := report.Periods[i].Delta.TotalChars; got != want {
+			t.Errorf("HistoryReport.Periods[%d].Delta.TotalChars = %d, want %d", i, got, want)
+		}
+	}
+	if got := report.Periods[4].Total.TotalChars; got != 80 {
+		t.Errorf("HistoryReport.Periods[4].Total.TotalChars = %d, want 80", got)
+	}
+
+	// Days 3 and 2 grew consecutively, day 1 shrank and today shrank too
+	if report.LongestStreak != 2 || report.CurrentStreak != 0 {
+		t.Errorf("HistoryReport streaks = %d current, %d longest, want 0, 2", report.CurrentStreak, report.LongestStreak)
+	}
+
+	if len(report.Files) != 2 || report.Files[0].File != "a.md" || report.Files[0].Delta.TotalChars != -100 ||
+		report.Files[1].Start.TotalChars != 0 || report.Files[1].End.TotalChars != 80 {
+		t.Errorf("HistoryReport.Files = %+v %+v", report.Files[0], report.Files[1])
+	}
+
+	weekly, err := wcg.NewHistoryReport(records, wcg.HistoryPeriodWeek)
+	if err != nil {
+		t.Fatalf("NewHistoryReport() error = %v", err)
+	}
+	if len(weekly.Periods) < 1 || len(weekly.Periods) > 2 || !strings.Contains(weekly.Periods[0].Period, "-W") {
+		t.Errorf("HistoryReport.Periods by week = %+v", weekly.Periods)
+	}
+
+	if _, err := wcg.NewHistoryReport(records, "month"); err == nil {
+		t.Errorf("NewHistoryReport() expected error for unsupported period")
+	}
+}
+
+func TestHistoryReport_CurrentStreak(t *testing.T) {
+	records := []*wcg.HistoryRecord{
+		historyRecord(3, map[string]int{"a.md": 10}),
+		historyRecord(2, map[string]int{"a.md": 20}),
+		historyRecord(1, map[string]int{"a.md": 30}),
+		historyRecord(0, map[string]int{"a.md": 40}),
+	}
+	report, err := wcg.NewHistoryReport(records, wcg.HistoryPeriodDay)
+	if err != nil {
+		t.Fatalf("NewHistoryReport() error = %v", err)
+	}
+	// The first day has no previous record to grow from
+	if report.CurrentStreak != 3 || report.LongestStreak != 3 {
+		t.Errorf("HistoryReport streaks = %d current, %d longest, want 3, 3", report.CurrentStreak, report.LongestStreak)
+	}
+}
+
+func TestHistoryReport_Export(t *testing.T) {
+	records := []*wcg.HistoryRecord{
+		historyRecord(1, map[string]int{"a.md": 10}),
+		historyRecord(0, map[string]int{"a.md": 30}),
+	}
+	report, err := wcg.NewHistoryReport(records, wcg.HistoryPeriodDay)
+	if err != nil {
+		t.Fatalf("NewHistoryReport() error = %v", err)
+	}
+
+	table, err := report.ExportTable(wcg.HistoryViewPeriods)
+	if err != nil || !strings.Contains(table, "ΔTOTALCHARS") || !strings.Contains(table, "Current streak: 1 days") {
+		t.Errorf("HistoryReport.ExportTable() = %s, %v", table, err)
+	}
+	if _, err := report.ExportTable("unknown"); err == nil {
+		t.Errorf("HistoryReport.ExportTable() expected error for unknown view")
+	}
+
+	csvData, err := report.ExportCSV(wcg.HistoryViewFiles)
+	if err != nil || csvData != "File,Lines,ChineseChars,NonChineseChars,TotalChars,ΔLines,ΔChineseChars,ΔNonChineseChars,ΔTotalChars\na.md,1,30,0,30,0,20,0,20" {
+		t.Errorf("HistoryReport.ExportCSV() = %q, %v", csvData, err)
+	}
+
+	jsonData, err := report.ExportJSON()
+	if err != nil {
+		t.Fatalf("HistoryReport.ExportJSON() error = %v", err)
+	}
+	var decoded wcg.HistoryReport
+	if err := json.Unmarshal([]byte(jsonData), &decoded); err != nil || len(decoded.Periods) != 2 || decoded.CurrentStreak != 1 {
+		t.Errorf("HistoryReport.ExportJSON() = %s, %v", jsonData, err)
+	}
+
+	if err := report.ExportExcel(wcg.HistoryViewPeriods, filepath.Join(t.TempDir(), "history.xlsx")); err != nil {
+		t.Errorf("HistoryReport.ExportExcel() error = %v", err)
+	}
+
+	defer wcg.SetLanguage(wcg.LangEnglish)
+	for lang, want := range map[string]string{
+		wcg.LangSimplifiedChinese:  "文件,行数,中文字数,非中文字数,总字数,行数变化,中文字数变化,非中文字数变化,总字数变化\n",
+		wcg.LangTraditionalChinese: "檔案,行數,中文字數,非中文字數,總字數,行數變化,中文字數變化,非中文字數變化,總字數變化\n",
+	} {
+		if err := wcg.SetLanguage(lang); err != nil {
+			t.Fatalf("SetLanguage() error = %v", err)
+		}
+		csvData, err := report.ExportCSV(wcg.HistoryViewFiles)
+		if err != nil || !strings.HasPrefix(csvData, want) {
+			t.Errorf("HistoryReport.ExportCSV() in %s = %q, %v, want header %q", lang, csvData, err, want)
+		}
+	}
+}
@@ -0,0 +1,107 @@
+package wordcounter
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+)
+
+// ErrorResponse is the body of every error response of the server
+type ErrorResponse struct {
+	Msg     string         `json:"msg"`               // Summary in the request language
+	Error   string         `json:"error"`             // What went wrong
+	Code    string         `json:"code"`              // Stable machine-readable code, see ErrorType.Code
+	Context map[string]any `json:"context,omitempty"` // Details such as the invalid field
+}
+
+// errorStatuses are the HTTP statuses of the error types caused by the request, the others are 500
+var errorStatuses = map[ErrorType]int{
+	ErrorTypeFileNotFound:    http.StatusNotFound,
+	ErrorTypeInvalidInput:    http.StatusUnprocessableEntity,
+	ErrorTypeInvalidPath:     http.StatusUnprocessableEntity,
+	ErrorTypePatternMatch:    http.StatusUnprocessableEntity,
+	ErrorTypeRequestTooLarge: http.StatusRequestEntityTooLarge,
+	ErrorTypeUnauthorized:    http.StatusUnauthorized,
+	ErrorTypeRateLimited:     http.StatusTooManyRequests,
+}
+
+// ErrorStatus returns the HTTP status of the responses to errors of the type
+func ErrorStatus(t ErrorType) int {
+	if status, ok := errorStatuses[t]; ok {
+		return status
+	}
+	return http.StatusInternalServerError
+}
+
+// respondError writes the error envelope of err, summarized by msg, with the status of its type.
+// Errors other than WordCounterError are server errors, only detailed in debug mode.
+func respondError(c echo.Context, msg MessageKey, err error) error {
+	var wcErr *WordCounterError
+	if !errors.As(err, &wcErr) {
+		wcErr = NewServerError(err.Error(), nil)
+	}
+	c.Set(errorCodeKey, wcErr.Type.Code())
+	status := ErrorStatus(wcErr.Type)
+	response := ErrorResponse{
+		Msg:     Translate(requestLanguage(c), msg),
+		Error:   wcErr.Error(),
+		Code:    wcErr.Type.Code(),
+		Context: wcErr.Context,
+	}
+	hideInternalError(c, status, &response, err)
+	return c.JSON(status, response)
+}
+
+// hideInternalError logs a server error and replaces its details with the status text,
+// unless the server is in debug mode
+func hideInternalError(c echo.Context, status int, response *ErrorResponse, err error) {
+	if status < http.StatusInternalServerError {
+		return
+	}
+	c.Echo().Logger.Error(err)
+	if !c.Echo().Debug {
+		response.Error, response.Context = http.StatusText(status), nil
+	}
+}
+
+// HTTPErrorHandler writes the errors returned by handlers and middleware, such as unknown
+// routes, as an ErrorResponse. Internal errors are only detailed in debug mode.
+func (s *WordCounterServer) HTTPErrorHandler(err error, c echo.Context) {
+	if c.Response().Committed {
+		return
+	}
+
+	var wcErr *WordCounterError
+	var httpErr *echo.HTTPError
+	response := ErrorResponse{Msg: Translate(requestLanguage(c), MsgRequestFailed)}
+	status := http.StatusInternalServerError
+	switch {
+	case errors.As(err, &wcErr):
+		status = ErrorStatus(wcErr.Type)
+		response.Error, response.Code, response.Context = wcErr.Error(), wcErr.Type.Code(), wcErr.Context
+	case errors.As(err, &httpErr):
+		status = httpErr.Code
+		response.Error, response.Code = fmt.Sprint(httpErr.Message), statusCode(status)
+	default:
+		response.Error, response.Code = http.StatusText(status), ErrorTypeServer.Code()
+	}
+	c.Set(errorCodeKey, response.Code)
+	hideInternalError(c, status, &response, err)
+
+	if c.Request().Method == http.MethodHead {
+		err = c.NoContent(status)
+	} else {
+		err = c.JSON(status, response)
+	}
+	if err != nil {
+		s.Echo.Logger.Error(err)
+	}
+}
+
+// statusCode returns the code of an HTTP status without error type, e.g. "method_not_allowed"
+func statusCode(status int) string {
+	return strings.ReplaceAll(strings.ToLower(http.StatusText(status)), " ", "_")
+}
@@ -0,0 +1,80 @@
+package wordcounter_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	wcg "github.com/100gle/wordcounter"
+	"github.com/gavv/httpexpect/v2"
+	"github.com/labstack/echo/v4"
+)
+
+func TestErrorStatus(t *testing.T) {
+	tests := []struct {
+		errorType wcg.ErrorType
+		want      int
+	}{
+		{wcg.ErrorTypeInvalidInput, http.StatusUnprocessableEntity},
+		{wcg.ErrorTypeFileNotFound, http.StatusNotFound},
+		{wcg.ErrorTypeRequestTooLarge, http.StatusRequestEntityTooLarge},
+		{wcg.ErrorTypeExport, http.StatusInternalServerError},
+		{wcg.ErrorTypeServer, http.StatusInternalServerError},
+	}
+	for _, tt := range tests {
+		if got := wcg.ErrorStatus(tt.errorType); got != tt.want {
+			t.Errorf("ErrorStatus(%s) = %d, want %d", tt.errorType.Code(), got, tt.want)
+		}
+	}
+}
+
+// TestWordCounterServer_HTTPErrorHandler tests that errors of every route get the error envelope
+func TestWordCounterServer_HTTPErrorHandler(t *testing.T) {
+	server := wcg.NewWordCounterServer()
+	server.Config.MaxBodySize = 8
+	server.Echo.GET("/missing", func(c echo.Context) error {
+		return wcg.NewFileNotFoundError("a.md", nil)
+	})
+	server.Echo.GET("/failing", func(c echo.Context) error {
+		return errors.New("database password is hunter2")
+	})
+	testServer := httptest.NewServer(server.Echo)
+	defer testServer.Close()
+
+	e := httpexpect.WithConfig(httpexpect.Config{
+		BaseURL:  testServer.URL,
+		Reporter: httpexpect.NewAssertReporter(t),
+	})
+
+	tests := []struct {
+		name    string
+		req     *httpexpect.Request
+		status  int
+		code    string
+		msg     string
+		context map[string]any
+	}{
+		{"unknown route", e.GET("/nowhere"), http.StatusNotFound, "not_found", "request failed", nil},
+		{"method not allowed", e.GET(wcg.CountEndpoint), http.StatusMethodNotAllowed, "method_not_allowed", "request failed", nil},
+		{"word counter error", e.GET("/missing"), http.StatusNotFound, "file_not_found", "request failed", map[string]any{"path": "a.md"}},
+		{"internal error", e.GET("/failing"), http.StatusInternalServerError, "server_error", "request failed", nil},
+		{"body too large", e.POST(wcg.CountEndpoint).WithText("too large body"), http.StatusRequestEntityTooLarge, "request_too_large", "request body too large", map[string]any{"limit": 8}},
+		{"empty body", e.POST(wcg.CountEndpoint), http.StatusUnprocessableEntity, "invalid_input", "parse failed", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			object := tt.req.Expect().
+				Status(tt.status).
+				JSON().Object().
+				HasValue("code", tt.code).
+				HasValue("msg", tt.msg)
+			object.Value("error").String().NotEmpty().NotContains("hunter2")
+			if tt.context == nil {
+				object.NotContainsKey("context")
+			} else {
+				object.HasValue("context", tt.context)
+			}
+		})
+	}
+}
@@ -0,0 +1,501 @@
+package wordcounter
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"sync/atomic"
+)
+
+// Supported languages
+const (
+	LangEnglish            = "en"
+	LangSimplifiedChinese  = "zh-CN"
+	LangTraditionalChinese = "zh-TW"
+)
+
+// MessageKey identifies a translatable message in the catalogs
+type MessageKey string
+
+// Report header messages
+const (
+	MsgHeaderFile            MessageKey = "header.file"
+	MsgHeaderDirectory       MessageKey = "header.directory"
+	MsgHeaderFiles           MessageKey = "header.files"
+	MsgHeaderGroup           MessageKey = "header.group"
+	MsgHeaderPeriod          MessageKey = "header.period"
+	MsgHeaderLines           MessageKey = "header.lines"
+	MsgHeaderChineseChars    MessageKey = "header.chinese_chars"
+	MsgHeaderNonChineseChars MessageKey = "header.non_chinese_chars"
+	MsgHeaderTotalChars      MessageKey = "header.total_chars"
+	MsgHeaderShare           MessageKey = "header.share"
+	MsgHeaderChineseRatio    MessageKey = "header.chinese_ratio"
+	MsgHeaderSize            MessageKey = "header.size"
+	MsgHeaderModified        MessageKey = "header.modified"
+	MsgHeaderReadingTime     MessageKey = "header.reading_time"
+	MsgHeaderWords           MessageKey = "header.words"
+	MsgHeaderGoal            MessageKey = "header.goal"
+	MsgHeaderField           MessageKey = "header.field"
+	MsgHeaderCurrent         MessageKey = "header.current"
+	MsgHeaderTarget          MessageKey = "header.target"
+	MsgHeaderProgress        MessageKey = "header.progress"
+	MsgHeaderRemaining       MessageKey = "header.remaining"
+	MsgHeaderDeadline        MessageKey = "header.deadline"
+	MsgHeaderDailyPace       MessageKey = "header.daily_pace"
+	MsgHeaderRule            MessageKey = "header.rule"
+	MsgHeaderValue           MessageKey = "header.value"
+	MsgHeaderLimit           MessageKey = "header.limit"
+	MsgHeaderStatus          MessageKey = "header.status"
+	MsgHeaderAuthor          MessageKey = "header.author"
+	MsgHeaderDelta           MessageKey = "header.delta"
+	MsgTotal                 MessageKey = "total"
+	MsgReportTitle           MessageKey = "report.title"
+)
+
+// CLI messages
+const (
+	MsgWarnEmptyFile      MessageKey = "warn.empty_file"
+	MsgErrPathRequired    MessageKey = "error.path_required"
+	MsgErrPathEmpty       MessageKey = "error.path_empty"
+	MsgErrInvalidMode     MessageKey = "error.invalid_mode"
+	MsgErrDirNotExist     MessageKey = "error.dir_not_exist"
+	MsgErrFileNotExist    MessageKey = "error.file_not_exist"
+	MsgErrCountDir        MessageKey = "error.count_dir"
+	MsgErrCountFile       MessageKey = "error.count_file"
+	MsgErrExportCSV       MessageKey = "error.export_csv"
+	MsgErrExportExcel     MessageKey = "error.export_excel"
+	MsgErrExportJSON      MessageKey = "error.export_json"
+	MsgErrRenderTemplate  MessageKey = "error.render_template"
+	MsgErrUnsupportedLang MessageKey = "error.unsupported_lang"
+	MsgErrExportType      MessageKey = "error.export_type"
+	MsgErrInvalidColumns  MessageKey = "error.invalid_columns"
+	MsgErrInvalidQuery    MessageKey = "error.invalid_query"
+	MsgErrInvalidGroupBy  MessageKey = "error.invalid_group_by"
+	MsgExcelExported      MessageKey = "info.excel_exported"
+	MsgHistoryStreak      MessageKey = "info.history_streak"
+	MsgHistoryRecorded    MessageKey = "info.history_recorded"
+	MsgErrHistory         MessageKey = "error.history"
+	MsgGoalBelowMinimum   MessageKey = "info.goal_below_minimum"
+	MsgGoalOverdue        MessageKey = "info.goal_overdue"
+	MsgGoalDaysLeft       MessageKey = "info.goal_days_left"
+	MsgGoalDueToday       MessageKey = "info.goal_due_today"
+	MsgGoalDone           MessageKey = "info.goal_done"
+	MsgErrGoal            MessageKey = "error.goal"
+	MsgErrConfig          MessageKey = "error.config"
+	MsgGoalsFailed        MessageKey = "info.goals_failed"
+	MsgNoGoals            MessageKey = "info.no_goals"
+	MsgErrCheck           MessageKey = "error.check"
+	MsgCheckPassed        MessageKey = "info.check_passed"
+	MsgCheckFailed        MessageKey = "info.check_failed"
+	MsgNoRules            MessageKey = "info.no_rules"
+	MsgNoConfigFile       MessageKey = "info.no_config_file"
+	MsgDiffAdded          MessageKey = "info.diff_added"
+	MsgDiffRemoved        MessageKey = "info.diff_removed"
+	MsgDiffModified       MessageKey = "info.diff_modified"
+	MsgErrDiff            MessageKey = "error.diff"
+	MsgErrBlame           MessageKey = "error.blame"
+	MsgWatching           MessageKey = "info.watching"
+	MsgWatchPolling       MessageKey = "info.watch_polling"
+	MsgWatchChanged       MessageKey = "info.watch_changed"
+	MsgWatchDelta         MessageKey = "info.watch_delta"
+	MsgErrWatch           MessageKey = "error.watch"
+	MsgServerListening    MessageKey = "info.server_listening"
+	MsgServerStopped      MessageKey = "info.server_stopped"
+	MsgErrServer          MessageKey = "error.server"
+	MsgCacheStats         MessageKey = "info.cache_stats"
+	MsgWarnCacheSave      MessageKey = "warn.cache_save"
+)
+
+// Server messages
+const (
+	MsgOK               MessageKey = "server.ok"
+	MsgParseFailed      MessageKey = "server.parse_failed"
+	MsgRequestBodyEmpty MessageKey = "server.request_body_empty"
+	MsgNoFilesUploaded  MessageKey = "server.no_files_uploaded"
+	MsgBodyTooLarge     MessageKey = "server.body_too_large"
+	MsgRequestFailed    MessageKey = "server.request_failed"
+	MsgUnauthorized     MessageKey = "server.unauthorized"
+	MsgRateLimited      MessageKey = "server.rate_limited"
+	MsgInvalidOptions   MessageKey = "server.invalid_options"
+)
+
+var catalogs = map[string]map[MessageKey]string{
+	LangEnglish: {
+		MsgHeaderFile:            "File",
+		MsgHeaderDirectory:       "Directory",
+		MsgHeaderFiles:           "Files",
+		MsgHeaderGroup:           "Group",
+		MsgHeaderPeriod:          "Period",
+		MsgHeaderLines:           "Lines",
+		MsgHeaderChineseChars:    "ChineseChars",
+		MsgHeaderNonChineseChars: "NonChineseChars",
+		MsgHeaderTotalChars:      "TotalChars",
+		MsgHeaderShare:           "Share",
+		MsgHeaderChineseRatio:    "ChineseRatio",
+		MsgHeaderSize:            "Size",
+		MsgHeaderModified:        "Modified",
+		MsgHeaderReadingTime:     "ReadingMinutes",
+		MsgHeaderWords:           "Words",
+		MsgHeaderGoal:            "Goal",
+		MsgHeaderField:           "Field",
+		MsgHeaderCurrent:         "Current",
+		MsgHeaderTarget:          "Target",
+		MsgHeaderProgress:        "Progress",
+		MsgHeaderRemaining:       "Remaining",
+		MsgHeaderDeadline:        "Deadline",
+		MsgHeaderDailyPace:       "DailyPace",
+		MsgHeaderRule:            "Rule",
+		MsgHeaderValue:           "Value",
+		MsgHeaderLimit:           "Limit",
+		MsgHeaderStatus:          "Status",
+		MsgHeaderAuthor:          "Author",
+		MsgHeaderDelta:           "Δ%v",
+		MsgTotal:                 "Total",
+		MsgReportTitle:           "Word Count Report",
+
+		MsgWarnEmptyFile:      "Warning: Empty file detected: %s",
+		MsgErrPathRequired:    "Error: path argument is required",
+		MsgErrPathEmpty:       "Error: path cannot be empty",
+		MsgErrInvalidMode:     "Error: Invalid mode. Choose either 'dir' or 'file'",
+		MsgErrDirNotExist:     "Error: Directory does not exist: %s",
+		MsgErrFileNotExist:    "Error: File does not exist: %s",
+		MsgErrCountDir:        "Error counting files in directory: %v",
+		MsgErrCountFile:       "Error counting characters in file: %v",
+		MsgErrExportCSV:       "Error exporting to CSV: %v",
+		MsgErrExportExcel:     "Error exporting to Excel: %v",
+		MsgErrExportJSON:      "Error exporting to JSON: %v",
+		MsgErrRenderTemplate:  "Error rendering template: %v",
+		MsgErrUnsupportedLang: "Error: unsupported language: %s",
+		MsgErrExportType:      "Error: unsupported export type: %s, supported types: %s",
+		MsgErrInvalidColumns:  "Error: invalid columns: %v",
+		MsgErrInvalidQuery:    "Error: invalid query: %v",
+		MsgErrInvalidGroupBy:  "Error: invalid group-by: %v",
+		MsgExcelExported:      "Excel file exported to: %s",
+		MsgHistoryStreak:      "Current streak: %d days, longest streak: %d days",
+		MsgHistoryRecorded:    "Recorded count to history: %s",
+		MsgErrHistory:         "Error reading or writing history: %v",
+		MsgGoalBelowMinimum:   "(below minimum %d)",
+		MsgGoalOverdue:        "(overdue)",
+		MsgGoalDaysLeft:       "(%d days left)",
+		MsgGoalDueToday:       "(due today)",
+		MsgGoalDone:           "(done)",
+		MsgErrGoal:            "Error evaluating goals: %v",
+		MsgErrConfig:          "Error loading configuration: %v",
+		MsgGoalsFailed:        "Some goals are below their minimum",
+		MsgNoGoals:            "No goals defined, add them to %s or use --target",
+		MsgErrCheck:           "Error checking rules: %v",
+		MsgCheckPassed:        "All %d files passed %d rules",
+		MsgCheckFailed:        "%d violations of %d rules in %d files",
+		MsgNoRules:            "No rules defined, add them to the check section of %s or use --rule",
+		MsgNoConfigFile:       "(none)",
+		MsgDiffAdded:          "added",
+		MsgDiffRemoved:        "removed",
+		MsgDiffModified:       "modified",
+		MsgErrDiff:            "Error comparing counts: %v",
+		MsgErrBlame:           "Error attributing lines to authors: %v",
+		MsgWatching:           "Watching %s, press Ctrl+C to stop",
+		MsgWatchPolling:       "Polling for changes every %s",
+		MsgWatchChanged:       "Recounted %d changed paths at %s",
+		MsgWatchDelta:         "Since start: %+d lines, %+d Chinese chars, %+d non-Chinese chars, %+d total chars",
+		MsgErrWatch:           "Error watching directory: %v",
+		MsgServerListening:    "Listening on %s, press Ctrl+C to stop",
+		MsgServerStopped:      "Server stopped",
+		MsgErrServer:          "Error running server: %v",
+		MsgCacheStats:         "Cache: %d hits, %d misses (%.1f%% hit rate)",
+		MsgWarnCacheSave:      "Warning: could not save the cache: %v",
+
+		MsgOK:               "ok",
+		MsgParseFailed:      "parse failed",
+		MsgRequestBodyEmpty: "request body is empty",
+		MsgNoFilesUploaded:  "no files uploaded, send them as multipart/form-data",
+		MsgBodyTooLarge:     "request body too large",
+		MsgRequestFailed:    "request failed",
+		MsgUnauthorized:     "unauthorized",
+		MsgRateLimited:      "too many requests",
+		MsgInvalidOptions:   "invalid options",
+	},
+	LangSimplifiedChinese: {
+		MsgHeaderFile:            "文件",
+		MsgHeaderDirectory:       "目录",
+		MsgHeaderFiles:           "文件数",
+		MsgHeaderGroup:           "分组",
+		MsgHeaderPeriod:          "周期",
+		MsgHeaderLines:           "行数",
+		MsgHeaderChineseChars:    "中文字数",
+		MsgHeaderNonChineseChars: "非中文字数",
+		MsgHeaderTotalChars:      "总字数",
+		MsgHeaderShare:           "占比",
+		MsgHeaderChineseRatio:    "中文占比",
+		MsgHeaderSize:            "文件大小",
+		MsgHeaderModified:        "修改时间",
+		MsgHeaderReadingTime:     "阅读分钟",
+		MsgHeaderWords:           "词数",
+		MsgHeaderGoal:            "目标",
+		MsgHeaderField:           "字段",
+		MsgHeaderCurrent:         "当前",
+		MsgHeaderTarget:          "目标值",
+		MsgHeaderProgress:        "进度",
+		MsgHeaderRemaining:       "剩余",
+		MsgHeaderDeadline:        "截止日期",
+		MsgHeaderDailyPace:       "每日需完成",
+		MsgHeaderRule:            "规则",
+		MsgHeaderValue:           "数值",
+		MsgHeaderLimit:           "限制",
+		MsgHeaderStatus:          "状态",
+		MsgHeaderAuthor:          "作者",
+		MsgHeaderDelta:           "%v变化",
+		MsgTotal:                 "合计",
+		MsgReportTitle:           "字数统计报告",
+
+		MsgWarnEmptyFile:      "警告：检测到空文件：%s",
+		MsgErrPathRequired:    "错误：缺少路径参数",
+		MsgErrPathEmpty:       "错误：路径不能为空",
+		MsgErrInvalidMode:     "错误：无效的模式，请选择 'dir' 或 'file'",
+		MsgErrDirNotExist:     "错误：目录不存在：%s",
+		MsgErrFileNotExist:    "错误：文件不存在：%s",
+		MsgErrCountDir:        "统计目录文件时出错：%v",
+		MsgErrCountFile:       "统计文件字数时出错：%v",
+		MsgErrExportCSV:       "导出 CSV 时出错：%v",
+		MsgErrExportExcel:     "导出 Excel 时出错：%v",
+		MsgErrExportJSON:      "导出 JSON 时出错：%v",
+		MsgErrRenderTemplate:  "渲染模板时出错：%v",
+		MsgErrUnsupportedLang: "错误：不支持的语言：%s",
+		MsgErrExportType:      "错误：不支持的导出类型：%s，支持的类型：%s",
+		MsgErrInvalidColumns:  "错误：无效的列：%v",
+		MsgErrInvalidQuery:    "错误：无效的查询条件：%v",
+		MsgErrInvalidGroupBy:  "错误：无效的分组方式：%v",
+		MsgExcelExported:      "Excel 文件已导出至：%s",
+		MsgHistoryStreak:      "当前连续 %d 天，最长连续 %d 天",
+		MsgHistoryRecorded:    "已记录到历史：%s",
+		MsgErrHistory:         "读写历史记录时出错：%v",
+		MsgGoalBelowMinimum:   "（低于最低要求 %d）",
+		MsgGoalOverdue:        "（已逾期）",
+		MsgGoalDaysLeft:       "（剩余 %d 天）",
+		MsgGoalDueToday:       "（今天截止）",
+		MsgGoalDone:           "（已完成）",
+		MsgErrGoal:            "评估写作目标时出错：%v",
+		MsgErrConfig:          "加载配置时出错：%v",
+		MsgGoalsFailed:        "部分目标未达到最低要求",
+		MsgNoGoals:            "未定义目标，请在 %s 中添加或使用 --target",
+		MsgErrCheck:           "检查规则时出错：%v",
+		MsgCheckPassed:        "全部 %d 个文件通过了 %d 条规则",
+		MsgCheckFailed:        "%d 处违反规则（共 %d 条规则，%d 个文件）",
+		MsgNoRules:            "未定义规则，请在 %s 的 check 部分中添加或使用 --rule",
+		MsgNoConfigFile:       "（无）",
+		MsgDiffAdded:          "新增",
+		MsgDiffRemoved:        "删除",
+		MsgDiffModified:       "修改",
+		MsgErrDiff:            "比较统计时出错：%v",
+		MsgErrBlame:           "统计作者贡献时出错：%v",
+		MsgWatching:           "正在监视 %s，按 Ctrl+C 停止",
+		MsgWatchPolling:       "每 %s 轮询一次变更",
+		MsgWatchChanged:       "%[2]s 重新统计了 %[1]d 个变更路径",
+		MsgWatchDelta:         "自开始以来：行数 %+d，中文字数 %+d，非中文字数 %+d，总字数 %+d",
+		MsgErrWatch:           "监视目录时出错：%v",
+		MsgServerListening:    "正在监听 %s，按 Ctrl+C 停止",
+		MsgServerStopped:      "服务器已停止",
+		MsgErrServer:          "运行服务器时出错：%v",
+		MsgCacheStats:         "缓存：命中 %d 个，未命中 %d 个（命中率 %.1f%%）",
+		MsgWarnCacheSave:      "警告：无法保存缓存：%v",
+
+		MsgOK:               "成功",
+		MsgParseFailed:      "解析失败",
+		MsgRequestBodyEmpty: "请求体为空",
+		MsgNoFilesUploaded:  "未上传文件，请以 multipart/form-data 发送",
+		MsgBodyTooLarge:     "请求体过大",
+		MsgRequestFailed:    "请求失败",
+		MsgUnauthorized:     "未授权",
+		MsgRateLimited:      "请求过于频繁",
+		MsgInvalidOptions:   "选项无效",
+	},
+	LangTraditionalChinese: {
+		MsgHeaderFile:            "檔案",
+		MsgHeaderDirectory:       "目錄",
+		MsgHeaderFiles:           "檔案數",
+		MsgHeaderGroup:           "分組",
+		MsgHeaderPeriod:          "週期",
+		MsgHeaderLines:           "行數",
+		MsgHeaderChineseChars:    "中文字數",
+		MsgHeaderNonChineseChars: "非中文字數",
+		MsgHeaderTotalChars:      "總字數",
+		MsgHeaderShare:           "佔比",
+		MsgHeaderChineseRatio:    "中文佔比",
+		MsgHeaderSize:            "檔案大小",
+		MsgHeaderModified:        "修改時間",
+		MsgHeaderReadingTime:     "閱讀分鐘",
+		MsgHeaderWords:           "詞數",
+		MsgHeaderGoal:            "目標",
+		MsgHeaderField:           "欄位",
+		MsgHeaderCurrent:         "目前",
+		MsgHeaderTarget:          "目標值",
+		MsgHeaderProgress:        "進度",
+		MsgHeaderRemaining:       "剩餘",
+		MsgHeaderDeadline:        "截止日期",
+		MsgHeaderDailyPace:       "每日需完成",
+		MsgHeaderRule:            "規則",
+		MsgHeaderValue:           "數值",
+		MsgHeaderLimit:           "限制",
+		MsgHeaderStatus:          "狀態",
+		MsgHeaderAuthor:          "作者",
+		MsgHeaderDelta:           "%v變化",
+		MsgTotal:                 "合計",
+		MsgReportTitle:           "字數統計報告",
+
+		MsgWarnEmptyFile:      "警告：偵測到空檔案：%s",
+		MsgErrPathRequired:    "錯誤：缺少路徑參數",
+		MsgErrPathEmpty:       "錯誤：路徑不能為空",
+		MsgErrInvalidMode:     "錯誤：無效的模式，請選擇 'dir' 或 'file'",
+		MsgErrDirNotExist:     "錯誤：目錄不存在：%s",
+		MsgErrFileNotExist:    "錯誤：檔案不存在：%s",
+		MsgErrCountDir:        "統計目錄檔案時出錯：%v",
+		MsgErrCountFile:       "統計檔案字數時出錯：%v",
+		MsgErrExportCSV:       "匯出 CSV 時出錯：%v",
+		MsgErrExportExcel:     "匯出 Excel 時出錯：%v",
+		MsgErrExportJSON:      "匯出 JSON 時出錯：%v",
+		MsgErrRenderTemplate:  "渲染範本時出錯：%v",
+		MsgErrUnsupportedLang: "錯誤：不支援的語言：%s",
+		MsgErrExportType:      "錯誤：不支援的匯出類型：%s，支援的類型：%s",
+		MsgErrInvalidColumns:  "錯誤：無效的欄位：%v",
+		MsgErrInvalidQuery:    "錯誤：無效的查詢條件：%v",
+		MsgErrInvalidGroupBy:  "錯誤：無效的分組方式：%v",
+		MsgExcelExported:      "Excel 檔案已匯出至：%s",
+		MsgHistoryStreak:      "目前連續 %d 天，最長連續 %d 天",
+		MsgHistoryRecorded:    "已記錄到歷史：%s",
+		MsgErrHistory:         "讀寫歷史記錄時出錯：%v",
+		MsgGoalBelowMinimum:   "（低於最低要求 %d）",
+		MsgGoalOverdue:        "（已逾期）",
+		MsgGoalDaysLeft:       "（剩餘 %d 天）",
+		MsgGoalDueToday:       "（今天截止）",
+		MsgGoalDone:           "（已完成）",
+		MsgErrGoal:            "評估寫作目標時出錯：%v",
+		MsgErrConfig:          "載入設定時出錯：%v",
+		MsgGoalsFailed:        "部分目標未達到最低要求",
+		MsgNoGoals:            "未定義目標，請在 %s 中新增或使用 --target",
+		MsgErrCheck:           "檢查規則時出錯：%v",
+		MsgCheckPassed:        "全部 %d 個檔案通過了 %d 條規則",
+		MsgCheckFailed:        "%d 處違反規則（共 %d 條規則，%d 個檔案）",
+		MsgNoRules:            "未定義規則，請在 %s 的 check 部分中新增或使用 --rule",
+		MsgNoConfigFile:       "（無）",
+		MsgDiffAdded:          "新增",
+		MsgDiffRemoved:        "刪除",
+		MsgDiffModified:       "修改",
+		MsgErrDiff:            "比較統計時出錯：%v",
+		MsgErrBlame:           "統計作者貢獻時出錯：%v",
+		MsgWatching:           "正在監視 %s，按 Ctrl+C 停止",
+		MsgWatchPolling:       "每 %s 輪詢一次變更",
+		MsgWatchChanged:       "%[2]s 重新統計了 %[1]d 個變更路徑",
+		MsgWatchDelta:         "自開始以來：行數 %+d，中文字數 %+d，非中文字數 %+d，總字數 %+d",
+		MsgErrWatch:           "監視目錄時出錯：%v",
+		MsgServerListening:    "正在監聽 %s，按 Ctrl+C 停止",
+		MsgServerStopped:      "伺服器已停止",
+		MsgErrServer:          "執行伺服器時出錯：%v",
+		MsgCacheStats:         "快取：命中 %d 個，未命中 %d 個（命中率 %.1f%%）",
+		MsgWarnCacheSave:      "警告：無法儲存快取：%v",
+
+		MsgOK:               "成功",
+		MsgParseFailed:      "解析失敗",
+		MsgRequestBodyEmpty: "請求內容為空",
+		MsgNoFilesUploaded:  "未上傳檔案，請以 multipart/form-data 傳送",
+		MsgBodyTooLarge:     "請求內容過大",
+		MsgRequestFailed:    "請求失敗",
+		MsgUnauthorized:     "未授權",
+		MsgRateLimited:      "請求過於頻繁",
+		MsgInvalidOptions:   "選項無效",
+	},
+}
+
+var currentLanguage atomic.Value
+
+func init() {
+	currentLanguage.Store(LangEnglish)
+}
+
+// SupportedLanguages returns the languages that have a message catalog
+func SupportedLanguages() []string {
+	return []string{LangEnglish, LangSimplifiedChinese, LangTraditionalChinese}
+}
+
+// NormalizeLanguage maps a language tag or POSIX locale such as "zh_CN.UTF-8",
+// "zh-Hant" or "en_US" to one of the supported languages.
+// The second return value is false if the language is not supported.
+func NormalizeLanguage(lang string) (string, bool) {
+	lang = strings.TrimSpace(lang)
+	if i := strings.IndexAny(lang, ".@"); i >= 0 {
+		lang = lang[:i]
+	}
+	lang = strings.ToLower(strings.ReplaceAll(lang, "_", "-"))
+
+	switch {
+	case lang == "":
+		return "", false
+	case lang == "c" || lang == "posix" || lang == "en" || strings.HasPrefix(lang, "en-"):
+		return LangEnglish, true
+	case lang == "zh-tw" || lang == "zh-hk" || lang == "zh-mo" || strings.HasPrefix(lang, "zh-hant"):
+		return LangTraditionalChinese, true
+	case lang == "zh" || strings.HasPrefix(lang, "zh-"):
+		return LangSimplifiedChinese, true
+	default:
+		return "", false
+	}
+}
+
+// DetectLanguage determines the language from the locale environment variables
+// LC_ALL, LC_MESSAGES and LANG (in that order), falling back to English.
+func DetectLanguage() string {
+	for _, env := range []string{"LC_ALL", "LC_MESSAGES", "LANG"} {
+		value := os.Getenv(env)
+		if value == "" {
+			continue
+		}
+		if lang, ok := NormalizeLanguage(value); ok {
+			return lang
+		}
+		// The first non-empty variable takes precedence, as with setlocale(3)
+		break
+	}
+	return LangEnglish
+}
+
+// SetLanguage sets the language used for report headers and messages.
+// Returns an error if the language is not supported.
+func SetLanguage(lang string) error {
+	normalized, ok := NormalizeLanguage(lang)
+	if !ok {
+		return NewInvalidInputError(fmt.Sprintf("unsupported language: %s, supported languages: %s",
+			lang, strings.Join(SupportedLanguages(), ", "))).WithContext("lang", lang)
+	}
+	currentLanguage.Store(normalized)
+	return nil
+}
+
+// Language returns the language currently used for report headers and messages
+func Language() string {
+	return currentLanguage.Load().(string)
+}
+
+// T translates a message into the current language.
+// Arguments are formatted into the message with fmt.Sprintf.
+func T(key MessageKey, args ...any) string {
+	return Translate(Language(), key, args...)
+}
+
+// Translate translates a message into the given language, falling back to
+// English for unsupported languages or missing messages and to the key itself
+// for unknown messages.
+func Translate(lang string, key MessageKey, args ...any) string {
+	msg, ok := "", false
+	if normalized, supported := NormalizeLanguage(lang); supported {
+		msg, ok = catalogs[normalized][key]
+	}
+	if !ok {
+		msg, ok = catalogs[LangEnglish][key]
+	}
+	if !ok {
+		msg = string(key)
+	}
+
+	if len(args) > 0 {
+		return fmt.Sprintf(msg, args...)
+	}
+	return msg
+}
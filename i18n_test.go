@@ -0,0 +1,174 @@
+package wordcounter_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	wcg "github.com/100gle/wordcounter"
+	"github.com/gavv/httpexpect/v2"
+	"github.com/labstack/echo/v4"
+)
+
+func TestNormalizeLanguage(t *testing.T) {
+	tests := []struct {
+		input  string
+		want   string
+		wantOK bool
+	}{
+		{input: "en", want: wcg.LangEnglish, wantOK: true},
+		{input: "en_US.UTF-8", want: wcg.LangEnglish, wantOK: true},
+		{input: "C", want: wcg.LangEnglish, wantOK: true},
+		{input: "zh", want: wcg.LangSimplifiedChinese, wantOK: true},
+		{input: "zh_CN.UTF-8", want: wcg.LangSimplifiedChinese, wantOK: true},
+		{input: "zh-Hans", want: wcg.LangSimplifiedChinese, wantOK: true},
+		{input: "zh_TW", want: wcg.LangTraditionalChinese, wantOK: true},
+		{input: "zh-HK", want: wcg.LangTraditionalChinese, wantOK: true},
+		{input: "zh-Hant-TW", want: wcg.LangTraditionalChinese, wantOK: true},
+		{input: "fr_FR", want: "", wantOK: false},
+		{input: "", want: "", wantOK: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, ok := wcg.NormalizeLanguage(tt.input)
+			if got != tt.want || ok != tt.wantOK {
+				t.Errorf("NormalizeLanguage(%q) = %q, %v, want %q, %v", tt.input, got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestDetectLanguage(t *testing.T) {
+	tests := []struct {
+		name string
+		env  map[string]string
+		want string
+	}{
+		{
+			name: "LANG only",
+			env:  map[string]string{"LC_ALL": "", "LC_MESSAGES": "", "LANG": "zh_CN.UTF-8"},
+			want: wcg.LangSimplifiedChinese,
+		},
+		{
+			name: "LC_ALL takes precedence",
+			env:  map[string]string{"LC_ALL": "zh_TW.UTF-8", "LC_MESSAGES": "", "LANG": "en_US.UTF-8"},
+			want: wcg.LangTraditionalChinese,
+		},
+		{
+			name: "Unsupported locale falls back to English",
+			env:  map[string]string{"LC_ALL": "fr_FR.UTF-8", "LC_MESSAGES": "", "LANG": "zh_CN.UTF-8"},
+			want: wcg.LangEnglish,
+		},
+		{
+			name: "No locale",
+			env:  map[string]string{"LC_ALL": "", "LC_MESSAGES": "", "LANG": ""},
+			want: wcg.LangEnglish,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for k, v := range tt.env {
+				t.Setenv(k, v)
+			}
+			if got := wcg.DetectLanguage(); got != tt.want {
+				t.Errorf("DetectLanguage() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSetLanguage(t *testing.T) {
+	defer wcg.SetLanguage(wcg.LangEnglish)
+
+	if err := wcg.SetLanguage("fr"); err == nil {
+		t.Errorf("SetLanguage() expected error for unsupported language")
+	}
+	if got := wcg.Language(); got != wcg.LangEnglish {
+		t.Errorf("Language() = %q after failed SetLanguage, want %q", got, wcg.LangEnglish)
+	}
+
+	if err := wcg.SetLanguage("zh_CN"); err != nil {
+		t.Fatalf("SetLanguage() error = %v", err)
+	}
+	if got := wcg.Language(); got != wcg.LangSimplifiedChinese {
+		t.Errorf("Language() = %q, want %q", got, wcg.LangSimplifiedChinese)
+	}
+
+	want := wcg.Row{"行数", "中文字数", "非中文字数", "总字数"}
+	if got := (&wcg.Stats{}).Header(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Stats.Header() = %v, want %v", got, want)
+	}
+
+	dc := wcg.NewDirCounter("testdata")
+	if got := dc.GetHeader(); got[0] != "文件" {
+		t.Errorf("DirCounter.GetHeader() = %v, want first column %q", got, "文件")
+	}
+}
+
+func TestTranslate(t *testing.T) {
+	tests := []struct {
+		name string
+		lang string
+		key  wcg.MessageKey
+		args []any
+		want string
+	}{
+		{name: "English", lang: wcg.LangEnglish, key: wcg.MsgHeaderFile, want: "File"},
+		{name: "Traditional Chinese", lang: wcg.LangTraditionalChinese, key: wcg.MsgTotal, want: "合計"},
+		{name: "With arguments", lang: wcg.LangSimplifiedChinese, key: wcg.MsgErrFileNotExist, args: []any{"a.md"}, want: "错误：文件不存在：a.md"},
+		{name: "Unsupported language falls back to English", lang: "fr", key: wcg.MsgTotal, want: "Total"},
+		{name: "Unknown key", lang: wcg.LangEnglish, key: wcg.MessageKey("no.such.key"), want: "no.such.key"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := wcg.Translate(tt.lang, tt.key, tt.args...); got != tt.want {
+				t.Errorf("Translate() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWordCounterServer_CountAcceptLanguage(t *testing.T) {
+	app := echo.New()
+	server := wcg.NewWordCounterServer()
+	apiPath := "/v1/wordcounter/count"
+	app.POST(apiPath, server.Count)
+
+	testServer := httptest.NewServer(app)
+	defer testServer.Close()
+
+	e := httpexpect.Default(t, testServer.URL)
+
+	e.POST(apiPath).
+		WithHeader("Accept-Language", "fr-FR, zh-TW;q=0.8").
+		Expect().
+		Status(http.StatusUnprocessableEntity).
+		JSON().
+		Object().
+		Value("msg").String().IsEqual("解析失敗")
+
+	// Languages are preferred by their q-value, q=0 ones are not acceptable
+	for header, want := range map[string]string{
+		"en;q=0.1, zh-CN;q=0.9":  "解析失败",
+		"zh-TW;q=0, en":          "parse failed",
+		"zh-CN;q=0, zh-TW;q=0.5": "解析失敗",
+		"en, zh-CN":              "parse failed",
+	} {
+		e.POST(apiPath).
+			WithHeader("Accept-Language", header).
+			Expect().
+			Status(http.StatusUnprocessableEntity).
+			JSON().
+			Object().
+			Value("msg").String().IsEqual(want)
+	}
+
+	e.POST(apiPath).
+		WithJSON(&wcg.CountBody{Content: "你好"}).
+		Expect().
+		Status(http.StatusOK).
+		JSON().
+		Object().
+		Value("msg").String().IsEqual("ok")
+}
@@ -0,0 +1,142 @@
+package wordcounter
+
+import (
+	"io"
+	"log/slog"
+	"strconv"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// metricsNamespace prefixes the names of the metrics
+const metricsNamespace = "wordcounter"
+
+// errorCodeKey is the key of the code of the error response in the echo.Context, see respondError
+const errorCodeKey = "wordcounter.error_code"
+
+// Metrics are the Prometheus metrics of a WordCounterServer, served at MetricsEndpoint
+type Metrics struct {
+	Registry *prometheus.Registry
+
+	requests     *prometheus.CounterVec
+	duration     *prometheus.HistogramVec
+	inFlight     prometheus.Gauge
+	errors       *prometheus.CounterVec
+	countedBytes prometheus.Counter
+	countedChars *prometheus.CounterVec
+}
+
+// NewMetrics registers the metrics of the server, and those of the Go runtime and the process
+func NewMetrics() *Metrics {
+	m := &Metrics{
+		Registry: prometheus.NewRegistry(),
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "http_requests_total",
+			Help:      "Number of HTTP requests by route, method and status.",
+		}, []string{"route", "method", "status"}),
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: metricsNamespace,
+			Name:      "http_request_duration_seconds",
+			Help:      "Latency of HTTP requests by route, method and status.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"route", "method", "status"}),
+		inFlight: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: metricsNamespace,
+			Name:      "http_requests_in_flight",
+			Help:      "Number of HTTP requests being served.",
+		}),
+		errors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "errors_total",
+			Help:      "Number of error responses by code, see ErrorType.Code.",
+		}, []string{"code"}),
+		countedBytes: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "counted_bytes_total",
+			Help:      "Number of bytes of text counted.",
+		}),
+		countedChars: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "counted_chars_total",
+			Help:      "Number of characters counted by kind.",
+		}, []string{"kind"}),
+	}
+	m.Registry.MustRegister(
+		m.requests, m.duration, m.inFlight, m.errors, m.countedBytes, m.countedChars,
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+	return m
+}
+
+// ObserveCount records text of size bytes counted into stats
+func (m *Metrics) ObserveCount(size int64, stats *Stats) {
+	m.countedBytes.Add(float64(size))
+	m.countedChars.WithLabelValues("chinese").Add(float64(stats.ChineseChars))
+	m.countedChars.WithLabelValues("non_chinese").Add(float64(stats.NonChineseChars))
+}
+
+// Handler serves the metrics in the Prometheus exposition format
+func (m *Metrics) Handler() echo.HandlerFunc {
+	return echo.WrapHandler(promhttp.HandlerFor(m.Registry, promhttp.HandlerOpts{}))
+}
+
+// observe records the requests in the metrics and, when Config.AccessLog is set, in the access log
+func (s *WordCounterServer) observe(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		start := time.Now()
+		s.Metrics.inFlight.Inc()
+		defer s.Metrics.inFlight.Dec()
+
+		err := next(c)
+		if err != nil {
+			c.Error(err) // Writes the response now to record its status
+		}
+
+		req, res := c.Request(), c.Response()
+		route := c.Path()
+		if route == "" {
+			route = "unmatched"
+		}
+		status := strconv.Itoa(res.Status)
+		elapsed := time.Since(start)
+		s.Metrics.requests.WithLabelValues(route, req.Method, status).Inc()
+		s.Metrics.duration.WithLabelValues(route, req.Method, status).Observe(elapsed.Seconds())
+		code, _ := c.Get(errorCodeKey).(string)
+		if code != "" {
+			s.Metrics.errors.WithLabelValues(code).Inc()
+		}
+
+		if s.Config.AccessLog != nil {
+			s.Config.AccessLog.LogAttrs(req.Context(), slog.LevelInfo, "request",
+				slog.String("method", req.Method),
+				slog.String("uri", req.RequestURI),
+				slog.String("route", route),
+				slog.Int("status", res.Status),
+				slog.Float64("duration_ms", float64(elapsed.Microseconds())/1000),
+				slog.Int64("bytes_in", req.ContentLength),
+				slog.Int64("bytes_out", res.Size),
+				slog.String("remote_ip", c.RealIP()),
+				slog.String("error_code", code),
+			)
+		}
+		return nil
+	}
+}
+
+// countingReader counts the bytes read from a reader
+type countingReader struct {
+	io.Reader
+	n int64
+}
+
+func (r *countingReader) Read(p []byte) (int, error) {
+	n, err := r.Reader.Read(p)
+	r.n += int64(n)
+	return n, err
+}
@@ -0,0 +1,72 @@
+package wordcounter_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	wcg "github.com/100gle/wordcounter"
+	"github.com/gavv/httpexpect/v2"
+)
+
+func TestWordCounterServer_Metrics(t *testing.T) {
+	var accessLog bytes.Buffer
+	server := wcg.NewWordCounterServer()
+	server.Config.AccessLog = slog.New(slog.NewJSONHandler(&accessLog, nil))
+	testServer := httptest.NewServer(server.Echo)
+	defer testServer.Close()
+
+	e := httpexpect.WithConfig(httpexpect.Config{
+		BaseURL:  testServer.URL,
+		Reporter: httpexpect.NewAssertReporter(t),
+	})
+
+	e.POST(wcg.CountEndpoint).WithJSON(&wcg.CountBody{Content: "你好，world"}).Expect().Status(http.StatusOK)
+	e.POST(wcg.CountEndpoint).WithText("hello").Expect().Status(http.StatusOK)
+	e.POST(wcg.CountEndpoint).WithJSON(&wcg.CountBody{}).Expect().Status(http.StatusUnprocessableEntity)
+	e.POST(wcg.BatchEndpoint).WithJSON([]wcg.BatchItem{{ID: "a", Content: "世界"}}).Expect().Status(http.StatusOK)
+	e.GET("/nowhere/12345").Expect().Status(http.StatusNotFound)
+
+	metrics := e.GET(wcg.MetricsEndpoint).
+		Expect().
+		Status(http.StatusOK).
+		Body().Raw()
+
+	for _, want := range []string{
+		`wordcounter_http_requests_total{method="POST",route="/v1/wordcounter/count",status="200"} 2`,
+		`wordcounter_http_requests_total{method="POST",route="/v1/wordcounter/count",status="422"} 1`,
+		`wordcounter_http_requests_total{method="GET",route="unmatched",status="404"} 1`,
+		`wordcounter_http_request_duration_seconds_count{method="POST",route="/v1/wordcounter/batch",status="200"} 1`,
+		`wordcounter_http_requests_in_flight 1`, // The request for the metrics
+		`wordcounter_errors_total{code="invalid_input"} 1`,
+		`wordcounter_errors_total{code="not_found"} 1`,
+		`wordcounter_counted_bytes_total 25`,
+		`wordcounter_counted_chars_total{kind="chinese"} 5`,
+		`wordcounter_counted_chars_total{kind="non_chinese"} 10`,
+		`go_goroutines`,
+	} {
+		if !strings.Contains(metrics, want) {
+			t.Errorf("metrics do not contain %s", want)
+		}
+	}
+	if strings.Contains(metrics, "12345") {
+		t.Error("metrics should not be labeled with the paths of unknown routes")
+	}
+
+	lines := strings.Split(strings.TrimSpace(accessLog.String()), "\n")
+	if len(lines) != 6 {
+		t.Fatalf("access log has %d lines, want one per request:\n%s", len(lines), accessLog.String())
+	}
+	var entry map[string]any
+	if err := json.Unmarshal([]byte(lines[2]), &entry); err != nil {
+		t.Fatalf("access log line is not JSON: %v", err)
+	}
+	if entry["route"] != wcg.CountEndpoint || entry["status"] != float64(http.StatusUnprocessableEntity) ||
+		entry["error_code"] != "invalid_input" || entry["method"] != http.MethodPost {
+		t.Errorf("access log entry = %v", entry)
+	}
+}
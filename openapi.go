@@ -0,0 +1,30 @@
+package wordcounter
+
+import (
+	"bytes"
+	_ "embed"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+)
+
+//go:embed api/openapi.json
+var openAPISpec []byte
+
+//go:embed api/docs.html
+var docsPage []byte
+
+// OpenAPISpec returns the OpenAPI 3 document describing the endpoints of WordCounterServer
+func OpenAPISpec() []byte {
+	return bytes.Clone(openAPISpec)
+}
+
+// OpenAPI serves the OpenAPI document of the API
+func (s *WordCounterServer) OpenAPI(c echo.Context) error {
+	return c.Blob(http.StatusOK, echo.MIMEApplicationJSON, openAPISpec)
+}
+
+// Docs serves a documentation page rendering the OpenAPI document
+func (s *WordCounterServer) Docs(c echo.Context) error {
+	return c.HTMLBlob(http.StatusOK, docsPage)
+}
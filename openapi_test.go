@@ -0,0 +1,147 @@
+package wordcounter_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+
+	wcg "github.com/100gle/wordcounter"
+	"github.com/gavv/httpexpect/v2"
+)
+
+// openAPIDocument is the part of the OpenAPI document the tests read
+type openAPIDocument struct {
+	OpenAPI    string                                 `json:"openapi"`
+	Servers    []struct{ URL string }                 `json:"servers"`
+	Paths      map[string]map[string]openAPIOperation `json:"paths"`
+	Components map[string]map[string]map[string]any   `json:"components"`
+}
+
+type openAPIOperation struct {
+	Responses map[string]map[string]any `json:"responses"`
+}
+
+func loadOpenAPI(t *testing.T) *openAPIDocument {
+	t.Helper()
+	var doc openAPIDocument
+	if err := json.Unmarshal(wcg.OpenAPISpec(), &doc); err != nil {
+		t.Fatalf("OpenAPISpec() is not valid JSON: %v", err)
+	}
+	return &doc
+}
+
+// responseSchema returns the JSON schema of a documented response, with the components its references point to
+func (d *openAPIDocument) responseSchema(t *testing.T, method, endpoint string, status int) map[string]any {
+	t.Helper()
+	path := strings.TrimPrefix(endpoint, d.Servers[0].URL)
+	operation, ok := d.Paths[path][strings.ToLower(method)]
+	if !ok {
+		t.Fatalf("%s %s is not documented", method, path)
+	}
+	response, ok := operation.Responses[strconv.Itoa(status)]
+	if !ok {
+		t.Fatalf("%s %s has no documented %d response", method, path, status)
+	}
+	if ref, ok := response["$ref"].(string); ok {
+		response = d.Components["responses"][ref[strings.LastIndex(ref, "/")+1:]]
+	}
+	content, _ := response["content"].(map[string]any)
+	media, ok := content["application/json"].(map[string]any)
+	if !ok {
+		t.Fatalf("%s %s %d response has no JSON schema", method, path, status)
+	}
+	return map[string]any{
+		"components": d.Components,
+		"allOf":      []any{media["schema"]},
+	}
+}
+
+// TestOpenAPISpec tests that the document describes exactly the endpoints of the server
+func TestOpenAPISpec(t *testing.T) {
+	doc := loadOpenAPI(t)
+	if !strings.HasPrefix(doc.OpenAPI, "3.") {
+		t.Errorf("openapi = %q, want an OpenAPI 3 document", doc.OpenAPI)
+	}
+	if len(doc.Servers) != 1 || doc.Servers[0].URL != wcg.APIBasePath {
+		t.Fatalf("servers = %+v, want %s", doc.Servers, wcg.APIBasePath)
+	}
+
+	var routes, documented []string
+	for _, route := range wcg.NewWordCounterServer().Echo.Routes() {
+		if strings.HasPrefix(route.Path, wcg.APIBasePath) {
+			routes = append(routes, route.Method+" "+route.Path)
+		}
+	}
+	for path, operations := range doc.Paths {
+		for method := range operations {
+			documented = append(documented, strings.ToUpper(method)+" "+wcg.APIBasePath+path)
+		}
+	}
+	sort.Strings(routes)
+	sort.Strings(documented)
+	if strings.Join(routes, "\n") != strings.Join(documented, "\n") {
+		t.Errorf("documented endpoints:\n%s\nwant the routes of the server:\n%s",
+			strings.Join(documented, "\n"), strings.Join(routes, "\n"))
+	}
+}
+
+// TestOpenAPISpec_Responses validates responses of the handlers against the document
+func TestOpenAPISpec_Responses(t *testing.T) {
+	doc := loadOpenAPI(t)
+	server := wcg.NewWordCounterServer()
+	server.Config.MaxBodySize = 1024
+	testServer := httptest.NewServer(server.Echo)
+	defer testServer.Close()
+
+	e := httpexpect.WithConfig(httpexpect.Config{
+		BaseURL:  testServer.URL,
+		Reporter: httpexpect.NewAssertReporter(t),
+	})
+
+	tests := []struct {
+		name     string
+		endpoint string
+		req      *httpexpect.Request
+		status   int
+	}{
+		{"count", wcg.CountEndpoint, e.POST(wcg.CountEndpoint).WithJSON(&wcg.CountBody{Content: "你好，world"}), http.StatusOK},
+		{"count words", wcg.CountEndpoint, e.POST(wcg.CountEndpoint).WithQuery("count_words", "true").WithText("hello 世界"), http.StatusOK},
+		{"count empty content", wcg.CountEndpoint, e.POST(wcg.CountEndpoint).WithJSON(&wcg.CountBody{}), http.StatusUnprocessableEntity},
+		{"count invalid options", wcg.CountEndpoint, e.POST(wcg.CountEndpoint).WithQuery("count_words", "maybe").WithText("你好"), http.StatusUnprocessableEntity},
+		{"count too large", wcg.CountEndpoint, e.POST(wcg.CountEndpoint).WithText(strings.Repeat("a", 2048)), http.StatusRequestEntityTooLarge},
+		{"batch", wcg.BatchEndpoint, e.POST(wcg.BatchEndpoint).WithJSON([]wcg.BatchItem{{ID: "a", Content: "你好"}, {ID: "b"}}), http.StatusOK},
+		{"batch without id", wcg.BatchEndpoint, e.POST(wcg.BatchEndpoint).WithJSON([]wcg.BatchItem{{Content: "你好"}}), http.StatusUnprocessableEntity},
+		{"upload", wcg.UploadEndpoint, e.POST(wcg.UploadEndpoint).WithMultipart().WithFileBytes("files", "a.md", []byte("# 标题")), http.StatusOK},
+		{"upload without files", wcg.UploadEndpoint, e.POST(wcg.UploadEndpoint).WithMultipart().WithFormField("count_words", "true"), http.StatusUnprocessableEntity},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			schema := doc.responseSchema(t, http.MethodPost, tt.endpoint, tt.status)
+			tt.req.Expect().
+				Status(tt.status).
+				JSON().
+				Schema(schema)
+		})
+	}
+
+	t.Run("openapi", func(t *testing.T) {
+		e.GET(wcg.OpenAPIEndpoint).
+			Expect().
+			Status(http.StatusOK).
+			ContentType("application/json").
+			JSON().Object().
+			ContainsKey("paths")
+	})
+
+	t.Run("docs", func(t *testing.T) {
+		e.GET(wcg.DocsEndpoint).
+			Expect().
+			Status(http.StatusOK).
+			ContentType("text/html").
+			Body().Contains("openapi.json")
+	})
+}
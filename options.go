@@ -0,0 +1,293 @@
+package wordcounter
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// CountOptions change what is counted. The zero value counts every character of the content.
+type CountOptions struct {
+	StripMarkdown      bool `json:"strip_markdown" yaml:"strip_markdown"`           // Count the text of Markdown documents without their syntax and front matter
+	ExcludePunctuation bool `json:"exclude_punctuation" yaml:"exclude_punctuation"` // Leave punctuation out of the character counts
+	CountWords         bool `json:"count_words" yaml:"count_words"`                 // Count words, every Chinese character being a word
+}
+
+// Keys of the counting options, as in JSON and query parameters
+const (
+	OptionStripMarkdown      = "strip_markdown"
+	OptionExcludePunctuation = "exclude_punctuation"
+	OptionCountWords         = "count_words"
+)
+
+// String identifies the options, e.g. "strip_markdown,count_words", empty for the zero value
+func (o CountOptions) String() string {
+	var keys []string
+	if o.StripMarkdown {
+		keys = append(keys, OptionStripMarkdown)
+	}
+	if o.ExcludePunctuation {
+		keys = append(keys, OptionExcludePunctuation)
+	}
+	if o.CountWords {
+		keys = append(keys, OptionCountWords)
+	}
+	return strings.Join(keys, ",")
+}
+
+// UnmarshalJSON sets the options given as booleans in a JSON object.
+// Unknown options and values of other types are errors naming the option in their "field" context.
+func (o *CountOptions) UnmarshalJSON(data []byte) error {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return NewInvalidInputError("options must be an object").WithContext("field", "")
+	}
+	keys := make([]string, 0, len(fields))
+	for key := range fields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		raw := fields[key]
+		var enabled bool
+		if err := json.Unmarshal(raw, &enabled); err != nil {
+			return NewInvalidInputError(fmt.Sprintf("invalid value of %s: %s, expected true or false", key, raw)).WithContext("field", key)
+		}
+		if err := o.Set(key, strconv.FormatBool(enabled)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Set enables or disables an option identified by its key, with a value parsed like strconv.ParseBool
+func (o *CountOptions) Set(key, value string) error {
+	enabled, err := strconv.ParseBool(value)
+	if err != nil {
+		return NewInvalidInputError(fmt.Sprintf("invalid value of %s: %q, expected true or false", key, value)).WithContext("field", key)
+	}
+	switch key {
+	case OptionStripMarkdown:
+		o.StripMarkdown = enabled
+	case OptionExcludePunctuation:
+		o.ExcludePunctuation = enabled
+	case OptionCountWords:
+		o.CountWords = enabled
+	default:
+		return NewInvalidInputError(fmt.Sprintf("unsupported option: %s, supported options: %s", key,
+			strings.Join([]string{OptionStripMarkdown, OptionExcludePunctuation, OptionCountWords}, ", "))).WithContext("field", key)
+	}
+	return nil
+}
+
+var (
+	markdownFence     = regexp.MustCompile("^\\s*(```|~~~)")
+	markdownRule      = regexp.MustCompile(`^\s*([-*_]\s*){3,}$`)
+	markdownTableRule = regexp.MustCompile(`^\s*\|?\s*:?-+:?\s*(\|\s*:?-+:?\s*)*\|?\s*$`)
+	markdownBlock     = regexp.MustCompile(`^\s*((>\s?)+|#{1,6}\s+|([-*+]|\d+[.)])\s+(\[[ xX]\]\s+)?)`)
+	markdownImage     = regexp.MustCompile(`!\[([^\]]*)\]\([^)]*\)`)
+	markdownLink      = regexp.MustCompile(`\[([^\]]*)\]\([^)]*\)`)
+	markdownTag       = regexp.MustCompile(`</?[a-zA-Z][^>]*>`)
+	markdownEmphasis  = regexp.MustCompile("\\*+|~~|`+|__")
+)
+
+// markdownLineLimit is the longest piece of a line that Markdown stripping holds in memory.
+// Longer lines are stripped in pieces, so syntax across the end of a piece may be kept,
+// and longer front matter is counted as text.
+const markdownLineLimit = 64 << 10
+
+// markdownStripper turns the lines of a Markdown document into text: front matter, comments, HTML tags,
+// code fences, rules and the markers of headings, quotes, lists, tables, links and emphasis are removed.
+// Lines are kept, except those of the front matter.
+type markdownStripper struct {
+	write     func([]byte) // Receives the text, each piece ending on a rune boundary
+	lines     int
+	written   bool     // A line was written, so the next one starts with a newline
+	front     [][]byte // Lines of the front matter being read
+	frontSize int
+	inFront   bool
+	inCode    bool
+	inComment bool
+	inLine    bool // The rest of a long line follows
+	inTable   bool // The current line is a table row
+	dropLine  bool // The current line has no text, e.g. a code fence
+}
+
+// stripMarkdown returns the text of a Markdown document, see markdownStripper
+func stripMarkdown(data []byte) []byte {
+	text := make([]byte, 0, len(data))
+	// Reading from memory does not fail
+	_ = stripMarkdownReader(bytes.NewReader(data), func(piece []byte) { text = append(text, piece...) })
+	return text
+}
+
+// stripMarkdownReader reads a Markdown document line by line and writes its text, see markdownStripper.
+// At most markdownLineLimit bytes of a line are held in memory.
+func stripMarkdownReader(r io.Reader, write func([]byte)) error {
+	s := &markdownStripper{write: write}
+	reader := bufio.NewReaderSize(r, markdownLineLimit)
+	var carry []byte // Incomplete rune at the end of the previous piece
+	for {
+		piece, err := reader.ReadSlice('\n')
+		if len(carry) > 0 {
+			piece, carry = append(carry, piece...), nil
+		}
+		switch {
+		case err == bufio.ErrBufferFull:
+			end := runeBoundary(piece)
+			carry = append([]byte{}, piece[end:]...)
+			s.piece(piece[:end], false)
+		case err != nil && err != io.EOF:
+			return err
+		default:
+			s.piece(bytes.TrimSuffix(piece, []byte("\n")), true)
+			if err == io.EOF {
+				s.close()
+				return nil
+			}
+		}
+	}
+}
+
+// piece strips a line, without its newline, or a piece of a long line that continues if not lineEnd
+func (s *markdownStripper) piece(piece []byte, lineEnd bool) {
+	if s.inLine {
+		s.inLine = !lineEnd
+		if !s.dropLine {
+			s.write(s.stripRest(piece))
+		}
+		return
+	}
+	s.inLine = !lineEnd
+	if s.inLine && s.inFront {
+		s.flushFront()
+	}
+	s.line(piece)
+}
+
+// line strips a line, or the first piece of a long line
+func (s *markdownStripper) line(line []byte) {
+	s.lines++
+	if s.lines == 1 {
+		line = bytes.TrimPrefix(line, []byte("\xef\xbb\xbf")) // UTF-8 BOM
+		if !s.inLine && bytes.HasPrefix(line, frontMatterDelimiter) && len(bytes.TrimSpace(line)) == len(frontMatterDelimiter) {
+			s.inFront = true
+			s.front, s.frontSize = [][]byte{append([]byte{}, line...)}, len(line)
+			return
+		}
+	}
+	if s.inFront {
+		s.front = append(s.front, append([]byte{}, line...))
+		s.frontSize += len(line)
+		if bytes.Equal(bytes.TrimSpace(line), frontMatterDelimiter) {
+			s.inFront, s.front = false, nil
+		} else if s.frontSize > markdownLineLimit {
+			s.flushFront()
+		}
+		return
+	}
+	s.emit(s.strip(line))
+}
+
+// close writes the lines of an unterminated front matter, which is not a front matter then
+func (s *markdownStripper) close() {
+	if s.inFront {
+		s.flushFront()
+	}
+}
+
+// flushFront writes the lines read as front matter as text
+func (s *markdownStripper) flushFront() {
+	front := s.front
+	s.inFront, s.front = false, nil
+	for _, line := range front {
+		s.emit(s.strip(line))
+	}
+}
+
+// emit writes a stripped line
+func (s *markdownStripper) emit(line []byte) {
+	if s.written {
+		s.write([]byte("\n"))
+	}
+	s.written = true
+	s.write(line)
+}
+
+// strip removes the Markdown syntax of a line, returning nil for lines without text
+func (s *markdownStripper) strip(line []byte) []byte {
+	s.inTable, s.dropLine = false, false
+	line = s.stripComments(line)
+	switch {
+	case markdownFence.Match(line):
+		s.inCode = !s.inCode
+		s.dropLine = true
+		return nil
+	case s.inCode:
+		return line
+	case markdownRule.Match(line), markdownTableRule.Match(line) && bytes.Contains(line, []byte("-")):
+		s.dropLine = true
+		return nil
+	}
+
+	line = markdownBlock.ReplaceAll(line, nil)
+	line = stripInline(line)
+	if trimmed := bytes.TrimSpace(line); bytes.HasPrefix(trimmed, []byte("|")) {
+		s.inTable = true
+		line = bytes.ReplaceAll(trimmed, []byte("|"), nil)
+	}
+	return line
+}
+
+// stripRest removes the Markdown syntax of a piece following the first one of a long line
+func (s *markdownStripper) stripRest(piece []byte) []byte {
+	piece = s.stripComments(piece)
+	if s.inCode {
+		return piece
+	}
+	piece = stripInline(piece)
+	if s.inTable {
+		piece = bytes.ReplaceAll(piece, []byte("|"), nil)
+	}
+	return piece
+}
+
+// stripInline removes images, links, HTML tags and emphasis, keeping the text of links and images
+func stripInline(line []byte) []byte {
+	line = markdownImage.ReplaceAll(line, []byte("$1"))
+	line = markdownLink.ReplaceAll(line, []byte("$1"))
+	line = markdownTag.ReplaceAll(line, nil)
+	return markdownEmphasis.ReplaceAll(line, nil)
+}
+
+// stripComments removes HTML comments, which may span lines
+func (s *markdownStripper) stripComments(line []byte) []byte {
+	var text []byte
+	for len(line) > 0 {
+		if s.inComment {
+			end := bytes.Index(line, []byte("-->"))
+			if end < 0 {
+				break
+			}
+			line, s.inComment = line[end+len("-->"):], false
+			continue
+		}
+		start := bytes.Index(line, []byte("<!--"))
+		if start < 0 {
+			if text == nil {
+				return line
+			}
+			return append(text, line...)
+		}
+		text = append(text, line[:start]...)
+		line, s.inComment = line[start+len("<!--"):], true
+	}
+	return text
+}
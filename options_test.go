@@ -0,0 +1,225 @@
+package wordcounter_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	wcg "github.com/100gle/wordcounter"
+	"github.com/gavv/httpexpect/v2"
+)
+
+func TestCounter_Options(t *testing.T) {
+	markdown := "---\ntitle: 测试\n---\n# 标题\n\n> 引用**加粗**\n\n- [链接](https://example.com)\n1. ![图片](a.png)\n\n```go\nfmt.Println(\"代码\")\n```\n\n| 列一 | 列二 |\n| --- | --- |\n| 甲 | 乙 |\n\n---\n<!-- 注释 -->\n<b>结束</b>"
+
+	tests := []struct {
+		name    string
+		content string
+		options wcg.CountOptions
+		want    wcg.Stats
+	}{
+		{
+			name:    "no options",
+			content: "你好，world!",
+			want:    wcg.Stats{Lines: 1, ChineseChars: 3, NonChineseChars: 6, TotalChars: 9},
+		},
+		{
+			name:    "exclude punctuation",
+			content: "你好，world!「引号」",
+			options: wcg.CountOptions{ExcludePunctuation: true},
+			want:    wcg.Stats{Lines: 1, ChineseChars: 4, NonChineseChars: 5, TotalChars: 9},
+		},
+		{
+			name:    "count words",
+			content: "Don't panic, 你好 42 times\nhello-world",
+			options: wcg.CountOptions{CountWords: true},
+			want:    wcg.Stats{Lines: 2, ChineseChars: 2, NonChineseChars: 33, TotalChars: 35, Words: 8},
+		},
+		{
+			name:    "strip markdown",
+			content: markdown,
+			options: wcg.CountOptions{StripMarkdown: true, ExcludePunctuation: true},
+			want:    wcg.Stats{Lines: 18, ChineseChars: 20, NonChineseChars: 18, TotalChars: 38},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			counter := wcg.NewCounterWithOptions(tt.options)
+			if err := counter.Count(tt.content); err != nil {
+				t.Fatalf("Count() error = %v", err)
+			}
+			if *counter.Stats != tt.want {
+				t.Errorf("Count() = %+v, want %+v", *counter.Stats, tt.want)
+			}
+			if counter.Options() != tt.options {
+				t.Errorf("Options() = %+v, want %+v", counter.Options(), tt.options)
+			}
+		})
+	}
+}
+
+func TestCountOptions_Set(t *testing.T) {
+	options := wcg.CountOptions{}
+	if options.String() != "" {
+		t.Errorf("String() of zero options = %q", options.String())
+	}
+	for _, key := range []string{wcg.OptionStripMarkdown, wcg.OptionExcludePunctuation, wcg.OptionCountWords} {
+		if err := options.Set(key, "true"); err != nil {
+			t.Errorf("Set(%s) error = %v", key, err)
+		}
+	}
+	if options.String() != "strip_markdown,exclude_punctuation,count_words" {
+		t.Errorf("String() = %q", options.String())
+	}
+
+	for _, invalid := range [][2]string{{"strip_markdown", "yes"}, {"stem_words", "true"}} {
+		err := options.Set(invalid[0], invalid[1])
+		var wcErr *wcg.WordCounterError
+		if !errors.As(err, &wcErr) || wcErr.Context["field"] != invalid[0] {
+			t.Errorf("Set(%s, %s) error = %v, want an error naming the field", invalid[0], invalid[1], err)
+		}
+	}
+
+	var parsed wcg.CountOptions
+	if err := json.Unmarshal([]byte(`{"count_words": true, "strip_markdown": false}`), &parsed); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	if parsed != (wcg.CountOptions{CountWords: true}) {
+		t.Errorf("Unmarshal() = %+v", parsed)
+	}
+	for _, invalid := range []string{`{"count_words": "yes"}`, `{"stem": true}`, `[]`} {
+		if err := json.Unmarshal([]byte(invalid), &parsed); err == nil {
+			t.Errorf("Unmarshal(%s) should return error", invalid)
+		}
+	}
+}
+
+func TestDirCounter_SetOptions(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "a.md"), []byte("# 标题\n\n你好，世界"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+
+	dc := wcg.NewDirCounter(dir)
+	plain := dc.CountingOptions()
+	options := wcg.CountOptions{StripMarkdown: true, CountWords: true}
+	dc.SetOptions(options)
+	if dc.Options() != options {
+		t.Errorf("Options() = %+v", dc.Options())
+	}
+	if dc.CountingOptions() == plain {
+		t.Error("CountingOptions() should change with the counting options, to invalidate caches")
+	}
+	if err := dc.Count(); err != nil {
+		t.Fatalf("Count() error = %v", err)
+	}
+	got := *dc.GetFileCounters()[0].Stats
+	if want := (wcg.Stats{Lines: 3, ChineseChars: 7, NonChineseChars: 0, TotalChars: 7, Words: 6}); got != want {
+		t.Errorf("Count() = %+v, want %+v", got, want)
+	}
+}
+
+func TestWordCounterServer_CountOptions(t *testing.T) {
+	server := wcg.NewWordCounterServer()
+	testServer := httptest.NewServer(server.Echo)
+	defer testServer.Close()
+
+	e := httpexpect.WithConfig(httpexpect.Config{
+		BaseURL:  testServer.URL,
+		Reporter: httpexpect.NewAssertReporter(t),
+	})
+
+	t.Run("query", func(t *testing.T) {
+		object := e.POST(wcg.CountEndpoint).
+			WithQuery("count_words", "true").
+			WithQuery("exclude_punctuation", "1").
+			WithJSON(&wcg.CountBody{Content: "你好，world!"}).
+			Expect().
+			Status(http.StatusOK).
+			JSON().Object()
+		object.Value("data").Object().HasValue("words", 3).HasValue("total_chars", 7)
+		object.Value("options").Object().
+			HasValue("count_words", true).
+			HasValue("exclude_punctuation", true).
+			HasValue("strip_markdown", false)
+	})
+
+	t.Run("body takes precedence", func(t *testing.T) {
+		e.POST(wcg.CountEndpoint).
+			WithQuery("count_words", "true").
+			WithJSON(map[string]any{"content": "# 标题", "options": map[string]bool{"strip_markdown": true}}).
+			Expect().
+			Status(http.StatusOK).
+			JSON().Object().
+			HasValue("data", map[string]int{"lines": 1, "chinese_chars": 2, "total_chars": 2}).
+			HasValue("options", wcg.CountOptions{StripMarkdown: true})
+	})
+
+	invalid := []struct {
+		name  string
+		query map[string]string
+		body  any
+		field string
+	}{
+		{"query value", map[string]string{"strip_markdown": "maybe"}, &wcg.CountBody{Content: "你好"}, "strip_markdown"},
+		{"body value", nil, map[string]any{"content": "你好", "options": map[string]any{"count_words": "yes"}}, "options.count_words"},
+		{"unknown option", nil, map[string]any{"content": "你好", "options": map[string]any{"stem": true}}, "options.stem"},
+		{"not an object", nil, map[string]any{"content": "你好", "options": true}, "options"},
+	}
+	for _, tt := range invalid {
+		t.Run(tt.name, func(t *testing.T) {
+			req := e.POST(wcg.CountEndpoint).WithJSON(tt.body)
+			for key, value := range tt.query {
+				req = req.WithQuery(key, value)
+			}
+			req.Expect().
+				Status(http.StatusUnprocessableEntity).
+				JSON().Object().
+				HasValue("msg", "invalid options").
+				HasValue("code", "invalid_input").
+				HasValue("context", map[string]string{"field": tt.field}).
+				ContainsKey("error")
+		})
+	}
+
+	t.Run("batch", func(t *testing.T) {
+		results := e.POST(wcg.BatchEndpoint).
+			WithQuery("count_words", "true").
+			WithJSON([]map[string]any{
+				{"id": "a", "content": "hello world"},
+				{"id": "b", "content": "hello world", "options": map[string]bool{"exclude_punctuation": true}},
+			}).
+			Expect().
+			Status(http.StatusOK).
+			JSON().Object().
+			Value("data").Object().
+			Value("results").Object()
+		results.Value("a").Object().Value("stats").Object().HasValue("words", 2)
+		results.Value("b").Object().Value("stats").Object().NotContainsKey("words")
+		results.Value("b").Object().Value("options").Object().HasValue("exclude_punctuation", true)
+
+		e.POST(wcg.BatchEndpoint).
+			WithJSON([]map[string]any{{"id": "a", "content": "你好", "options": map[string]any{"count_words": 1}}}).
+			Expect().
+			Status(http.StatusUnprocessableEntity).
+			JSON().Object().
+			Value("context").Object().HasValue("field", "options.count_words")
+	})
+
+	t.Run("upload", func(t *testing.T) {
+		e.POST(wcg.UploadEndpoint).
+			WithMultipart().
+			WithFormField("count_words", "true").
+			WithFileBytes("files", "a.txt", []byte("hello world")).
+			Expect().
+			Status(http.StatusOK).
+			JSON().Object().
+			Value("data").Object().
+			Value("total").Object().
+			HasValue("words", 2)
+	})
+}
@@ -0,0 +1,247 @@
+package wordcounter
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Filter operators
+const (
+	FilterOpLess         = "<"
+	FilterOpLessEqual    = "<="
+	FilterOpGreater      = ">"
+	FilterOpGreaterEqual = ">="
+	FilterOpEqual        = "=="
+	FilterOpNotEqual     = "!="
+	FilterOpMatch        = "~" // glob match, against the base name if the pattern has no separator
+)
+
+// filterOps is ordered so that two-character operators win over their one-character prefixes
+var filterOps = []string{
+	FilterOpLessEqual,
+	FilterOpGreaterEqual,
+	FilterOpEqual,
+	FilterOpNotEqual,
+	FilterOpLess,
+	FilterOpGreater,
+	FilterOpMatch,
+}
+
+// Filter is a single condition on a column, such as "total_chars < 500"
+type Filter struct {
+	Column string
+	Op     string
+	Value  string
+}
+
+// Query selects, orders and limits the counted files of a DirCounter.
+// Filters are combined with AND, then the remaining files are sorted and
+// truncated to Top entries when Top is greater than zero.
+type Query struct {
+	Where  []Filter
+	SortBy string
+	Desc   bool
+	Top    int
+}
+
+// ParseFilter parses a filter expression of the form "<column> <op> <value>",
+// e.g. "total_chars < 500" or "file ~ '*/drafts/*'". Values may be quoted.
+func ParseFilter(expr string) (Filter, error) {
+	// The operator is the first one in the expression, so that values may contain operators
+	opIndex, opFound := -1, ""
+	for _, op := range filterOps {
+		i := strings.Index(expr, op)
+		if i >= 0 && (opIndex < 0 || i < opIndex) {
+			opIndex, opFound = i, op
+		}
+	}
+
+	if opIndex > 0 {
+		filter := Filter{
+			Column: strings.TrimSpace(expr[:opIndex]),
+			Op:     opFound,
+			Value:  unquote(strings.TrimSpace(expr[opIndex+len(opFound):])),
+		}
+		if err := filter.validate(); err != nil {
+			return Filter{}, err
+		}
+		return filter, nil
+	}
+	return Filter{}, NewInvalidInputError(fmt.Sprintf("invalid filter expression: %q, expected <column> <op> <value>", expr)).
+		WithContext("filter", expr)
+}
+
+func unquote(value string) string {
+	if len(value) >= 2 && (value[0] == '\'' || value[0] == '"') && value[len(value)-1] == value[0] {
+		return value[1 : len(value)-1]
+	}
+	return value
+}
+
+func (f Filter) String() string {
+	return fmt.Sprintf("%s %s %s", f.Column, f.Op, f.Value)
+}
+
+func (f Filter) validate() error {
+	if _, ok := columnRegistry[f.Column]; !ok {
+		return NewInvalidInputError(fmt.Sprintf("unsupported filter column: %s, supported columns: %s",
+			f.Column, strings.Join(AvailableColumns(), ", "))).WithContext("filter", f.String())
+	}
+	if f.Op == FilterOpMatch {
+		if _, err := filepath.Match(f.Value, ""); err != nil {
+			return NewPatternMatchError(f.Value, err).WithContext("filter", f.String())
+		}
+		return nil
+	}
+	if _, ok := columnRegistry[f.Column].value(&columnInput{}).(string); ok {
+		return nil
+	}
+	if _, err := strconv.ParseFloat(f.Value, 64); err != nil {
+		return NewInvalidInputError(fmt.Sprintf("filter value of numeric column %s must be a number: %s", f.Column, f.Value)).
+			WithContext("filter", f.String())
+	}
+	return nil
+}
+
+// match reports whether the column value satisfies the filter
+func (f Filter) match(in *columnInput) bool {
+	value := columnRegistry[f.Column].value(in)
+
+	if f.Op == FilterOpMatch {
+		// Like ignore patterns, patterns without a separator match the base name
+		text := fmt.Sprintf("%v", value)
+		if !strings.ContainsRune(f.Value, filepath.Separator) && !strings.Contains(f.Value, "/") {
+			text = filepath.Base(text)
+		}
+		matched, _ := filepath.Match(f.Value, text)
+		return matched
+	}
+
+	var cmp int
+	if text, ok := value.(string); ok {
+		cmp = strings.Compare(text, f.Value)
+	} else {
+		want, _ := strconv.ParseFloat(f.Value, 64)
+		cmp = compareFloat(toFloat(value), want)
+	}
+
+	switch f.Op {
+	case FilterOpLess:
+		return cmp < 0
+	case FilterOpLessEqual:
+		return cmp <= 0
+	case FilterOpGreater:
+		return cmp > 0
+	case FilterOpGreaterEqual:
+		return cmp >= 0
+	case FilterOpEqual:
+		return cmp == 0
+	case FilterOpNotEqual:
+		return cmp != 0
+	}
+	return false
+}
+
+// Validate checks that all referenced columns exist and the values are well formed
+func (q *Query) Validate() error {
+	for _, filter := range q.Where {
+		if err := filter.validate(); err != nil {
+			return err
+		}
+	}
+	if q.SortBy != "" {
+		if _, ok := columnRegistry[q.SortBy]; !ok {
+			return NewInvalidInputError(fmt.Sprintf("unsupported sort column: %s, supported columns: %s",
+				q.SortBy, strings.Join(AvailableColumns(), ", "))).WithContext("sort", q.SortBy)
+		}
+	}
+	if q.Top < 0 {
+		return NewInvalidInputError(fmt.Sprintf("top must not be negative: %d", q.Top))
+	}
+	return nil
+}
+
+// Apply returns the files selected by the query. The input slice is not modified.
+func (q *Query) Apply(fcs []*FileCounter) []*FileCounter {
+	grandTotal := 0
+	for _, fc := range fcs {
+		grandTotal += fc.TotalChars
+	}
+
+	type entry struct {
+		fc *FileCounter
+		in *columnInput
+	}
+
+	entries := make([]entry, 0, len(fcs))
+	for _, fc := range fcs {
+		in := fc.columnInput(grandTotal)
+		matched := true
+		for _, filter := range q.Where {
+			if !filter.match(in) {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			entries = append(entries, entry{fc: fc, in: in})
+		}
+	}
+
+	if q.SortBy != "" {
+		value := columnRegistry[q.SortBy].value
+		sort.SliceStable(entries, func(i, j int) bool {
+			cmp := compareValues(value(entries[i].in), value(entries[j].in))
+			if q.Desc {
+				return cmp > 0
+			}
+			return cmp < 0
+		})
+	}
+
+	if q.Top > 0 && len(entries) > q.Top {
+		entries = entries[:q.Top]
+	}
+
+	result := make([]*FileCounter, 0, len(entries))
+	for _, e := range entries {
+		result = append(result, e.fc)
+	}
+	return result
+}
+
+func compareValues(a, b any) int {
+	textA, okA := a.(string)
+	textB, okB := b.(string)
+	if okA && okB {
+		return strings.Compare(textA, textB)
+	}
+	return compareFloat(toFloat(a), toFloat(b))
+}
+
+func compareFloat(a, b float64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
+}
+
+func toFloat(value any) float64 {
+	switch v := value.(type) {
+	case int:
+		return float64(v)
+	case int64:
+		return float64(v)
+	case float64:
+		return v
+	default:
+		return 0
+	}
+}
@@ -0,0 +1,190 @@
+package wordcounter_test
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	wcg "github.com/100gle/wordcounter"
+)
+
+func TestParseFilter(t *testing.T) {
+	tests := []struct {
+		name    string
+		expr    string
+		want    wcg.Filter
+		wantErr bool
+	}{
+		{
+			name: "Less than",
+			expr: "total_chars < 500",
+			want: wcg.Filter{Column: "total_chars", Op: "<", Value: "500"},
+		},
+		{
+			name: "Greater or equal without spaces",
+			expr: "chinese_ratio>=0.5",
+			want: wcg.Filter{Column: "chinese_ratio", Op: ">=", Value: "0.5"},
+		},
+		{
+			name: "Quoted glob",
+			expr: "file ~ 'ch<1>*.md'",
+			want: wcg.Filter{Column: "file", Op: "~", Value: "ch<1>*.md"},
+		},
+		{
+			name: "Text comparison",
+			expr: `modified >= "2024-01-01"`,
+			want: wcg.Filter{Column: "modified", Op: ">=", Value: "2024-01-01"},
+		},
+		{
+			name:    "Missing operator",
+			expr:    "total_chars 500",
+			wantErr: true,
+		},
+		{
+			name:    "Missing column",
+			expr:    "< 500",
+			wantErr: true,
+		},
+		{
+			name:    "Unknown column",
+			expr:    "paragraphs < 500",
+			wantErr: true,
+		},
+		{
+			name:    "Non numeric value",
+			expr:    "lines > many",
+			wantErr: true,
+		},
+		{
+			name:    "Invalid glob",
+			expr:    "file ~ [",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := wcg.ParseFilter(tt.expr)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseFilter() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && got != tt.want {
+				t.Errorf("ParseFilter() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDirCounter_SetQuery(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"ch01.md":   "第一章",
+		"ch02.md":   "第二章的内容比较长",
+		"ch03.md":   "第三章，还没写",
+		"notes.txt": "some notes",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write test file: %v", err)
+		}
+	}
+
+	tests := []struct {
+		name      string
+		query     *wcg.Query
+		wantFiles []string
+		wantErr   bool
+	}{
+		{
+			name:      "No query keeps walk order",
+			query:     nil,
+			wantFiles: []string{"ch01.md", "ch02.md", "ch03.md", "notes.txt"},
+		},
+		{
+			name:      "Sort descending",
+			query:     &wcg.Query{SortBy: "chinese_chars", Desc: true},
+			wantFiles: []string{"ch02.md", "ch03.md", "ch01.md", "notes.txt"},
+		},
+		{
+			name:      "Sort ascending with top",
+			query:     &wcg.Query{SortBy: "total_chars", Top: 2},
+			wantFiles: []string{"ch01.md", "ch03.md"},
+		},
+		{
+			name: "Filters are combined",
+			query: &wcg.Query{Where: []wcg.Filter{
+				{Column: "file", Op: "~", Value: "*.md"},
+				{Column: "total_chars", Op: "<", Value: "8"},
+			}},
+			wantFiles: []string{"ch01.md", "ch03.md"},
+		},
+		{
+			name:    "Invalid sort column",
+			query:   &wcg.Query{SortBy: "unknown"},
+			wantErr: true,
+		},
+		{
+			name:    "Negative top",
+			query:   &wcg.Query{Top: -1},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dc := wcg.NewDirCounterWithPathMode(dir, wcg.PathDisplayRelative)
+			if err := dc.SetQuery(tt.query); (err != nil) != tt.wantErr {
+				t.Fatalf("DirCounter.SetQuery() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if err := dc.SetColumns("file"); err != nil {
+				t.Fatalf("DirCounter.SetColumns() error = %v", err)
+			}
+			if err := dc.Count(); err != nil {
+				t.Fatalf("DirCounter.Count() error = %v", err)
+			}
+
+			var got []string
+			for _, row := range dc.GetRows() {
+				got = append(got, row[0].(string))
+			}
+			if !reflect.DeepEqual(got, tt.wantFiles) {
+				t.Errorf("DirCounter.GetRows() files = %v, want %v", got, tt.wantFiles)
+			}
+			if len(dc.FilteredFileCounters()) != len(tt.wantFiles) {
+				t.Errorf("DirCounter.FilteredFileCounters() = %d files, want %d", len(dc.FilteredFileCounters()), len(tt.wantFiles))
+			}
+			if len(dc.GetFileCounters()) != len(files) {
+				t.Errorf("DirCounter.GetFileCounters() = %d files, want all %d", len(dc.GetFileCounters()), len(files))
+			}
+		})
+	}
+}
+
+func TestDirCounter_QueryTotal(t *testing.T) {
+	dc := wcg.NewDirCounter("testdata")
+	dc.EnableTotal()
+	if err := dc.SetColumns("file", "total_chars", "percent"); err != nil {
+		t.Fatalf("DirCounter.SetColumns() error = %v", err)
+	}
+	if err := dc.SetQuery(&wcg.Query{Where: []wcg.Filter{{Column: "file", Op: "~", Value: "foo.md"}}}); err != nil {
+		t.Fatalf("DirCounter.SetQuery() error = %v", err)
+	}
+	if err := dc.Count(); err != nil {
+		t.Fatalf("DirCounter.Count() error = %v", err)
+	}
+
+	rows := dc.GetRows()
+	if len(rows) != 2 {
+		t.Fatalf("DirCounter.GetRows() = %v, want one file and the total", rows)
+	}
+	total := rows[1]
+	if total[1] != rows[0][1] {
+		t.Errorf("total row = %v, want only the selected files summed", total)
+	}
+	if total[2].(float64) >= 100 {
+		t.Errorf("total row percent = %v, want it relative to all counted files", total[2])
+	}
+}
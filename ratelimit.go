@@ -0,0 +1,68 @@
+package wordcounter
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+// RateLimit limits the requests of every client with a token bucket
+type RateLimit struct {
+	Rate  float64 // Requests per second, 0 for no limit
+	Burst int     // Requests allowed at once, at least 1
+}
+
+// rateLimiter holds the token buckets of the clients, its zero value is ready to use
+type rateLimiter struct {
+	mu        sync.Mutex
+	buckets   map[string]*tokenBucket
+	lastSweep time.Time
+}
+
+type tokenBucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// rateSweepInterval is how often clients with a full bucket are forgotten
+const rateSweepInterval = time.Minute
+
+// allow takes a token from the bucket of the client, or returns how long to wait for one
+func (l *rateLimiter) allow(client string, limit RateLimit, now time.Time) (bool, time.Duration) {
+	if limit.Rate <= 0 {
+		return true, 0
+	}
+	burst := float64(max(limit.Burst, 1))
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.buckets == nil {
+		l.buckets = make(map[string]*tokenBucket)
+	}
+	if now.Sub(l.lastSweep) >= rateSweepInterval {
+		l.sweep(now, limit, burst)
+	}
+
+	bucket, ok := l.buckets[client]
+	if !ok {
+		bucket = &tokenBucket{tokens: burst, last: now}
+		l.buckets[client] = bucket
+	}
+	bucket.tokens = math.Min(burst, bucket.tokens+now.Sub(bucket.last).Seconds()*limit.Rate)
+	bucket.last = now
+	if bucket.tokens >= 1 {
+		bucket.tokens--
+		return true, 0
+	}
+	return false, time.Duration((1 - bucket.tokens) / limit.Rate * float64(time.Second))
+}
+
+// sweep forgets the clients whose bucket has refilled, as they start over with a full one anyway
+func (l *rateLimiter) sweep(now time.Time, limit RateLimit, burst float64) {
+	l.lastSweep = now
+	for client, bucket := range l.buckets {
+		if bucket.tokens+now.Sub(bucket.last).Seconds()*limit.Rate >= burst {
+			delete(l.buckets, client)
+		}
+	}
+}
@@ -1,64 +1,430 @@
 package wordcounter
 
 import (
+	"context"
+	"crypto/tls"
+	"errors"
 	"fmt"
+	"log/slog"
+	"mime"
+	"net"
 	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/labstack/echo/v4"
 )
 
+// ServerConfig configures the HTTP server of WordCounterServer
+type ServerConfig struct {
+	Host            string
+	Port            int           // 0 picks a free port
+	ReadTimeout     time.Duration // Maximum duration for reading a request, including the body
+	WriteTimeout    time.Duration // Maximum duration before timing out writes of the response
+	IdleTimeout     time.Duration // Maximum time to wait for the next request on keep-alive connections
+	ShutdownTimeout time.Duration // Maximum time to drain in-flight requests when shutting down
+	MaxHeaderBytes  int
+	MaxBatchItems   int          // Maximum number of documents of a batch request, 0 for no limit
+	MaxBodySize     int64        // Maximum size of a request body in bytes, larger ones get 413, 0 for no limit
+	AccessLog       *slog.Logger // Logs every request when not nil
+	APIKeys         []string     // Keys accepted as bearer tokens or X-API-Key headers, no authentication if empty
+	IPRateLimit     RateLimit    // Limits the requests of every client IP
+	KeyRateLimit    RateLimit    // Limits the requests of every API key
+	CORSOrigins     []string     // Origins of the browsers allowed to call the API, "*" for any, none if empty
+	TrustProxy      bool         // Take client IPs from X-Forwarded-For, behind a reverse proxy
+	Socket          string       // Unix domain socket to listen on instead of Host and Port
+	TLSCertFile     string       // Serve HTTPS with this certificate and TLSKeyFile, reloaded when they change
+	TLSKeyFile      string
+}
+
+// DefaultServerConfig returns the configuration used by NewWordCounterServer
+func DefaultServerConfig() ServerConfig {
+	return ServerConfig{
+		Host:            DefaultHost,
+		Port:            DefaultPort,
+		ReadTimeout:     DefaultReadTimeout,
+		WriteTimeout:    DefaultWriteTimeout,
+		IdleTimeout:     DefaultIdleTimeout,
+		ShutdownTimeout: DefaultShutdownTimeout,
+		MaxHeaderBytes:  DefaultMaxHeaderBytes,
+		MaxBatchItems:   DefaultMaxBatchItems,
+		MaxBodySize:     DefaultMaxBodySize,
+	}
+}
+
+// Address returns the listen address, host:port
+func (c ServerConfig) Address() string {
+	return net.JoinHostPort(c.Host, strconv.Itoa(c.Port))
+}
+
 type WordCounterServer struct {
-	Echo *echo.Echo
+	Echo    *echo.Echo
+	Config  ServerConfig // Read by Start, change it before starting
+	Metrics *Metrics
+
+	mu             sync.Mutex
+	listener       net.Listener
+	done           chan error
+	ipLimiter      rateLimiter
+	keyLimiter     rateLimiter
+	corsOnce       sync.Once
+	corsMiddleware echo.MiddlewareFunc
 }
 
+// MIMETextMarkdown is the media type of Markdown documents
+const MIMETextMarkdown = "text/markdown"
+
 type CountBody struct {
-	Content string `json:"content"`
+	Content string        `json:"content"`
+	Options *CountOptions `json:"options,omitempty"` // Takes precedence over the options of the query parameters
 }
 
 func NewWordCounterServer() *WordCounterServer {
 	echoServer := echo.New()
 	echoServer.HideBanner = true
-	return &WordCounterServer{Echo: echoServer}
+	s := &WordCounterServer{Echo: echoServer, Config: DefaultServerConfig(), Metrics: NewMetrics()}
+	echoServer.HTTPErrorHandler = s.HTTPErrorHandler
+	echoServer.IPExtractor = s.clientIP
+	s.routes()
+	return s
+}
+
+// routes registers the API endpoints
+func (s *WordCounterServer) routes() {
+	s.Echo.Use(s.observe, s.cors, s.limitIP, s.authenticate, s.limitKey, s.limitBody)
+	s.Echo.GET(MetricsEndpoint, s.Metrics.Handler())
+	s.Echo.GET(PingEndpoint, func(c echo.Context) error {
+		return c.String(http.StatusOK, "pong")
+	})
+	s.Echo.POST(CountEndpoint, s.Count)
+	s.Echo.POST(UploadEndpoint, s.Upload)
+	s.Echo.POST(BatchEndpoint, s.Batch)
+	s.Echo.GET(OpenAPIEndpoint, s.OpenAPI)
+	s.Echo.GET(DocsEndpoint, s.Docs)
 }
 
+// Count counts the content of a JSON CountBody, or a text/plain, text/markdown or
+// application/octet-stream body streamed through the counter without buffering it.
 func (s *WordCounterServer) Count(c echo.Context) error {
 	body := new(CountBody)
-	errMsg := ""
-	counter := NewCounter()
+	lang := requestLanguage(c)
+
+	options, err := requestOptions(c.QueryParam)
+	if err != nil {
+		return invalidOptions(c, err, "")
+	}
 
 	// Check if request has a body
 	if c.Request().ContentLength == 0 {
-		errMsg = "request body is empty"
-		return c.JSON(http.StatusUnprocessableEntity, map[string]any{
-			"msg":   "parse failed",
-			"error": errMsg,
-		})
+		return respondError(c, MsgParseFailed, NewInvalidInputError(Translate(lang, MsgRequestBodyEmpty)))
 	}
 
-	if err := c.Bind(body); err != nil {
-		errMsg = fmt.Sprintf("%s", err)
-		return c.JSON(http.StatusUnprocessableEntity, map[string]any{
-			"msg":   "parse failed",
-			"error": errMsg,
-		})
+	counter := NewCounterWithOptions(options)
+	if isRawContent(c.Request()) {
+		body := &countingReader{Reader: c.Request().Body}
+		if err := counter.CountReader(body); err != nil {
+			return s.bodyError(c, err, "")
+		}
+		if body.n == 0 {
+			return respondError(c, MsgParseFailed, NewInvalidInputError(Translate(lang, MsgRequestBodyEmpty)))
+		}
+		s.Metrics.ObserveCount(body.n, counter.Stats)
+	} else {
+		if err := c.Bind(body); err != nil {
+			return s.bodyError(c, err, "options.")
+		}
+		if body.Options != nil {
+			options = *body.Options
+			counter.SetOptions(options)
+		}
+		if err := counter.Count(body.Content); err != nil {
+			return respondError(c, MsgRequestFailed, err)
+		}
+		s.Metrics.ObserveCount(int64(len(body.Content)), counter.Stats)
 	}
 
-	err := counter.Count(body.Content)
-	if err != nil {
-		errMsg = fmt.Sprintf("%s", err)
-	}
 	return c.JSON(http.StatusOK, map[string]any{
-		"msg":   "ok",
-		"data":  counter.Stats,
-		"error": errMsg,
+		"msg":     Translate(lang, MsgOK),
+		"data":    counter.Stats,
+		"options": options,
+		"error":   "",
 	})
 }
 
-func (s *WordCounterServer) Run(port int) error {
-	s.Echo.GET(PingEndpoint, func(c echo.Context) error {
-		return c.String(http.StatusOK, "pong")
-	})
-	s.Echo.POST(CountEndpoint, s.Count)
+// isRawContent reports whether the request body is the text itself rather than a CountBody
+func isRawContent(req *http.Request) bool {
+	mediaType, _, _ := mime.ParseMediaType(req.Header.Get(echo.HeaderContentType))
+	switch mediaType {
+	case echo.MIMETextPlain, MIMETextMarkdown, echo.MIMEOctetStream:
+		return true
+	}
+	return false
+}
+
+// limitBody rejects request bodies larger than Config.MaxBodySize, up front when the
+// Content-Length is known, otherwise while handlers read them, see isBodyTooLarge
+func (s *WordCounterServer) limitBody(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		limit := s.Config.MaxBodySize
+		if limit <= 0 {
+			return next(c)
+		}
+		req := c.Request()
+		if req.ContentLength > limit {
+			return respondError(c, MsgBodyTooLarge, NewRequestTooLargeError(limit))
+		}
+		req.Body = http.MaxBytesReader(c.Response(), req.Body, limit)
+		return next(c)
+	}
+}
+
+// isBodyTooLarge reports whether reading the request body failed because it exceeds Config.MaxBodySize
+func isBodyTooLarge(err error) bool {
+	var maxBytesErr *http.MaxBytesError
+	return errors.As(err, &maxBytesErr)
+}
+
+// bodyError responds to an error reading the request body: 413 when it is too large, 422 naming
+// the field of invalid counting options prefixed by prefix, or 422 when it cannot be parsed
+func (s *WordCounterServer) bodyError(c echo.Context, err error, prefix string) error {
+	switch {
+	case isBodyTooLarge(err):
+		return respondError(c, MsgBodyTooLarge, NewRequestTooLargeError(s.Config.MaxBodySize))
+	case isOptionsError(err):
+		return invalidOptions(c, err, prefix)
+	default:
+		return respondError(c, MsgParseFailed, NewError(ErrorTypeInvalidInput, "invalid request body", err))
+	}
+}
+
+// requestOptions reads the counting options from request values such as query parameters
+func requestOptions(value func(name string) string) (CountOptions, error) {
+	options := CountOptions{}
+	for _, key := range []string{OptionStripMarkdown, OptionExcludePunctuation, OptionCountWords} {
+		if v := value(key); v != "" {
+			if err := options.Set(key, v); err != nil {
+				return options, err
+			}
+		}
+	}
+	return options, nil
+}
+
+// isOptionsError reports whether a bind error comes from invalid counting options
+func isOptionsError(err error) bool {
+	var wcErr *WordCounterError
+	return errors.As(err, &wcErr) && wcErr.Context["field"] != nil
+}
+
+// invalidOptions responds 422 with the invalid option in the "field" context, its name prefixed by prefix
+func invalidOptions(c echo.Context, err error, prefix string) error {
+	var wcErr *WordCounterError
+	if !errors.As(err, &wcErr) {
+		return respondError(c, MsgInvalidOptions, NewInvalidInputError(err.Error()))
+	}
+	field := strings.TrimSuffix(prefix+fmt.Sprint(wcErr.Context["field"]), ".")
+	return respondError(c, MsgInvalidOptions, NewError(wcErr.Type, wcErr.Message, wcErr.Cause).WithContext("field", field))
+}
+
+// requestLanguage returns the supported language of the Accept-Language header with the
+// highest q-value, or the current language if the client did not ask for one.
+// Languages with q=0 are not acceptable to the client.
+func requestLanguage(c echo.Context) string {
+	type weightedTag struct {
+		tag string
+		q   float64
+	}
+	var tags []weightedTag
+	for _, part := range strings.Split(c.Request().Header.Get("Accept-Language"), ",") {
+		tag, params, _ := strings.Cut(part, ";")
+		q := 1.0
+		for _, param := range strings.Split(params, ";") {
+			name, value, _ := strings.Cut(strings.TrimSpace(param), "=")
+			if strings.EqualFold(name, "q") {
+				parsed, err := strconv.ParseFloat(value, 64)
+				if err != nil {
+					parsed = 0
+				}
+				q = parsed
+			}
+		}
+		if q > 0 {
+			tags = append(tags, weightedTag{tag, q})
+		}
+	}
+	// Stable, so that languages of the same weight keep the order of the client
+	sort.SliceStable(tags, func(i, j int) bool { return tags[i].q > tags[j].q })
 
-	return s.Echo.Start(fmt.Sprintf(":%d", port))
+	for _, t := range tags {
+		if lang, ok := NormalizeLanguage(t.tag); ok {
+			return lang
+		}
+	}
+	return Language()
+}
+
+// Start listens on the configured address or socket and serves requests in the background,
+// over TLS when a certificate is configured.
+// It returns once the server accepts connections, see Addr, Wait and Shutdown.
+func (s *WordCounterServer) Start() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.listener != nil {
+		return NewInvalidInputError("server is already started")
+	}
+	if (s.Config.TLSCertFile == "") != (s.Config.TLSKeyFile == "") {
+		return NewInvalidInputError("TLS needs both a certificate and a key file")
+	}
+
+	listener, err := s.listen()
+	if err != nil {
+		return err
+	}
+	var certs *certReloader
+	if s.Config.TLSCertFile != "" {
+		certs, err = newCertReloader(s.Config.TLSCertFile, s.Config.TLSKeyFile, func(err error) {
+			s.Echo.Logger.Error(err)
+		})
+		if err != nil {
+			listener.Close()
+			return err
+		}
+		listener = tls.NewListener(listener, &tls.Config{
+			GetCertificate: certs.getCertificate,
+			MinVersion:     tls.VersionTLS12,
+		})
+	}
+
+	server := s.Echo.Server
+	server.Handler = s.Echo
+	server.ReadTimeout = s.Config.ReadTimeout
+	server.WriteTimeout = s.Config.WriteTimeout
+	server.IdleTimeout = s.Config.IdleTimeout
+	server.MaxHeaderBytes = s.Config.MaxHeaderBytes
+
+	s.listener = listener
+	s.done = make(chan error, 1)
+	go func(done chan<- error) {
+		err := server.Serve(listener)
+		if certs != nil {
+			certs.Close()
+		}
+		done <- err
+	}(s.done)
+	return nil
+}
+
+// listen listens on Config.Socket, or else on Config.Address
+func (s *WordCounterServer) listen() (net.Listener, error) {
+	if s.Config.Socket == "" {
+		return net.Listen("tcp", s.Config.Address())
+	}
+	if err := removeStaleSocket(s.Config.Socket); err != nil {
+		return nil, err
+	}
+	return net.Listen("unix", s.Config.Socket)
+}
+
+// removeStaleSocket removes the socket file left by a server that did not stop cleanly.
+// A socket accepting connections belongs to a running server and is kept.
+func removeStaleSocket(path string) error {
+	info, err := os.Lstat(path)
+	if err != nil || info.Mode()&os.ModeSocket == 0 {
+		return nil // Listen reports whatever is in the way
+	}
+	if conn, err := net.DialTimeout("unix", path, time.Second); err == nil {
+		conn.Close()
+		return NewServerError(fmt.Sprintf("socket is in use: %s", path), nil).WithContext("path", path)
+	}
+	return os.Remove(path)
+}
+
+// URL returns the URL of the started server, e.g. https://127.0.0.1:8443 or
+// http+unix:///run/wcg.sock, empty if it is not started
+func (s *WordCounterServer) URL() string {
+	addr := s.Addr()
+	if addr == nil {
+		return ""
+	}
+	scheme := "http"
+	if s.Config.TLSCertFile != "" {
+		scheme = "https"
+	}
+	if addr.Network() == "unix" {
+		return scheme + "+unix://" + addr.String()
+	}
+	return scheme + "://" + addr.String()
+}
+
+// Addr returns the address the server listens on, nil if it is not started
+func (s *WordCounterServer) Addr() net.Addr {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.listener == nil {
+		return nil
+	}
+	return s.listener.Addr()
+}
+
+// Wait blocks until the started server stops, and returns http.ErrServerClosed after Shutdown
+func (s *WordCounterServer) Wait() error {
+	s.mu.Lock()
+	done := s.done
+	s.mu.Unlock()
+	if done == nil {
+		return NewInvalidInputError("server is not started")
+	}
+
+	err := <-done
+	done <- err // Let other callers of Wait see it too
+	return err
+}
+
+// Shutdown stops accepting connections and waits for in-flight requests to complete,
+// until the context is done
+func (s *WordCounterServer) Shutdown(ctx context.Context) error {
+	return s.Echo.Server.Shutdown(ctx)
+}
+
+// Serve starts the server if it is not started yet, and shuts it down gracefully when
+// the context is done, draining in-flight requests for at most Config.ShutdownTimeout
+func (s *WordCounterServer) Serve(ctx context.Context) error {
+	if s.Addr() == nil {
+		if err := s.Start(); err != nil {
+			return err
+		}
+	}
+
+	select {
+	case err := <-s.done:
+		s.done <- err
+		return err
+	case <-ctx.Done():
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), s.Config.ShutdownTimeout)
+	defer cancel()
+	if err := s.Shutdown(shutdownCtx); err != nil {
+		return err
+	}
+	if err := s.Wait(); !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// Run serves on all interfaces at the port until Shutdown is called.
+//
+// Deprecated: set Config and use Serve, which honors the host and shuts down gracefully.
+func (s *WordCounterServer) Run(port int) error {
+	s.Config.Host = ""
+	s.Config.Port = port
+	if err := s.Start(); err != nil {
+		return err
+	}
+	return s.Wait()
 }
@@ -3,9 +3,14 @@ package wordcounter_test
 import (
 	"context"
 	"fmt"
+	"io"
 	"net"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
 	"testing"
 	"time"
 
@@ -99,7 +104,7 @@ func TestWordCounterServer_Count(t *testing.T) {
 		{
 			name:       "Testing Count with empty string",
 			content:    &wcg.CountBody{Content: ""},
-			statusCode: http.StatusOK,
+			statusCode: http.StatusUnprocessableEntity,
 			want:       &wcg.Stats{},
 		},
 		{
@@ -107,7 +112,7 @@ func TestWordCounterServer_Count(t *testing.T) {
 			content: &struct {
 				Foo int `json:"foo"`
 			}{Foo: 1},
-			statusCode: http.StatusOK,
+			statusCode: http.StatusUnprocessableEntity,
 			want:       &wcg.Stats{},
 		},
 		{
@@ -127,8 +132,9 @@ func TestWordCounterServer_Count(t *testing.T) {
 					JSON().
 					Object().
 					ContainsKey("msg").
-					ContainsKey("error")
-			} else {
+					ContainsKey("error").
+					HasValue("code", "invalid_input")
+			} else if tt.statusCode == http.StatusOK {
 				req.WithJSON(tt.content).Expect().
 					Status(tt.statusCode).
 					JSON().
@@ -136,6 +142,15 @@ func TestWordCounterServer_Count(t *testing.T) {
 					ContainsKey("msg").
 					ContainsKey("data").
 					ContainsKey("error")
+			} else {
+				req.WithJSON(tt.content).Expect().
+					Status(tt.statusCode).
+					JSON().
+					Object().
+					ContainsKey("msg").
+					ContainsKey("error").
+					HasValue("code", "invalid_input").
+					NotContainsKey("data")
 			}
 		})
 	}
@@ -214,16 +229,16 @@ func TestWordCounterServer_CountErrorHandling(t *testing.T) {
 		},
 	})
 
-	// Test with empty content - should trigger error in Count function
+	// Test with empty content - the error of the Count function is the error response
 	e.POST(apiPath).
 		WithJSON(&wcg.CountBody{Content: ""}).
 		Expect().
-		Status(http.StatusOK).
+		Status(http.StatusUnprocessableEntity).
 		JSON().
 		Object().
-		ContainsKey("msg").
-		ContainsKey("data").
-		ContainsKey("error").
+		HasValue("msg", "request failed").
+		HasValue("code", "invalid_input").
+		NotContainsKey("data").
 		Value("error").NotEqual("")
 
 	// Test with valid content but empty string (edge case)
@@ -265,6 +280,17 @@ func TestWordCounterServer_CountEmptyBody(t *testing.T) {
 		ContainsKey("msg").
 		ContainsKey("error").
 		Value("msg").String().Equal("parse failed")
+
+	// Test with an empty chunked body, whose length is unknown until it is read
+	e.POST(apiPath).
+		WithHeader("Content-Type", "text/plain").
+		WithChunked(strings.NewReader("")).
+		Expect().
+		Status(http.StatusUnprocessableEntity).
+		JSON().
+		Object().
+		HasValue("msg", "parse failed").
+		HasValue("code", "invalid_input")
 }
 
 // TestWordCounterServer_CountInvalidJSON tests Count function with invalid JSON
@@ -285,9 +311,10 @@ func TestWordCounterServer_CountInvalidJSON(t *testing.T) {
 		},
 	})
 
-	// Test with invalid JSON
+	// Test with invalid JSON, text/plain bodies being counted as they are
 	e.POST(apiPath).
-		WithText("invalid json {").
+		WithHeader("Content-Type", "application/json").
+		WithBytes([]byte("invalid json {")).
 		Expect().
 		Status(http.StatusUnprocessableEntity).
 		JSON().
@@ -296,3 +323,220 @@ func TestWordCounterServer_CountInvalidJSON(t *testing.T) {
 		ContainsKey("error").
 		Value("msg").Equal("parse failed")
 }
+
+// TestWordCounterServer_CountRawBody tests counting text/plain, text/markdown and
+// application/octet-stream bodies, and the maximum body size
+func TestWordCounterServer_CountRawBody(t *testing.T) {
+	server := wcg.NewWordCounterServer()
+	server.Config.MaxBodySize = 64
+	testServer := httptest.NewServer(server.Echo)
+	defer testServer.Close()
+
+	e := httpexpect.WithConfig(httpexpect.Config{
+		BaseURL:  testServer.URL,
+		Reporter: httpexpect.NewAssertReporter(t),
+	})
+
+	tests := []struct {
+		name        string
+		contentType string
+		query       map[string]string
+		body        string
+		want        map[string]int
+	}{
+		{"text", "text/plain; charset=utf-8", nil, "你好，\nworld", map[string]int{"lines": 2, "chinese_chars": 3, "non_chinese_chars": 5, "total_chars": 8}},
+		{"markdown", "text/markdown", map[string]string{"strip_markdown": "true"}, "# 标题\n**你好**", map[string]int{"lines": 2, "chinese_chars": 4, "total_chars": 4}},
+		{"octet stream", "application/octet-stream", map[string]string{"count_words": "1"}, "hello world", map[string]int{"lines": 1, "non_chinese_chars": 11, "total_chars": 11, "words": 2}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := e.POST(wcg.CountEndpoint).
+				WithHeader("Content-Type", tt.contentType).
+				WithBytes([]byte(tt.body))
+			for key, value := range tt.query {
+				req = req.WithQuery(key, value)
+			}
+			req.Expect().
+				Status(http.StatusOK).
+				JSON().Object().
+				HasValue("data", tt.want).
+				HasValue("error", "")
+		})
+	}
+
+	large := strings.Repeat("你好", 20)
+	t.Run("too large", func(t *testing.T) {
+		e.POST(wcg.CountEndpoint).
+			WithText(large).
+			Expect().
+			Status(http.StatusRequestEntityTooLarge).
+			JSON().Object().
+			HasValue("msg", "request body too large").
+			ContainsKey("error")
+	})
+
+	t.Run("too large without content length", func(t *testing.T) {
+		for _, contentType := range []string{"text/plain", "application/json"} {
+			e.POST(wcg.CountEndpoint).
+				WithHeader("Content-Type", contentType).
+				WithChunked(strings.NewReader(`{"content": "` + large + `"}`)).
+				Expect().
+				Status(http.StatusRequestEntityTooLarge)
+		}
+		e.POST(wcg.BatchEndpoint).
+			WithHeader("Content-Type", "application/json").
+			WithChunked(strings.NewReader(`[{"id": "a", "content": "` + large + `"}]`)).
+			Expect().
+			Status(http.StatusRequestEntityTooLarge)
+	})
+
+	t.Run("no limit", func(t *testing.T) {
+		server.Config.MaxBodySize = 0
+		defer func() { server.Config.MaxBodySize = 64 }()
+		e.POST(wcg.CountEndpoint).
+			WithText(large).
+			Expect().
+			Status(http.StatusOK).
+			JSON().Object().
+			Value("data").Object().HasValue("chinese_chars", 40)
+	})
+}
+
+func TestDefaultServerConfig(t *testing.T) {
+	config := wcg.DefaultServerConfig()
+	if config.Address() != "127.0.0.1:8080" {
+		t.Errorf("Address() = %s, want 127.0.0.1:8080", config.Address())
+	}
+	if config.ReadTimeout <= 0 || config.WriteTimeout <= 0 || config.IdleTimeout <= 0 ||
+		config.ShutdownTimeout <= 0 || config.MaxHeaderBytes <= 0 || config.MaxBodySize <= 0 {
+		t.Errorf("DefaultServerConfig() = %+v, want positive limits", config)
+	}
+	if server := wcg.NewWordCounterServer(); !reflect.DeepEqual(server.Config, config) {
+		t.Errorf("NewWordCounterServer() config = %+v, want %+v", server.Config, config)
+	}
+}
+
+// TestWordCounterServer_Serve tests that the server binds the configured host
+// and drains in-flight requests when the context is canceled
+func TestWordCounterServer_Serve(t *testing.T) {
+	server := wcg.NewWordCounterServer()
+	server.Config.Port = 0
+	server.Config.ShutdownTimeout = 5 * time.Second
+
+	started := make(chan struct{})
+	server.Echo.GET("/slow", func(c echo.Context) error {
+		close(started)
+		time.Sleep(200 * time.Millisecond)
+		return c.String(http.StatusOK, "done")
+	})
+
+	if server.Addr() != nil {
+		t.Errorf("Addr() = %v before Start, want nil", server.Addr())
+	}
+	if err := server.Start(); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	if err := server.Start(); err == nil {
+		t.Error("Start() of a started server should return error")
+	}
+	addr := server.Addr().(*net.TCPAddr)
+	if !addr.IP.IsLoopback() || addr.Port == 0 {
+		t.Errorf("Addr() = %v, want a free port on the loopback host", addr)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- server.Serve(ctx)
+	}()
+
+	type result struct {
+		body string
+		err  error
+	}
+	response := make(chan result, 1)
+	go func() {
+		resp, err := http.Get(fmt.Sprintf("http://%s/slow", addr))
+		if err != nil {
+			response <- result{err: err}
+			return
+		}
+		defer resp.Body.Close()
+		body, err := io.ReadAll(resp.Body)
+		response <- result{body: string(body), err: err}
+	}()
+
+	<-started
+	cancel()
+
+	select {
+	case r := <-response:
+		if r.err != nil || r.body != "done" {
+			t.Errorf("in-flight request = %q, %v, want it to complete", r.body, r.err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("in-flight request did not complete")
+	}
+	select {
+	case err := <-serveErr:
+		if err != nil {
+			t.Errorf("Serve() error = %v", err)
+		}
+	case <-time.After(6 * time.Second):
+		t.Fatal("Serve() did not return after the context was canceled")
+	}
+
+	if _, err := http.Get(fmt.Sprintf("http://%s%s", addr, wcg.PingEndpoint)); err == nil {
+		t.Error("server still accepts connections after shutdown")
+	}
+}
+
+func TestWordCounterServer_Socket(t *testing.T) {
+	socket := filepath.Join(t.TempDir(), "wcg.sock")
+
+	// A socket file left by a server that did not stop cleanly
+	stale, err := net.Listen("unix", socket)
+	if err != nil {
+		t.Skipf("unix sockets are not supported: %v", err)
+	}
+	stale.(*net.UnixListener).SetUnlinkOnClose(false)
+	stale.Close()
+
+	server := wcg.NewWordCounterServer()
+	server.Config.Socket = socket
+	if err := server.Start(); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	if want := "http+unix://" + socket; server.URL() != want {
+		t.Errorf("URL() = %s, want %s", server.URL(), want)
+	}
+
+	other := wcg.NewWordCounterServer()
+	other.Config.Socket = socket
+	if err := other.Start(); err == nil {
+		t.Error("Start() on the socket of a running server should return error")
+	}
+
+	client := &http.Client{Transport: &http.Transport{
+		DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+			return (&net.Dialer{}).DialContext(ctx, "unix", socket)
+		},
+	}}
+	resp, err := client.Post(fmt.Sprintf("http://wordcounter%s", wcg.CountEndpoint), "text/plain", strings.NewReader("你好"))
+	if err != nil {
+		t.Fatalf("POST over the socket error = %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("POST over the socket status = %d, want 200", resp.StatusCode)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := server.Serve(ctx); err != nil {
+		t.Fatalf("Serve() error = %v", err)
+	}
+	if _, err := os.Stat(socket); !os.IsNotExist(err) {
+		t.Errorf("socket file remains after shutdown: %v", err)
+	}
+}
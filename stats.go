@@ -1,7 +1,8 @@
 package wordcounter
 
 import (
-	"reflect"
+	"fmt"
+	"strings"
 )
 
 type Stats struct {
@@ -9,6 +10,7 @@ type Stats struct {
 	ChineseChars    int `json:"chinese_chars,omitempty"`
 	NonChineseChars int `json:"non_chinese_chars,omitempty"`
 	TotalChars      int `json:"total_chars,omitempty"`
+	Words           int `json:"words,omitempty"` // Only counted with CountOptions.CountWords
 }
 
 func (s *Stats) ToRow() Row {
@@ -20,11 +22,62 @@ func (s *Stats) ToRow() Row {
 	}
 }
 
+// StatsFields are the keys of the Stats fields, in the same order as ToRow
+var StatsFields = []string{
+	ColumnLines,
+	ColumnChineseChars,
+	ColumnNonChineseChars,
+	ColumnTotalChars,
+}
+
+// Value returns the field identified by its key, see StatsFields, or ColumnWords
+func (s *Stats) Value(field string) (int, error) {
+	switch field {
+	case ColumnLines:
+		return s.Lines, nil
+	case ColumnChineseChars:
+		return s.ChineseChars, nil
+	case ColumnNonChineseChars:
+		return s.NonChineseChars, nil
+	case ColumnTotalChars:
+		return s.TotalChars, nil
+	case ColumnWords:
+		return s.Words, nil
+	default:
+		return 0, NewInvalidInputError(fmt.Sprintf("unsupported stats field: %s, supported fields: %s, %s",
+			field, strings.Join(StatsFields, ", "), ColumnWords)).WithContext("field", field)
+	}
+}
+
+// set assigns the field identified by its key, unknown keys are ignored
+func (s *Stats) set(field string, value int) {
+	switch field {
+	case ColumnLines:
+		s.Lines = value
+	case ColumnChineseChars:
+		s.ChineseChars = value
+	case ColumnNonChineseChars:
+		s.NonChineseChars = value
+	case ColumnTotalChars:
+		s.TotalChars = value
+	case ColumnWords:
+		s.Words = value
+	}
+}
+
+// statsHeaderKeys lists the header messages in the same order as ToRow
+var statsHeaderKeys = []MessageKey{
+	MsgHeaderLines,
+	MsgHeaderChineseChars,
+	MsgHeaderNonChineseChars,
+	MsgHeaderTotalChars,
+}
+
+// Header returns the column names of ToRow in the current language
 func (s *Stats) Header() Row {
-	var headers Row
-	t := reflect.TypeOf(*s)
-	for i := 0; i < t.NumField(); i++ {
-		headers = append(headers, t.Field(i).Name)
+	headers := make(Row, 0, len(statsHeaderKeys))
+	for _, key := range statsHeaderKeys {
+		headers = append(headers, T(key))
 	}
 	return headers
 }
@@ -35,3 +88,23 @@ func (s *Stats) HeaderAndRows() []Row {
 		s.ToRow(),
 	}
 }
+
+// add adds other to the statistics
+func (s *Stats) add(other *Stats) {
+	s.Lines += other.Lines
+	s.ChineseChars += other.ChineseChars
+	s.NonChineseChars += other.NonChineseChars
+	s.TotalChars += other.TotalChars
+	s.Words += other.Words
+}
+
+// sub returns the difference between the statistics and other
+func (s *Stats) sub(other *Stats) Stats {
+	return Stats{
+		Lines:           s.Lines - other.Lines,
+		ChineseChars:    s.ChineseChars - other.ChineseChars,
+		NonChineseChars: s.NonChineseChars - other.NonChineseChars,
+		TotalChars:      s.TotalChars - other.TotalChars,
+		Words:           s.Words - other.Words,
+	}
+}
@@ -107,3 +107,17 @@ func TestStats_HeaderAndRows(t *testing.T) {
 		})
 	}
 }
+
+func TestStats_Value(t *testing.T) {
+	s := &wcg.Stats{Lines: 1, ChineseChars: 2, NonChineseChars: 3, TotalChars: 5}
+	want := []int{1, 2, 3, 5}
+	for i, field := range wcg.StatsFields {
+		got, err := s.Value(field)
+		if err != nil || got != want[i] {
+			t.Errorf("Stats.Value(%s) = %d, %v, want %d", field, got, err, want[i])
+		}
+	}
+	if _, err := s.Value("paragraphs"); err == nil {
+		t.Error("Stats.Value() with unknown field should return error")
+	}
+}
@@ -0,0 +1,296 @@
+package wordcounter
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	htmltemplate "html/template"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+	"time"
+)
+
+//go:embed templates/*.tmpl
+var bundledTemplates embed.FS
+
+// TemplateFile holds the statistics of a single file exposed to report templates.
+type TemplateFile struct {
+	Path string `json:"path"`
+	Stats
+}
+
+// TemplateMetadata describes the counting run a report was rendered from.
+type TemplateMetadata struct {
+	Root        string    `json:"root"`
+	Mode        string    `json:"mode"`
+	FileCount   int       `json:"file_count"`
+	GeneratedAt time.Time `json:"generated_at"`
+}
+
+// TemplateData is the value passed to report templates as the dot (".").
+// Columns, Header, Rows and TotalRow hold the report with the selected columns,
+// as in the table, CSV and JSON exports.
+type TemplateData struct {
+	Files    []TemplateFile   `json:"files"`
+	Total    Stats            `json:"total"`
+	Metadata TemplateMetadata `json:"metadata"`
+	Columns  []string         `json:"columns"`
+	Header   Row              `json:"header"`
+	Rows     []Row            `json:"rows"`
+	TotalRow Row              `json:"total_row"`
+}
+
+// NewTemplateData builds template data from the given file counters, reporting the default columns.
+// Totals are summed over all files regardless of whether the total row is enabled.
+func NewTemplateData(root string, mode string, fcs []*FileCounter) *TemplateData {
+	data := &TemplateData{
+		Files: make([]TemplateFile, 0, len(fcs)),
+		Metadata: TemplateMetadata{
+			Root:        root,
+			Mode:        mode,
+			FileCount:   len(fcs),
+			GeneratedAt: time.Now(),
+		},
+	}
+
+	for _, fc := range fcs {
+		data.Files = append(data.Files, TemplateFile{Path: fc.getDisplayPath(), Stats: *fc.Stats})
+		data.Total.Lines += fc.Lines
+		data.Total.ChineseChars += fc.ChineseChars
+		data.Total.NonChineseChars += fc.NonChineseChars
+		data.Total.TotalChars += fc.TotalChars
+	}
+	data.setReport(DefaultColumns, fcs, data.Total.TotalChars)
+
+	return data
+}
+
+// setReport sets the report of the given columns with a row per file and the total row.
+// Percentages are relative to grandTotal characters.
+func (d *TemplateData) setReport(columns []string, fcs []*FileCounter, grandTotal int) {
+	d.Columns, d.Header = columns, columnHeader(columns)
+	d.Rows = make([]Row, 0, len(fcs))
+	for _, fc := range fcs {
+		d.Rows = append(d.Rows, columnRow(columns, fc.columnInput(grandTotal)))
+	}
+	d.TotalRow = getTotal(fcs, columns, grandTotal)
+}
+
+// BundledTemplates returns the names of the templates shipped in the binary.
+func BundledTemplates() []string {
+	entries, err := bundledTemplates.ReadDir("templates")
+	if err != nil {
+		return nil
+	}
+
+	names := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		names = append(names, strings.TrimSuffix(entry.Name(), ".tmpl"))
+	}
+	return names
+}
+
+// RenderTemplate renders data through the named template.
+//
+// The name is looked up among the bundled templates first (see BundledTemplates)
+// and is otherwise treated as a path to a template file. Templates whose name
+// ends with ".html", ".htm", ".html.tmpl" or ".htm.tmpl" are rendered with
+// html/template so that file names are escaped, all others with text/template.
+func RenderTemplate(name string, data *TemplateData) (string, error) {
+	if name == "" {
+		return "", NewInvalidInputError("template name cannot be empty")
+	}
+
+	content, isBundled, err := loadTemplate(name)
+	if err != nil {
+		return "", err
+	}
+
+	templateName := filepath.Base(name)
+	if isBundled {
+		templateName = name + ".tmpl"
+	}
+
+	var buf bytes.Buffer
+	if isHTMLTemplate(templateName) {
+		tmpl, err := htmltemplate.New(templateName).Funcs(templateFuncs()).Parse(content)
+		if err != nil {
+			return "", NewExportError("template parse", err).WithContext("template", name)
+		}
+		err = tmpl.Execute(&buf, data)
+		if err != nil {
+			return "", NewExportError("template execute", err).WithContext("template", name)
+		}
+	} else {
+		tmpl, err := template.New(templateName).Funcs(templateFuncs()).Parse(content)
+		if err != nil {
+			return "", NewExportError("template parse", err).WithContext("template", name)
+		}
+		err = tmpl.Execute(&buf, data)
+		if err != nil {
+			return "", NewExportError("template execute", err).WithContext("template", name)
+		}
+	}
+
+	return buf.String(), nil
+}
+
+// loadTemplate returns the template source and whether it is a bundled template
+func loadTemplate(name string) (string, bool, error) {
+	if content, err := bundledTemplates.ReadFile("templates/" + name + ".tmpl"); err == nil {
+		return string(content), true, nil
+	}
+
+	file, err := os.Open(name)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "", false, NewFileNotFoundError(name, err)
+		}
+		return "", false, NewFileReadError(name, err)
+	}
+	defer file.Close()
+
+	content, err := io.ReadAll(file)
+	if err != nil {
+		return "", false, NewFileReadError(name, err)
+	}
+	return string(content), false, nil
+}
+
+func isHTMLTemplate(name string) bool {
+	name = strings.TrimSuffix(strings.ToLower(name), ".tmpl")
+	return name == "html" || strings.HasSuffix(name, ".html") || strings.HasSuffix(name, ".htm")
+}
+
+// exportToTemplate renders the template and optionally writes the result to a file
+func exportToTemplate(data *TemplateData, name string, filename ...string) (string, error) {
+	output, err := RenderTemplate(name, data)
+	if err != nil {
+		return "", err
+	}
+
+	if len(filename) > 0 && filename[0] != "" {
+		absPath, err := toAbsolutePathWithError(filename[0])
+		if err != nil {
+			return "", NewExportError("template export", err)
+		}
+		if err := os.WriteFile(absPath, []byte(output), 0644); err != nil {
+			return "", NewFileWriteError(absPath, err)
+		}
+	}
+	return output, nil
+}
+
+// templateFuncs returns the helper functions available in report templates:
+//   - number: formats an integer with thousands separators, e.g. 12,345
+//   - cell: formats a report value such as a row cell, integers as with number
+//   - percent: formats a/b as a percentage with one decimal, e.g. 12.5%
+//   - ratio: returns a/b as a float, 0 when b is 0
+//   - add, sub: integer arithmetic
+//   - sortBy, sortByDesc: return the files sorted by a field such as "chinese_chars"
+//   - upper, lower, repeat: string helpers
+//   - t: translates a message key such as "header.lines" into the current language
+func templateFuncs() map[string]any {
+	return map[string]any{
+		"t":       func(key string) string { return T(MessageKey(key)) },
+		"number":  formatNumber,
+		"cell":    formatCell,
+		"percent": formatPercent,
+		"ratio":   ratio,
+		"add":     func(a, b int) int { return a + b },
+		"sub":     func(a, b int) int { return a - b },
+		"sortBy": func(field string, files []TemplateFile) ([]TemplateFile, error) {
+			return sortTemplateFiles(files, field, false)
+		},
+		"sortByDesc": func(field string, files []TemplateFile) ([]TemplateFile, error) {
+			return sortTemplateFiles(files, field, true)
+		},
+		"upper":  strings.ToUpper,
+		"lower":  strings.ToLower,
+		"repeat": strings.Repeat,
+	}
+}
+
+// formatNumber formats an integer with comma thousands separators
+func formatNumber(n int) string {
+	s := fmt.Sprintf("%d", n)
+	negative := strings.HasPrefix(s, "-")
+	if negative {
+		s = s[1:]
+	}
+
+	var b strings.Builder
+	for i, r := range s {
+		if i > 0 && (len(s)-i)%3 == 0 {
+			b.WriteByte(',')
+		}
+		b.WriteRune(r)
+	}
+
+	if negative {
+		return "-" + b.String()
+	}
+	return b.String()
+}
+
+// formatCell formats a report value, integers with thousands separators
+func formatCell(value any) string {
+	switch v := value.(type) {
+	case int:
+		return formatNumber(v)
+	case int64:
+		return formatNumber(int(v))
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+func ratio(a, b int) float64 {
+	if b == 0 {
+		return 0
+	}
+	return float64(a) / float64(b)
+}
+
+func formatPercent(a, b int) string {
+	return fmt.Sprintf("%.1f%%", ratio(a, b)*100)
+}
+
+// sortTemplateFiles returns a sorted copy of files, keeping the original order for ties
+func sortTemplateFiles(files []TemplateFile, field string, desc bool) ([]TemplateFile, error) {
+	var key func(f TemplateFile) int
+	switch field {
+	case "lines":
+		key = func(f TemplateFile) int { return f.Lines }
+	case "chinese_chars":
+		key = func(f TemplateFile) int { return f.ChineseChars }
+	case "non_chinese_chars":
+		key = func(f TemplateFile) int { return f.NonChineseChars }
+	case "total_chars":
+		key = func(f TemplateFile) int { return f.TotalChars }
+	case "path":
+		// handled below
+	default:
+		return nil, NewInvalidInputError(fmt.Sprintf("unsupported sort field: %s", field))
+	}
+
+	sorted := make([]TemplateFile, len(files))
+	copy(sorted, files)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if key == nil {
+			if desc {
+				return sorted[i].Path > sorted[j].Path
+			}
+			return sorted[i].Path < sorted[j].Path
+		}
+		if desc {
+			return key(sorted[i]) > key(sorted[j])
+		}
+		return key(sorted[i]) < key(sorted[j])
+	})
+	return sorted, nil
+}
@@ -0,0 +1,201 @@
+package wordcounter_test
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	wcg "github.com/100gle/wordcounter"
+)
+
+func TestBundledTemplates(t *testing.T) {
+	names := wcg.BundledTemplates()
+	for _, want := range []string{"html", "markdown", "summary"} {
+		found := false
+		for _, name := range names {
+			if name == want {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf("BundledTemplates() = %v, missing %q", names, want)
+		}
+	}
+}
+
+func TestRenderTemplate(t *testing.T) {
+	fc := wcg.NewFileCounter("testdata/foo.md")
+	if err := fc.Count(); err != nil {
+		t.Fatalf("Failed to count: %v", err)
+	}
+	data := wcg.NewTemplateData("testdata", wcg.ModeFile, []*wcg.FileCounter{fc})
+
+	tmplPath := filepath.Join(t.TempDir(), "report.tmpl")
+	content := `{{ range sortByDesc "total_chars" .Files }}{{ .Path }}={{ number .TotalChars }};{{ end }}` +
+		`{{ number 1234567 }};{{ percent .Total.ChineseChars .Total.TotalChars }}`
+	if err := os.WriteFile(tmplPath, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write template: %v", err)
+	}
+
+	htmlPath := filepath.Join(t.TempDir(), "report.html.tmpl")
+	if err := os.WriteFile(htmlPath, []byte(`{{ .Metadata.Root }}`), 0644); err != nil {
+		t.Fatalf("Failed to write template: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		template string
+		data     *wcg.TemplateData
+		want     []string
+		wantErr  bool
+	}{
+		{
+			name:     "Bundled markdown template",
+			template: "markdown",
+			data:     data,
+			want:     []string{"| File |", "**Total**"},
+		},
+		{
+			name:     "Bundled summary template",
+			template: "summary",
+			data:     data,
+			want:     []string{"1 files", "Largest:"},
+		},
+		{
+			name:     "Custom template with helpers",
+			template: tmplPath,
+			data:     data,
+			want:     []string{fc.FileName + "=13;", "1,234,567;", "92.3%"},
+		},
+		{
+			name:     "HTML template escapes values",
+			template: htmlPath,
+			data:     &wcg.TemplateData{Metadata: wcg.TemplateMetadata{Root: "<b>"}},
+			want:     []string{"&lt;b&gt;"},
+		},
+		{
+			name:     "Missing template",
+			template: "testdata/not_exist.tmpl",
+			data:     data,
+			wantErr:  true,
+		},
+		{
+			name:     "Empty template name",
+			template: "",
+			data:     data,
+			wantErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := wcg.RenderTemplate(tt.template, tt.data)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("RenderTemplate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(got, want) {
+					t.Errorf("RenderTemplate() = %q, want it to contain %q", got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestDirCounter_ExportTemplate(t *testing.T) {
+	dc := wcg.NewDirCounter("testdata")
+	if err := dc.Count(); err != nil {
+		t.Fatalf("Failed to count: %v", err)
+	}
+
+	output := filepath.Join(t.TempDir(), "report.md")
+	got, err := dc.ExportTemplate("markdown", output)
+	if err != nil {
+		t.Fatalf("DirCounter.ExportTemplate() error = %v", err)
+	}
+
+	written, err := os.ReadFile(output)
+	if err != nil {
+		t.Fatalf("Failed to read exported file: %v", err)
+	}
+	if string(written) != got {
+		t.Errorf("DirCounter.ExportTemplate() wrote %q, returned %q", written, got)
+	}
+
+	tmplPath := filepath.Join(t.TempDir(), "sort.tmpl")
+	if err := os.WriteFile(tmplPath, []byte(`{{ sortBy "unknown" .Files }}`), 0644); err != nil {
+		t.Fatalf("Failed to write template: %v", err)
+	}
+	_, err = dc.ExportTemplate(tmplPath)
+	if err == nil || !strings.Contains(err.Error(), "unsupported sort field: unknown") {
+		t.Errorf("DirCounter.ExportTemplate() error = %v, want unsupported sort field", err)
+	}
+}
+
+func TestDirCounter_ExportTemplateColumns(t *testing.T) {
+	dc := wcg.NewDirCounter("testdata")
+	if err := dc.SetColumns(wcg.ColumnFile, wcg.ColumnChineseRatio, wcg.ColumnReadingTime); err != nil {
+		t.Fatalf("SetColumns() error = %v", err)
+	}
+	dc.EnableTotal()
+	if err := dc.Count(); err != nil {
+		t.Fatalf("Failed to count: %v", err)
+	}
+
+	tmplPath := filepath.Join(t.TempDir(), "columns.tmpl")
+	content := `{{ range .Columns }}{{ . }},{{ end }}|{{ range .Header }}{{ . }};{{ end }}|{{ len .Rows }}|{{ index .TotalRow 0 }}`
+	if err := os.WriteFile(tmplPath, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write template: %v", err)
+	}
+
+	got, err := dc.ExportTemplate(tmplPath)
+	if err != nil {
+		t.Fatalf("DirCounter.ExportTemplate() error = %v", err)
+	}
+	rows := dc.GetRows()
+	want := fmt.Sprintf("file,chinese_ratio,reading_time,|File;ChineseRatio;ReadingMinutes;|%d|%v", len(rows)-1, rows[len(rows)-1][0])
+	if got != want {
+		t.Errorf("DirCounter.ExportTemplate() = %q, want %q", got, want)
+	}
+}
+
+// TestDirCounter_ExportTemplateBundledColumns tests that the bundled templates report the selected columns only
+func TestDirCounter_ExportTemplateBundledColumns(t *testing.T) {
+	dc := wcg.NewDirCounter("testdata")
+	if err := dc.SetColumns(wcg.ColumnFile, wcg.ColumnChineseChars, wcg.ColumnSize); err != nil {
+		t.Fatalf("SetColumns() error = %v", err)
+	}
+	if err := dc.Count(); err != nil {
+		t.Fatalf("Failed to count: %v", err)
+	}
+
+	tests := []struct {
+		template string
+		want     []string
+		notWant  []string
+	}{
+		{"markdown", []string{"| File | ChineseChars | Size |", "| **Total** | **22** | **82** |"}, []string{"Lines", "TotalChars", "Share"}},
+		{"html", []string{"<th>File</th><th>ChineseChars</th><th>Size</th>", `<td class="num">22</td>`}, []string{"Lines", "TotalChars", "Share"}},
+		{"summary", []string{"4 files, ChineseChars: 22, Size: 82"}, []string{"Lines"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.template, func(t *testing.T) {
+			got, err := dc.ExportTemplate(tt.template)
+			if err != nil {
+				t.Fatalf("DirCounter.ExportTemplate() error = %v", err)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(got, want) {
+					t.Errorf("DirCounter.ExportTemplate() = %q, want it to contain %q", got, want)
+				}
+			}
+			for _, notWant := range tt.notWant {
+				if strings.Contains(got, notWant) {
+					t.Errorf("DirCounter.ExportTemplate() = %q, want it not to contain %q", got, notWant)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,123 @@
+package wordcounter
+
+import (
+	"crypto/tls"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// certReloader holds a TLS certificate and loads it again whenever its files change,
+// so that renewed certificates are served without restarting the server
+type certReloader struct {
+	certFile string
+	keyFile  string
+	onError  func(error) // Called when a changed certificate cannot be loaded, the previous one is kept
+
+	mu       sync.RWMutex
+	cert     *tls.Certificate
+	notifier *fsnotify.Watcher
+	done     chan struct{}
+}
+
+// newCertReloader loads the certificate and watches its files
+func newCertReloader(certFile, keyFile string, onError func(error)) (*certReloader, error) {
+	r := &certReloader{certFile: certFile, keyFile: keyFile, onError: onError, done: make(chan struct{})}
+	if err := r.reload(); err != nil {
+		return nil, err
+	}
+
+	notifier, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, NewServerError("failed to watch the TLS certificate", err)
+	}
+	// Watch the directories, as certificates are usually renewed by replacing their files
+	for _, dir := range uniqueDirs(certFile, keyFile) {
+		if err := notifier.Add(dir); err != nil {
+			notifier.Close()
+			return nil, NewServerError("failed to watch the TLS certificate", err).WithContext("path", dir)
+		}
+	}
+	r.notifier = notifier
+	go r.run()
+	return r, nil
+}
+
+// reload loads the certificate from its files
+func (r *certReloader) reload() error {
+	cert, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+	if err != nil {
+		return NewServerError("failed to load the TLS certificate", err).
+			WithContext("cert_file", r.certFile).
+			WithContext("key_file", r.keyFile)
+	}
+	r.mu.Lock()
+	r.cert = &cert
+	r.mu.Unlock()
+	return nil
+}
+
+// run reloads the certificate on the changes of its directories until Close
+func (r *certReloader) run() {
+	defer close(r.done)
+	for {
+		select {
+		case event, ok := <-r.notifier.Events:
+			if !ok {
+				return
+			}
+			if event.Op == fsnotify.Chmod || !r.concerns(event.Name) {
+				continue
+			}
+			// A half-written pair fails to load, the next event of the pair loads it
+			if err := r.reload(); err != nil && r.onError != nil {
+				r.onError(err)
+			}
+		case err, ok := <-r.notifier.Errors:
+			if !ok {
+				return
+			}
+			if r.onError != nil {
+				r.onError(err)
+			}
+		}
+	}
+}
+
+// concerns reports whether a change of the path may change the certificate: one of its files, or
+// a hidden "..data" link of the directories through which Kubernetes swaps mounted secrets
+func (r *certReloader) concerns(path string) bool {
+	path = filepath.Clean(path)
+	return path == filepath.Clean(r.certFile) || path == filepath.Clean(r.keyFile) ||
+		strings.HasPrefix(filepath.Base(path), "..")
+}
+
+// getCertificate implements tls.Config.GetCertificate
+func (r *certReloader) getCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.cert, nil
+}
+
+// Close stops watching the files
+func (r *certReloader) Close() error {
+	err := r.notifier.Close()
+	<-r.done
+	return err
+}
+
+// uniqueDirs returns the directories of the paths, without duplicates
+func uniqueDirs(paths ...string) []string {
+	var dirs []string
+	seen := map[string]bool{}
+	for _, path := range paths {
+		dir := filepath.Dir(path)
+		if !seen[dir] {
+			seen[dir] = true
+			dirs = append(dirs, dir)
+		}
+	}
+	return dirs
+}
@@ -0,0 +1,160 @@
+package wordcounter_test
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io"
+	"math/big"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	wcg "github.com/100gle/wordcounter"
+)
+
+// writeTestCert writes a self-signed certificate for 127.0.0.1 with the serial number, and returns it
+func writeTestCert(t *testing.T, certFile, keyFile string, serial int64) *x509.Certificate {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(serial),
+		Subject:      pkix.Name{CommonName: "wordcounter test"},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("Failed to create certificate: %v", err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatalf("Failed to marshal key: %v", err)
+	}
+	cert, _ := x509.ParseCertificate(der)
+
+	// Write the key first, and replace the files like renewal tools do
+	for _, file := range []struct {
+		path  string
+		block *pem.Block
+	}{
+		{keyFile, &pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}},
+		{certFile, &pem.Block{Type: "CERTIFICATE", Bytes: der}},
+	} {
+		tmp := file.path + ".tmp"
+		if err := os.WriteFile(tmp, pem.EncodeToMemory(file.block), 0600); err != nil {
+			t.Fatalf("Failed to write %s: %v", tmp, err)
+		}
+		if err := os.Rename(tmp, file.path); err != nil {
+			t.Fatalf("Failed to rename %s: %v", tmp, err)
+		}
+	}
+	return cert
+}
+
+// serverSerial returns the serial number of the certificate served at addr, without verifying it
+func serverSerial(t *testing.T, addr string) int64 {
+	t.Helper()
+	conn, err := tls.Dial("tcp", addr, &tls.Config{InsecureSkipVerify: true})
+	if err != nil {
+		t.Fatalf("TLS handshake error = %v", err)
+	}
+	defer conn.Close()
+	return conn.ConnectionState().PeerCertificates[0].SerialNumber.Int64()
+}
+
+func TestWordCounterServer_TLS(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile := filepath.Join(dir, "tls.crt"), filepath.Join(dir, "tls.key")
+	cert := writeTestCert(t, certFile, keyFile, 1)
+
+	server := wcg.NewWordCounterServer()
+	server.Config.Port = 0
+	server.Config.TLSCertFile = certFile
+	server.Config.TLSKeyFile = keyFile
+	if err := server.Start(); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go server.Serve(ctx)
+
+	if !strings.HasPrefix(server.URL(), "https://127.0.0.1:") {
+		t.Errorf("URL() = %s, want https on the loopback host", server.URL())
+	}
+	roots := x509.NewCertPool()
+	roots.AddCert(cert)
+	client := &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{RootCAs: roots}}}
+	resp, err := client.Get(server.URL() + wcg.PingEndpoint)
+	if err != nil {
+		t.Fatalf("GET over TLS error = %v", err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if string(body) != "pong" {
+		t.Errorf("GET over TLS = %q, want pong", body)
+	}
+
+	// A renewed certificate is served without restarting
+	writeTestCert(t, certFile, keyFile, 2)
+	addr := server.Addr().String()
+	deadline := time.Now().Add(5 * time.Second)
+	for serverSerial(t, addr) != 2 {
+		if time.Now().After(deadline) {
+			t.Fatal("renewed certificate was not reloaded")
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+
+	// A broken certificate keeps the previous one
+	if err := os.WriteFile(certFile, []byte("not a certificate"), 0600); err != nil {
+		t.Fatalf("Failed to write certificate: %v", err)
+	}
+	time.Sleep(100 * time.Millisecond)
+	if serial := serverSerial(t, addr); serial != 2 {
+		t.Errorf("served certificate %d after a failed reload, want the previous one", serial)
+	}
+}
+
+func TestWordCounterServer_TLSConfigErrors(t *testing.T) {
+	dir := t.TempDir()
+	tests := []struct {
+		name     string
+		certFile string
+		keyFile  string
+	}{
+		{"certificate without key", filepath.Join(dir, "tls.crt"), ""},
+		{"key without certificate", "", filepath.Join(dir, "tls.key")},
+		{"missing files", filepath.Join(dir, "tls.crt"), filepath.Join(dir, "tls.key")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := wcg.NewWordCounterServer()
+			server.Config.Port = 0
+			server.Config.TLSCertFile = tt.certFile
+			server.Config.TLSKeyFile = tt.keyFile
+			if err := server.Start(); err == nil {
+				server.Shutdown(context.Background())
+				t.Fatal("Start() should return error")
+			}
+			if server.Addr() != nil {
+				t.Errorf("Addr() = %v after a failed Start, want nil", server.Addr())
+			}
+		})
+	}
+}
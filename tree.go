@@ -0,0 +1,156 @@
+package wordcounter
+
+import (
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// DirNode is a directory in the aggregated tree of a DirCounter.
+// Stats and Files include everything below the directory, so every node is a subtotal.
+type DirNode struct {
+	Name     string     `json:"name"`
+	Path     string     `json:"path"` // Slash separated path relative to the counted directory, "." for the root
+	Files    int        `json:"files"`
+	Stats    Stats      `json:"stats"`
+	Children []*DirNode `json:"children,omitempty"`
+}
+
+// Tree aggregates the counted files by directory.
+//
+// depth limits how many directory levels below the root are expanded, like
+// du --max-depth: files in deeper directories are added to their ancestor at
+// that depth. A depth of zero or less expands all directories. Only the files
+// selected by the query are aggregated. Children are sorted by name.
+func (dc *DirCounter) Tree(depth int) *DirNode {
+	rootPath := ToAbsolutePath(dc.dirname)
+	root := &DirNode{Name: filepath.Base(rootPath), Path: "."}
+
+	for _, fc := range dc.FilteredFileCounters() {
+		relPath, err := filepath.Rel(rootPath, fc.FileName)
+		if err != nil {
+			relPath = filepath.Base(fc.FileName)
+		}
+		dirs := strings.Split(filepath.ToSlash(filepath.Dir(relPath)), "/")
+		if dirs[0] == "." {
+			dirs = nil
+		}
+		if depth > 0 && len(dirs) > depth {
+			dirs = dirs[:depth]
+		}
+
+		node := root
+		node.add(fc.Stats)
+		for i, name := range dirs {
+			node = node.child(name, strings.Join(dirs[:i+1], "/"))
+			node.add(fc.Stats)
+		}
+	}
+
+	root.sortChildren()
+	return root
+}
+
+func (n *DirNode) add(s *Stats) {
+	n.Files++
+	n.Stats.add(s)
+}
+
+func (n *DirNode) child(name, path string) *DirNode {
+	for _, c := range n.Children {
+		if c.Name == name {
+			return c
+		}
+	}
+	c := &DirNode{Name: name, Path: path}
+	n.Children = append(n.Children, c)
+	return c
+}
+
+func (n *DirNode) sortChildren() {
+	sort.Slice(n.Children, func(i, j int) bool {
+		return n.Children[i].Name < n.Children[j].Name
+	})
+	for _, c := range n.Children {
+		c.sortChildren()
+	}
+}
+
+// Walk calls fn for the node and all its descendants in depth-first order.
+// level is 0 for the node itself; last reports for each level whether the
+// node on the path is the last child of its parent, which is what tree
+// renderers need to draw connectors.
+func (n *DirNode) Walk(fn func(node *DirNode, level int, last []bool)) {
+	n.walk(fn, nil)
+}
+
+func (n *DirNode) walk(fn func(node *DirNode, level int, last []bool), last []bool) {
+	fn(n, len(last), last)
+	for i, c := range n.Children {
+		c.walk(fn, append(append([]bool{}, last...), i == len(n.Children)-1))
+	}
+}
+
+// treeHeader returns the header of the tree reports in the current language
+func treeHeader() Row {
+	return append(Row{T(MsgHeaderDirectory), T(MsgHeaderFiles)}, (&Stats{}).Header()...)
+}
+
+// treeRows returns one row per directory. With connectors the directory
+// column is drawn as a tree, otherwise it holds the relative path.
+func treeRows(root *DirNode, connectors bool) []Row {
+	var rows []Row
+	root.Walk(func(node *DirNode, level int, last []bool) {
+		name := node.Path
+		if connectors {
+			name = treePrefix(last) + node.Name
+		}
+		rows = append(rows, append(Row{name, node.Files}, node.Stats.ToRow()...))
+	})
+	return rows
+}
+
+// treePrefix draws the connectors in front of a node name
+func treePrefix(last []bool) string {
+	var b strings.Builder
+	for i, isLast := range last {
+		switch {
+		case i < len(last)-1 && isLast:
+			b.WriteString("    ")
+		case i < len(last)-1:
+			b.WriteString("│   ")
+		case isLast:
+			b.WriteString("└── ")
+		default:
+			b.WriteString("├── ")
+		}
+	}
+	return b.String()
+}
+
+// ExportTreeTable renders the directory tree with subtotals as a table.
+// See Tree for the meaning of depth.
+func (dc *DirCounter) ExportTreeTable(depth int) string {
+	data := append([]Row{treeHeader()}, treeRows(dc.Tree(depth), true)...)
+	return exportToTable(data)
+}
+
+// ExportTreeCSV exports the directory subtotals as CSV, one row per directory.
+// See Tree for the meaning of depth.
+func (dc *DirCounter) ExportTreeCSV(depth int, filename ...string) (string, error) {
+	data := append([]Row{treeHeader()}, treeRows(dc.Tree(depth), false)...)
+	return exportToCSV(data, filename...)
+}
+
+// ExportTreeExcel exports the directory subtotals to Excel, one row per directory.
+// See Tree for the meaning of depth.
+func (dc *DirCounter) ExportTreeExcel(depth int, filename ...string) error {
+	data := append([]Row{treeHeader()}, treeRows(dc.Tree(depth), false)...)
+	return exportToExcel(data, filename...)
+}
+
+// ExportTreeJSON exports the directory tree as nested DirNode objects.
+// See Tree for the meaning of depth.
+func (dc *DirCounter) ExportTreeJSON(depth int, filename ...string) (string, error) {
+	return exportToJSON(dc.Tree(depth), filename...)
+}
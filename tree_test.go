@@ -0,0 +1,154 @@
+package wordcounter_test
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	wcg "github.com/100gle/wordcounter"
+)
+
+// createBookDir creates a small book layout for tree tests
+func createBookDir(t *testing.T) string {
+	t.Helper()
+
+	dir := t.TempDir()
+	files := map[string]string{
+		"intro.md":           "前言",
+		"part1/ch01.md":      "第一章内容",
+		"part1/sec/ch02.md":  "第二章",
+		"part2/ch03.md":      "第三章 abc",
+		"part2/deep/a/b.txt": "深",
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("Failed to create directory: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write test file: %v", err)
+		}
+	}
+	return dir
+}
+
+func TestDirCounter_Tree(t *testing.T) {
+	dir := createBookDir(t)
+	dc := wcg.NewDirCounter(dir)
+	if err := dc.Count(); err != nil {
+		t.Fatalf("DirCounter.Count() error = %v", err)
+	}
+
+	tests := []struct {
+		name      string
+		depth     int
+		wantPaths []string
+		wantFiles []int
+		wantChars []int
+	}{
+		{
+			name:      "Unlimited depth",
+			depth:     0,
+			wantPaths: []string{".", "part1", "part1/sec", "part2", "part2/deep", "part2/deep/a"},
+			wantFiles: []int{5, 2, 1, 2, 1, 1},
+			wantChars: []int{18, 8, 3, 8, 1, 1},
+		},
+		{
+			name:      "Depth one",
+			depth:     1,
+			wantPaths: []string{".", "part1", "part2"},
+			wantFiles: []int{5, 2, 2},
+			wantChars: []int{18, 8, 8},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var paths []string
+			var files, chars []int
+			dc.Tree(tt.depth).Walk(func(node *wcg.DirNode, level int, last []bool) {
+				if level != len(last) {
+					t.Errorf("DirNode.Walk() level = %d, want %d", level, len(last))
+				}
+				paths = append(paths, node.Path)
+				files = append(files, node.Files)
+				chars = append(chars, node.Stats.TotalChars)
+			})
+
+			if strings.Join(paths, ",") != strings.Join(tt.wantPaths, ",") {
+				t.Errorf("Tree() paths = %v, want %v", paths, tt.wantPaths)
+			}
+			for i := range tt.wantFiles {
+				if i >= len(files) || files[i] != tt.wantFiles[i] || chars[i] != tt.wantChars[i] {
+					t.Errorf("Tree() files = %v, chars = %v, want %v, %v", files, chars, tt.wantFiles, tt.wantChars)
+					break
+				}
+			}
+		})
+	}
+}
+
+func TestDirCounter_TreeWithQuery(t *testing.T) {
+	dir := createBookDir(t)
+	dc := wcg.NewDirCounter(dir)
+	if err := dc.SetQuery(&wcg.Query{Where: []wcg.Filter{{Column: "file", Op: "~", Value: "*.md"}}}); err != nil {
+		t.Fatalf("DirCounter.SetQuery() error = %v", err)
+	}
+	if err := dc.Count(); err != nil {
+		t.Fatalf("DirCounter.Count() error = %v", err)
+	}
+
+	root := dc.Tree(0)
+	if root.Files != 4 {
+		t.Errorf("Tree() root files = %d, want only the 4 selected files", root.Files)
+	}
+}
+
+func TestDirCounter_ExportTree(t *testing.T) {
+	dir := createBookDir(t)
+	dc := wcg.NewDirCounter(dir)
+	if err := dc.Count(); err != nil {
+		t.Fatalf("DirCounter.Count() error = %v", err)
+	}
+
+	table := dc.ExportTreeTable(0)
+	for _, want := range []string{"DIRECTORY", "├── part1", "│   └── sec", "└── part2", "        └── a"} {
+		if !strings.Contains(table, want) {
+			t.Errorf("DirCounter.ExportTreeTable() = \n%s\nwant it to contain %q", table, want)
+		}
+	}
+
+	csvData, err := dc.ExportTreeCSV(1)
+	if err != nil {
+		t.Fatalf("DirCounter.ExportTreeCSV() error = %v", err)
+	}
+	wantCSV := "Directory,Files,Lines,ChineseChars,NonChineseChars,TotalChars\n" +
+		".,5,5,14,4,18\n" +
+		"part1,2,2,8,0,8\n" +
+		"part2,2,2,4,4,8"
+	if csvData != wantCSV {
+		t.Errorf("DirCounter.ExportTreeCSV() = \n%s\nwant\n%s", csvData, wantCSV)
+	}
+
+	output := filepath.Join(t.TempDir(), "tree.json")
+	jsonData, err := dc.ExportTreeJSON(1, output)
+	if err != nil {
+		t.Fatalf("DirCounter.ExportTreeJSON() error = %v", err)
+	}
+	var root wcg.DirNode
+	if err := json.Unmarshal([]byte(jsonData), &root); err != nil {
+		t.Fatalf("Failed to parse tree JSON: %v", err)
+	}
+	if root.Files != 5 || len(root.Children) != 2 || root.Children[1].Stats.NonChineseChars != 4 {
+		t.Errorf("DirCounter.ExportTreeJSON() = %s", jsonData)
+	}
+	if _, err := os.Stat(output); err != nil {
+		t.Errorf("DirCounter.ExportTreeJSON() did not write the file: %v", err)
+	}
+
+	excelPath := filepath.Join(t.TempDir(), "tree.xlsx")
+	if err := dc.ExportTreeExcel(0, excelPath); err != nil {
+		t.Errorf("DirCounter.ExportTreeExcel() error = %v", err)
+	}
+}
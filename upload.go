@@ -0,0 +1,128 @@
+package wordcounter
+
+import (
+	"mime/multipart"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+)
+
+// Media types of the upload downloads
+const (
+	MIMETextCSV = "text/csv"
+	MIMEXLSX    = "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+)
+
+// UploadedFile is the stats of an uploaded file
+type UploadedFile struct {
+	File  string `json:"file"`
+	Size  int64  `json:"size"`
+	Stats Stats  `json:"stats"`
+}
+
+// UploadResult is the stats of every uploaded file and their total
+type UploadResult struct {
+	Files   []UploadedFile `json:"files"`
+	Total   Stats          `json:"total"`
+	Options CountOptions   `json:"options"` // Effective counting options
+}
+
+// Upload counts the files of a multipart form, from any field, like FileCounter counts files.
+// Counting options are given as query parameters or form fields.
+// The result is JSON, or a CSV or XLSX download when the Accept header asks for text/csv or MIMEXLSX.
+func (s *WordCounterServer) Upload(c echo.Context) error {
+	lang := requestLanguage(c)
+	form, err := c.MultipartForm()
+	if err != nil {
+		return s.bodyError(c, err, "")
+	}
+	defer form.RemoveAll()
+
+	options, err := requestOptions(c.FormValue)
+	if err != nil {
+		return invalidOptions(c, err, "")
+	}
+
+	fields := make([]string, 0, len(form.File))
+	for field := range form.File {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+
+	result := &UploadResult{Files: []UploadedFile{}, Options: options}
+	for _, field := range fields {
+		for _, header := range form.File[field] {
+			file, err := countUploadedFile(header, options)
+			if err != nil {
+				return respondError(c, MsgRequestFailed, err)
+			}
+			result.Files = append(result.Files, *file)
+			result.Total.add(&file.Stats)
+		}
+	}
+	if len(result.Files) == 0 {
+		return respondError(c, MsgParseFailed, NewInvalidInputError(Translate(lang, MsgNoFilesUploaded)))
+	}
+	var size int64
+	for _, file := range result.Files {
+		size += file.Size
+	}
+	s.Metrics.ObserveCount(size, &result.Total)
+
+	accept := c.Request().Header.Get(echo.HeaderAccept)
+	switch {
+	case strings.Contains(accept, MIMETextCSV):
+		csvData, err := exportToCSV(result.headerAndRows(lang))
+		if err != nil {
+			return err
+		}
+		c.Response().Header().Set(echo.HeaderContentDisposition, `attachment; filename="counter.csv"`)
+		return c.Blob(http.StatusOK, MIMETextCSV+"; charset=utf-8", []byte(csvData))
+	case strings.Contains(accept, MIMEXLSX):
+		xlsxData, err := exportToExcelBytes(result.headerAndRows(lang))
+		if err != nil {
+			return err
+		}
+		c.Response().Header().Set(echo.HeaderContentDisposition, `attachment; filename="counter.xlsx"`)
+		return c.Blob(http.StatusOK, MIMEXLSX, xlsxData)
+	default:
+		return c.JSON(http.StatusOK, map[string]any{
+			"msg":   Translate(lang, MsgOK),
+			"data":  result,
+			"error": "",
+		})
+	}
+}
+
+// countUploadedFile counts an uploaded file as it reads it
+func countUploadedFile(header *multipart.FileHeader, options CountOptions) (*UploadedFile, error) {
+	file, err := header.Open()
+	if err != nil {
+		return nil, NewFileReadError(header.Filename, err)
+	}
+	defer file.Close()
+
+	counter := NewCounterWithOptions(options)
+	if err := counter.CountReader(file); err != nil {
+		return nil, NewFileReadError(header.Filename, err)
+	}
+	return &UploadedFile{File: header.Filename, Size: header.Size, Stats: *counter.Stats}, nil
+}
+
+// headerAndRows returns one row per file and the total, with headers in the language
+func (r *UploadResult) headerAndRows(lang string) []Row {
+	header := Row{Translate(lang, MsgHeaderFile), Translate(lang, MsgHeaderSize)}
+	for _, key := range statsHeaderKeys {
+		header = append(header, Translate(lang, key))
+	}
+	data := []Row{header}
+
+	for _, file := range r.Files {
+		row := Row{file.File, file.Size}
+		data = append(data, append(row, file.Stats.ToRow()...))
+	}
+	total := Row{Translate(lang, MsgTotal), ""}
+	return append(data, append(total, r.Total.ToRow()...))
+}
@@ -0,0 +1,124 @@
+package wordcounter_test
+
+import (
+	"bytes"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	wcg "github.com/100gle/wordcounter"
+	"github.com/gavv/httpexpect/v2"
+	"github.com/xuri/excelize/v2"
+)
+
+func TestWordCounterServer_Upload(t *testing.T) {
+	server := wcg.NewWordCounterServer()
+	testServer := httptest.NewServer(server.Echo)
+	defer testServer.Close()
+
+	e := httpexpect.WithConfig(httpexpect.Config{
+		BaseURL:  testServer.URL,
+		Reporter: httpexpect.NewAssertReporter(t),
+	})
+
+	upload := func() *httpexpect.Request {
+		return e.POST(wcg.UploadEndpoint).
+			WithMultipart().
+			WithFileBytes("files", "ch01.md", []byte("# 第一章\n你好，世界！")).
+			WithFileBytes("files", "notes.txt", []byte("hello")).
+			WithFileBytes("other", "empty.txt", []byte{})
+	}
+
+	t.Run("JSON", func(t *testing.T) {
+		data := upload().Expect().
+			Status(http.StatusOK).
+			JSON().Object().
+			HasValue("msg", "ok").
+			Value("data").Object()
+
+		files := data.Value("files").Array()
+		files.Length().IsEqual(3)
+		files.Value(0).Object().HasValue("file", "ch01.md").
+			Value("stats").Object().
+			HasValue("lines", 2).
+			HasValue("chinese_chars", 9)
+		files.Value(1).Object().HasValue("file", "notes.txt").HasValue("size", 5)
+		files.Value(2).Object().HasValue("file", "empty.txt")
+		data.Value("total").Object().HasValue("chinese_chars", 9).HasValue("non_chinese_chars", 7)
+	})
+
+	t.Run("CSV", func(t *testing.T) {
+		resp := upload().WithHeader("Accept", wcg.MIMETextCSV).Expect().Status(http.StatusOK)
+		resp.Header("Content-Disposition").Contains("counter.csv")
+		body := resp.Body().Raw()
+		lines := strings.Split(strings.TrimSpace(body), "\n")
+		if len(lines) != 5 || !strings.HasPrefix(lines[1], "ch01.md,") || !strings.HasPrefix(lines[4], "Total,") {
+			t.Errorf("CSV = %q, want header, 3 files and total", body)
+		}
+	})
+
+	t.Run("XLSX", func(t *testing.T) {
+		resp := upload().WithHeader("Accept", wcg.MIMEXLSX).
+			WithHeader("Accept-Language", "zh-CN").
+			Expect().Status(http.StatusOK)
+		resp.Header("Content-Type").IsEqual(wcg.MIMEXLSX)
+
+		f, err := excelize.OpenReader(bytes.NewReader([]byte(resp.Body().Raw())))
+		if err != nil {
+			t.Fatalf("Failed to open XLSX download: %v", err)
+		}
+		defer f.Close()
+		rows, err := f.GetRows("Sheet1")
+		if err != nil {
+			t.Fatalf("Failed to read rows: %v", err)
+		}
+		if len(rows) != 5 || rows[0][0] != "文件" || rows[1][0] != "ch01.md" || rows[1][3] != "9" {
+			t.Errorf("XLSX rows = %v", rows)
+		}
+	})
+
+	t.Run("no files", func(t *testing.T) {
+		e.POST(wcg.UploadEndpoint).
+			WithMultipart().
+			WithFormField("content", "你好").
+			Expect().
+			Status(http.StatusUnprocessableEntity).
+			JSON().Object().
+			HasValue("msg", "parse failed").
+			ContainsKey("error")
+	})
+
+	t.Run("not multipart", func(t *testing.T) {
+		e.POST(wcg.UploadEndpoint).
+			WithJSON(&wcg.CountBody{Content: "你好"}).
+			Expect().
+			Status(http.StatusUnprocessableEntity)
+	})
+}
+
+// TestWordCounterServer_UploadReadError tests that a file which cannot be read is a server
+// error whose cause is not sent to the client
+func TestWordCounterServer_UploadReadError(t *testing.T) {
+	server := wcg.NewWordCounterServer()
+	req := httptest.NewRequest(http.MethodPost, wcg.UploadEndpoint, nil)
+	req.Header.Set("Content-Type", "multipart/form-data; boundary=x")
+	// A parsed form whose file has neither content nor temporary file fails to open
+	req.MultipartForm = &multipart.Form{File: map[string][]*multipart.FileHeader{
+		"files": {{Filename: "secret.md", Size: 5}},
+	}}
+	rec := httptest.NewRecorder()
+	server.Echo.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusInternalServerError {
+		t.Fatalf("status = %d, want %d: %s", rec.Code, http.StatusInternalServerError, rec.Body)
+	}
+	body := rec.Body.String()
+	if !strings.Contains(body, `"error":"Internal Server Error"`) || !strings.Contains(body, `"code":"file_read_failed"`) {
+		t.Errorf("body = %s, want the status text and error code", body)
+	}
+	if strings.Contains(body, "secret.md") || strings.Contains(body, "no such file") {
+		t.Errorf("body = %s, leaks the read error", body)
+	}
+}
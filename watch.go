@@ -0,0 +1,214 @@
+package wordcounter
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// DefaultWatchInterval is the polling interval, and the delay to batch notified changes
+const DefaultWatchInterval = 500 * time.Millisecond
+
+// WatchOptions configures a Watcher
+type WatchOptions struct {
+	Poll     bool          // Poll the tree instead of using filesystem notifications
+	Interval time.Duration // Polling interval or batching delay, DefaultWatchInterval if zero
+}
+
+// WatchUpdate is sent after the initial count and after every batch of recounted files
+type WatchUpdate struct {
+	Changed []string // Changed paths, empty for the initial count
+	Total   Stats    // Total of all files
+	Delta   Stats    // Change of the total since the watch started
+	Polling bool     // The watcher fell back to polling
+}
+
+// Watcher recounts the changed files of a DirCounter
+type Watcher struct {
+	dc       *DirCounter
+	options  WatchOptions
+	baseline Stats
+}
+
+// NewWatcher creates a watcher for the directory of the DirCounter
+func NewWatcher(dc *DirCounter, options WatchOptions) *Watcher {
+	if options.Interval <= 0 {
+		options.Interval = DefaultWatchInterval
+	}
+	return &Watcher{dc: dc, options: options}
+}
+
+// Run counts the directory, then recounts changed files until the context is done.
+// It uses filesystem notifications and falls back to polling if they are unavailable.
+// onUpdate is called from the goroutine of Run.
+func (w *Watcher) Run(ctx context.Context, onUpdate func(*WatchUpdate)) error {
+	if w.dc.git != nil {
+		return NewInvalidInputError("watch is not supported for git revisions")
+	}
+	if err := w.dc.Count(); err != nil {
+		return err
+	}
+	w.baseline = w.total()
+
+	if !w.options.Poll {
+		notifier, err := fsnotify.NewWatcher()
+		if err == nil {
+			defer notifier.Close()
+			if err = w.watchTree(notifier, ToAbsolutePath(w.dc.dirname)); err == nil {
+				onUpdate(w.update(nil, false))
+				return w.runNotify(ctx, notifier, onUpdate)
+			}
+		}
+	}
+
+	onUpdate(w.update(nil, true))
+	return w.runPoll(ctx, onUpdate)
+}
+
+// total sums the stats of all files
+func (w *Watcher) total() Stats {
+	total := Stats{}
+	for _, fc := range w.dc.fileCounters {
+		total.add(fc.Stats)
+	}
+	return total
+}
+
+// update reports the current total and its change since the start
+func (w *Watcher) update(changed []string, polling bool) *WatchUpdate {
+	total := w.total()
+	return &WatchUpdate{Changed: changed, Total: total, Delta: total.sub(&w.baseline), Polling: polling}
+}
+
+// refresh recounts the changed paths and reports them
+func (w *Watcher) refresh(pending map[string]bool, polling bool, onUpdate func(*WatchUpdate)) error {
+	changed := make([]string, 0, len(pending))
+	for path := range pending {
+		changed = append(changed, path)
+	}
+	if err := w.dc.Refresh(changed...); err != nil {
+		return err
+	}
+	onUpdate(w.update(changed, polling))
+	return nil
+}
+
+// watchTree adds the directory and its subdirectories that are not ignored to the notifier
+func (w *Watcher) watchTree(notifier *fsnotify.Watcher, dir string) error {
+	return filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			// Removed while walking
+			return nil
+		}
+		if !info.IsDir() {
+			return nil
+		}
+		if path != dir && w.dc.IsIgnored(path) {
+			return filepath.SkipDir
+		}
+		return notifier.Add(path)
+	})
+}
+
+// runNotify batches notified changes until no event arrives for the interval
+func (w *Watcher) runNotify(ctx context.Context, notifier *fsnotify.Watcher, onUpdate func(*WatchUpdate)) error {
+	pending := map[string]bool{}
+	timer := time.NewTimer(w.options.Interval)
+	timer.Stop()
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case err := <-notifier.Errors:
+			return err
+		case event, ok := <-notifier.Events:
+			if !ok {
+				return nil
+			}
+			if event.Has(fsnotify.Create) {
+				if info, err := os.Stat(event.Name); err == nil && info.IsDir() && !w.dc.IsIgnored(event.Name) {
+					if err := w.watchTree(notifier, event.Name); err != nil {
+						return err
+					}
+				}
+			}
+			if event.Op == fsnotify.Chmod {
+				continue
+			}
+			pending[event.Name] = true
+			timer.Reset(w.options.Interval)
+		case <-timer.C:
+			if err := w.refresh(pending, false, onUpdate); err != nil {
+				return err
+			}
+			pending = map[string]bool{}
+		}
+	}
+}
+
+// fileState is what polling compares to detect a change
+type fileState struct {
+	size    int64
+	modTime time.Time
+}
+
+// scan walks the tree like DirCounter.Count and returns the state of every file
+func (w *Watcher) scan() map[string]fileState {
+	states := map[string]fileState{}
+	root := ToAbsolutePath(w.dc.dirname)
+	_ = filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return nil
+		}
+		if w.dc.IsIgnored(path) {
+			if info.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !info.IsDir() {
+			states[path] = fileState{size: info.Size(), modTime: info.ModTime()}
+		}
+		return nil
+	})
+	return states
+}
+
+// runPoll compares the tree every interval and recounts the files that changed
+func (w *Watcher) runPoll(ctx context.Context, onUpdate func(*WatchUpdate)) error {
+	states := w.scan()
+	ticker := time.NewTicker(w.options.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			current := w.scan()
+			pending := map[string]bool{}
+			for path, state := range current {
+				if previous, found := states[path]; !found || previous != state {
+					pending[path] = true
+				}
+			}
+			for path := range states {
+				if _, found := current[path]; !found {
+					pending[path] = true
+				}
+			}
+			states = current
+
+			if len(pending) > 0 {
+				if err := w.refresh(pending, true, onUpdate); err != nil {
+					return err
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,96 @@
+package wordcounter_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	wcg "github.com/100gle/wordcounter"
+)
+
+// runWatcher starts a watcher in the background and returns its updates
+func runWatcher(t *testing.T, dc *wcg.DirCounter, options wcg.WatchOptions) <-chan *wcg.WatchUpdate {
+	t.Helper()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	updates := make(chan *wcg.WatchUpdate, 16)
+	done := make(chan error, 1)
+	go func() {
+		done <- wcg.NewWatcher(dc, options).Run(ctx, func(update *wcg.WatchUpdate) {
+			updates <- update
+		})
+	}()
+	t.Cleanup(func() {
+		cancel()
+		if err := <-done; err != nil {
+			t.Errorf("Watcher.Run() error = %v", err)
+		}
+	})
+	return updates
+}
+
+// nextUpdate waits for an update
+func nextUpdate(t *testing.T, updates <-chan *wcg.WatchUpdate) *wcg.WatchUpdate {
+	t.Helper()
+
+	select {
+	case update := <-updates:
+		return update
+	case <-time.After(5 * time.Second):
+		t.Fatal("no watch update within 5 seconds")
+		return nil
+	}
+}
+
+func TestWatcher_Run(t *testing.T) {
+	for _, poll := range []bool{false, true} {
+		name := "notify"
+		if poll {
+			name = "poll"
+		}
+		t.Run(name, func(t *testing.T) {
+			dir := createBookDir(t)
+			dc := wcg.NewDirCounter(dir, "ignored")
+			updates := runWatcher(t, dc, wcg.WatchOptions{Poll: poll, Interval: 50 * time.Millisecond})
+
+			initial := nextUpdate(t, updates)
+			if len(initial.Changed) != 0 || initial.Total.ChineseChars != 14 || initial.Delta != (wcg.Stats{}) {
+				t.Fatalf("initial update = %+v", initial)
+			}
+			if poll && !initial.Polling {
+				t.Error("WatchUpdate.Polling = false, want true")
+			}
+
+			// Wait for the first poll so the change gets a newer modification time
+			time.Sleep(100 * time.Millisecond)
+			if err := os.MkdirAll(filepath.Join(dir, "ignored"), 0755); err != nil {
+				t.Fatalf("Failed to create directory: %v", err)
+			}
+			if err := os.WriteFile(filepath.Join(dir, "ignored", "a.md"), []byte("忽略"), 0644); err != nil {
+				t.Fatalf("Failed to write test file: %v", err)
+			}
+			if err := os.WriteFile(filepath.Join(dir, "part2", "ch04.md"), []byte("第四章"), 0644); err != nil {
+				t.Fatalf("Failed to write test file: %v", err)
+			}
+
+			update := nextUpdate(t, updates)
+			for update.Total.ChineseChars != 17 {
+				update = nextUpdate(t, updates)
+			}
+			if update.Delta.ChineseChars != 3 || len(dc.GetFileCounters()) != 6 {
+				t.Errorf("update = %+v with %d files, want the new chapter only", update, len(dc.GetFileCounters()))
+			}
+		})
+	}
+}
+
+func TestWatcher_RunGitRevision(t *testing.T) {
+	dc := wcg.NewDirCounter(t.TempDir())
+	dc.SetGitRevision("HEAD")
+	err := wcg.NewWatcher(dc, wcg.WatchOptions{}).Run(context.Background(), func(*wcg.WatchUpdate) {})
+	if err == nil {
+		t.Error("Watcher.Run() with a git revision should return error")
+	}
+}